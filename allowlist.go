@@ -0,0 +1,175 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	scriptElementRegex = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script\s*>`)
+	linkElementRegex   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	resourceAttrRegex  = regexp.MustCompile(`(?is)\s(?:src|href)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	baseElementRegex   = regexp.MustCompile(`(?is)<base\b[^>]*>`)
+	headStartRegex     = regexp.MustCompile(`(?i)<head\b[^>]*>`)
+)
+
+// SetResourceAllowlist removes <link> elements (like style sheets) and <script> elements from the HTML of the
+// page whose URL is not from one of the allowed origins, before the page is passed to wkhtmltopdf. An origin is
+// a scheme and host with an optional port, like "https://cdn.example.com"; use "data:" to allow data URLs and
+// "file://" to allow local files. Relative URLs and inline scripts without src are kept, as they come from the
+// page itself; a <base> element from another origin, which would change where they point, is removed. An empty
+// list removes all linked resources and scripts, nil turns the filter off.
+// This is finer-grained than SetSafeMode, but only filters the HTML of the page: resources loaded by CSS (like
+// @import and url()) or by allowed scripts are not checked. Pages read from a local file are filtered into a
+// temporary copy with a <base> pointing to the original directory, so relative URLs still work. URL pages can't
+// be filtered, Validate returns an error for them.
+func (po *PageOptions) SetResourceAllowlist(origins []string) {
+	if origins == nil {
+		po.allowlist = nil
+		return
+	}
+	po.allowlist = make([]string, 0, len(origins))
+	for _, origin := range origins {
+		po.allowlist = append(po.allowlist, normalizeOrigin(origin))
+	}
+}
+
+// normalizeOrigin returns the origin of a URL in lower case, like "https://example.com:8443",
+// "data:" for data URLs and "file://" for file URLs. It returns "" for relative URLs.
+func normalizeOrigin(s string) string {
+	u, err := url.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return s
+	}
+	scheme := strings.ToLower(u.Scheme)
+	switch {
+	case scheme == "file":
+		return "file://"
+	case u.Host != "":
+		if scheme == "" {
+			scheme = "https" // protocol-relative URLs like //example.com/a.js, see resourceAllowed
+		}
+		return scheme + "://" + strings.ToLower(u.Host)
+	case scheme != "":
+		return scheme + ":"
+	}
+	return ""
+}
+
+// resourceAllowed returns true if the URL of a resource is relative or from an origin in allowlist
+func resourceAllowed(allowlist []string, ref string) bool {
+	ref = strings.TrimSpace(ref)
+	origin := normalizeOrigin(ref)
+	if origin == "" {
+		return true
+	}
+	if strings.HasPrefix(ref, "//") {
+		// a protocol-relative URL takes the scheme of the page, so it is allowed for http and https
+		host := strings.TrimPrefix(origin, "https://")
+		return slices.Contains(allowlist, "https://"+host) || slices.Contains(allowlist, "http://"+host)
+	}
+	return slices.Contains(allowlist, origin)
+}
+
+// filterResources removes the <link>, <script> and <base> elements of html whose URL is not allowed by allowlist.
+// A <base> element would change where relative URLs point, so it is removed as well.
+func filterResources(html []byte, allowlist []string) []byte {
+	filter := func(element []byte) []byte {
+		// only the attributes of the start tag count, not the content of a script
+		tag := element
+		if end := bytes.IndexByte(element, '>'); end >= 0 {
+			tag = element[:end+1]
+		}
+		m := resourceAttrRegex.FindSubmatch(tag)
+		if m == nil {
+			return element
+		}
+		ref := string(m[1]) + string(m[2]) + string(m[3])
+		if resourceAllowed(allowlist, ref) {
+			return element
+		}
+		return nil
+	}
+	html = scriptElementRegex.ReplaceAllFunc(html, filter)
+	html = baseElementRegex.ReplaceAllFunc(html, filter)
+	return linkElementRegex.ReplaceAllFunc(html, filter)
+}
+
+// checkResourceAllowlist returns an error for URL pages with a resource allowlist, which can't be filtered
+func (pdfg *PDFGenerator) checkResourceAllowlist() error {
+	for i, page := range pdfg.pages {
+		if page.Options().allowlist == nil || page.Reader() != nil {
+			continue
+		}
+		if _, ok := localPath(page.InputFile()); !ok {
+			return fmt.Errorf("page %d: the resource allowlist can't be applied to URL %s", i+1, page.InputFile())
+		}
+	}
+	return nil
+}
+
+// filterFilePages writes the filtered HTML of local file pages with a resource allowlist or a Content Security
+// Policy (see SetCSP) to temporary files, which are used instead of the original files like spilled pages
+func (pdfg *PDFGenerator) filterFilePages() error {
+	for i, page := range pdfg.pages {
+		allowlist, csp := page.Options().allowlist, page.Options().csp
+		if (allowlist == nil && csp == "") || page.Reader() != nil {
+			continue
+		}
+		path, ok := localPath(page.InputFile())
+		if !ok {
+			continue
+		}
+		html, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading page %d to filter its resources: %w", i+1, err)
+		}
+		if allowlist != nil {
+			html = filterResources(html, allowlist)
+		}
+		if csp != "" {
+			html = injectAtHeadStart(html, cspMeta(csp))
+		}
+		if !baseElementRegex.Match(html) {
+			dir, err := filepath.Abs(filepath.Dir(path))
+			if err != nil {
+				return fmt.Errorf("error resolving the directory of page %d: %w", i+1, err)
+			}
+			base := (&url.URL{Scheme: "file", Path: filepath.ToSlash(dir) + "/"}).String()
+			html = injectBase(html, base)
+		}
+		tmp, err := pdfg.createTempFile("page-*"+filepath.Ext(path), html)
+		if err != nil {
+			return err
+		}
+		if pdfg.spilled == nil {
+			pdfg.spilled = make(map[int]string)
+		}
+		pdfg.spilled[i] = tmp
+	}
+	return nil
+}
+
+// injectBase inserts a <base> element at the start of the head of html, so it applies to all URLs of the document
+func injectBase(html []byte, href string) []byte {
+	return injectAtHeadStart(html, `<base href="`+href+`">`)
+}
+
+// injectAtHeadStart inserts snippet just after the opening head tag of an HTML document, before everything else
+// in the head. If the document has no head, the snippet is put in front of the document.
+func injectAtHeadStart(html []byte, snippet string) []byte {
+	loc := headStartRegex.FindIndex(html)
+	if loc == nil {
+		return append([]byte(snippet), html...)
+	}
+	out := make([]byte, 0, len(html)+len(snippet))
+	out = append(out, html[:loc[1]]...)
+	out = append(out, snippet...)
+	return append(out, html[loc[1]:]...)
+}
@@ -0,0 +1,130 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeOrigin(t *testing.T) {
+	assert.Equal(t, "https://cdn.example.com", normalizeOrigin("https://CDN.example.com/lib/"))
+	assert.Equal(t, "http://localhost:8080", normalizeOrigin("http://localhost:8080"))
+	assert.Equal(t, "data:", normalizeOrigin("data:"))
+	assert.Equal(t, "data:", normalizeOrigin("data:text/css;base64,"))
+	assert.Equal(t, "file://", normalizeOrigin("file:///tmp/a.css"))
+	assert.Equal(t, "", normalizeOrigin("css/theme.css"))
+	assert.Equal(t, "", normalizeOrigin("/theme.css"))
+}
+
+func TestFilterResources(t *testing.T) {
+	html, err := os.ReadFile("testdata/allowlist/page.html")
+	require.NoError(t, err)
+	filtered := string(filterResources(html, []string{"https://cdn.example.com", "data:"}))
+
+	assert.Contains(t, filtered, `<link rel="stylesheet" href="https://cdn.example.com/theme.css">`)
+	assert.Contains(t, filtered, `<link rel="stylesheet" href="local.css">`)
+	assert.Contains(t, filtered, `<script src="https://cdn.example.com/chart.js"></script>`)
+	assert.Contains(t, filtered, `<script>document.title = "inline";</script>`)
+	assert.NotContains(t, filtered, "evil.example.net")
+	// the allowed origin in the content of a removed script does not keep it
+	assert.NotContains(t, filtered, "var inline")
+
+	// without allowed origins only relative and inline resources are kept
+	filtered = string(filterResources(html, []string{}))
+	assert.NotContains(t, filtered, "cdn.example.com")
+	assert.Contains(t, filtered, `href="local.css"`)
+
+	// a base from another origin would make relative URLs external
+	filtered = string(filterResources([]byte(`<head><base href="https://evil.example.net/"><link href="a.css"></head>`), nil))
+	assert.Equal(t, `<head><link href="a.css"></head>`, filtered)
+}
+
+func TestResourceAllowlistReader(t *testing.T) {
+	html, err := os.ReadFile("testdata/allowlist/page.html")
+	require.NoError(t, err)
+	page := NewPageReader(strings.NewReader(string(html)))
+	page.SetResourceAllowlist([]string{"https://cdn.example.com/"})
+
+	r, err := stdinReader(page)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "https://cdn.example.com/theme.css")
+	assert.NotContains(t, string(b), "evil.example.net")
+}
+
+func TestResourceAllowlistFilePage(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	page := NewPage("testdata/allowlist/page.html")
+	page.SetResourceAllowlist([]string{"https://cdn.example.com"})
+	pdfg.AddPage(page)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.Validate())
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	args := pdfg.Args()
+	path := pdfg.spilled[0]
+	require.NotEmpty(t, path)
+	assert.Contains(t, args, path)
+	assert.Contains(t, args, "testdata/htmlsimple.html")
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	dir, err := filepath.Abs("testdata/allowlist")
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `<head><base href="file://`+filepath.ToSlash(dir)+`/">`)
+	assert.NotContains(t, string(b), "evil.example.net")
+	cleanup()
+
+	assert.Contains(t, pdfg.Args(), "testdata/allowlist/page.html")
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestResourceAllowlistURLPage(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	page := NewPage("https://example.com/report.html")
+	page.SetResourceAllowlist([]string{"https://example.com"})
+	pdfg.AddPage(page)
+	assert.EqualError(t, pdfg.Validate(), "page 1: the resource allowlist can't be applied to URL https://example.com/report.html")
+
+	page.SetResourceAllowlist(nil)
+	assert.NoError(t, pdfg.Validate())
+}
+
+func TestResourceAllowlistJSON(t *testing.T) {
+	html, err := os.ReadFile("testdata/allowlist/page.html")
+	require.NoError(t, err)
+	pdfg := NewPDFPreparer()
+	page := NewPageReader(bytes.NewReader(html))
+	page.SetResourceAllowlist([]string{"https://cdn.example.com"})
+	pdfg.AddPage(page)
+	blocked := NewPage("testdata/allowlist/page.html")
+	blocked.SetResourceAllowlist([]string{})
+	pdfg.AddPage(blocked)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	require.Len(t, restored.pages, 3)
+	assert.Equal(t, []string{"https://cdn.example.com"}, restored.pages[0].Options().allowlist)
+	assert.NotNil(t, restored.pages[1].Options().allowlist)
+	assert.Empty(t, restored.pages[1].Options().allowlist)
+	assert.Nil(t, restored.pages[2].Options().allowlist)
+
+	r, err := stdinReader(restored.pages[0])
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "https://cdn.example.com/theme.css")
+	assert.NotContains(t, string(b), "evil.example.net")
+}
@@ -0,0 +1,451 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"os"
+	"slices"
+)
+
+// AppendBeforeCover is the position for AppendPDFAt which puts a PDF before the cover and the TOC
+const AppendBeforeCover = -1
+
+// appendedPDF is a PDF file added with AppendPDFAt
+type appendedPDF struct {
+	Position int
+	Path     string
+	Label    string
+}
+
+// AppendPDFAt inserts the pages of the existing PDF file at path into the generated PDF, like a scanned appendix
+// or a form between the chapters. position is the number of pages added with AddPage which come before it: 0 puts
+// it before the first page (after the cover and TOC) and the number of pages after the last one, and
+// AppendBeforeCover puts it before the cover and TOC. PDFs at the same position keep the order in which they were
+// added.
+// If bookmarkLabel is not empty, a bookmark with this label pointing to the first page of the PDF is added to the
+// outline, between the bookmarks of the generated pages around it, and the bookmarks of the PDF are nested below
+// it; otherwise the bookmarks of the PDF are added at the top level. Links within the PDF keep working, including
+// links to named destinations, which are replaced by the destinations they name; links to names the PDF doesn't
+// define lose their destination.
+// The PDF is inserted by post-processing, so it is not counted by the page numbers in headers and footers and not
+// listed in the TOC. Unless the PDF is inserted before the cover or after the last page, each page is rendered
+// once more by itself to find where it starts in the PDF, which costs an extra wkhtmltopdf run per page.
+// Create returns an error if the position is beyond the pages added or the file can't be read as PDF.
+// An error is returned for a position below AppendBeforeCover, in which case nothing is added.
+func (pdfg *PDFGenerator) AppendPDFAt(position int, path string, bookmarkLabel string) error {
+	if position < AppendBeforeCover {
+		return fmt.Errorf("invalid position %d for appended PDF %s, use AppendBeforeCover or 0 or more", position, path)
+	}
+	pdfg.appendedPDFs = append(pdfg.appendedPDFs, appendedPDF{Position: position, Path: path, Label: bookmarkLabel})
+	return nil
+}
+
+// checkAppendedPDFs returns an error if a PDF added with AppendPDFAt is beyond the pages or does not exist
+func (pdfg *PDFGenerator) checkAppendedPDFs() error {
+	for _, ap := range pdfg.appendedPDFs {
+		if ap.Position > len(pdfg.pages) {
+			return fmt.Errorf("position %d of appended PDF %s is out of range, there are %d pages", ap.Position, ap.Path, len(pdfg.pages))
+		}
+		if _, err := os.Stat(ap.Path); err != nil {
+			return fmt.Errorf("appended PDF does not exist: %w", err)
+		}
+	}
+	return nil
+}
+
+// needsPageCounts reports whether a PDF added with AppendPDFAt is inserted between the generated pages, so the
+// PDF pages of each page must be counted
+func (pdfg *PDFGenerator) needsPageCounts() bool {
+	for _, ap := range pdfg.appendedPDFs {
+		if ap.Position != AppendBeforeCover && ap.Position < len(pdfg.pages) {
+			return true
+		}
+	}
+	return false
+}
+
+// keptPageCounts returns the number of PDF pages of each page after IncludePages, or nil if they were not counted
+func (pdfg *PDFGenerator) keptPageCounts() []int {
+	if pdfg.pageGroupPages == nil {
+		return nil
+	}
+	counts := make([]int, len(pdfg.pageGroupPages))
+	for i, n := range pdfg.pageGroupPages {
+		ranges := pdfg.pages[i].Options().includePages
+		for p := 1; p <= n; p++ {
+			if len(ranges) == 0 || includesPages(ranges, p) {
+				counts[i]++
+			}
+		}
+	}
+	return counts
+}
+
+// insertPDFs returns a post-processor which inserts the PDFs added with AppendPDFAt. counts are the number of PDF
+// pages of each page, the pages before them are the cover and the TOC; they are only needed for PDFs between the
+// pages.
+func insertPDFs(counts []int, pdfs []appendedPDF) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			body := 0
+			for _, n := range counts {
+				body += n
+			}
+			front := len(pages) - body
+			if front < 0 {
+				return fmt.Errorf("the PDF has %d pages, fewer than the %d pages counted for the appended PDFs", len(pages), body)
+			}
+			// index returns the index in pages before which a PDF at position is inserted
+			index := func(position int) int {
+				if position == AppendBeforeCover {
+					return 0
+				}
+				if position >= len(counts) {
+					return len(pages)
+				}
+				i := front
+				for _, n := range counts[:position] {
+					i += n
+				}
+				return i
+			}
+
+			sorted := slices.Clone(pdfs)
+			slices.SortStableFunc(sorted, func(a, b appendedPDF) int { return index(a.Position) - index(b.Position) })
+			var merged []pdfRef
+			var bookmarks []pdfBookmark
+			next := 0
+			for _, ap := range sorted {
+				at := index(ap.Position)
+				merged = append(merged, pages[next:at]...)
+				next = at
+				data, err := os.ReadFile(ap.Path)
+				if err != nil {
+					return fmt.Errorf("error reading appended PDF: %w", err)
+				}
+				src, err := parsePDF(data)
+				if err != nil {
+					return fmt.Errorf("error reading appended PDF %s: %w", ap.Path, err)
+				}
+				imported, outline, err := doc.importPDF(src)
+				if err != nil {
+					return fmt.Errorf("error appending PDF %s: %w", ap.Path, err)
+				}
+				if len(imported) == 0 {
+					continue
+				}
+				bookmarks = append(bookmarks, pdfBookmark{label: ap.Label, page: imported[0], children: outline, order: len(merged)})
+				merged = append(merged, imported...)
+			}
+			merged = append(merged, pages[next:]...)
+			if err := doc.setPages(merged); err != nil {
+				return err
+			}
+			return doc.addBookmarks(merged, bookmarks)
+		})
+	}
+}
+
+// pdfBookmark is an outline entry for an appended PDF
+type pdfBookmark struct {
+	label    string
+	page     pdfRef   // The first page of the PDF
+	children []pdfRef // The imported top-level outline items of the PDF
+	order    int      // The index of page in the merged document
+}
+
+// importPDF copies the pages of src and everything they use into doc, and returns the references to the copied
+// pages and the top-level items of the copied outline of src. The pages still have to be added with setPages.
+func (doc *pdfDocument) importPDF(src *pdfDocument) ([]pdfRef, []pdfRef, error) {
+	pages, err := src.pages()
+	if err != nil {
+		return nil, nil, err
+	}
+	// the page tree of src is not copied, so the inherited attributes are copied to the pages
+	for _, ref := range pages {
+		page := src.dict(ref)
+		for _, key := range []pdfName{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if page.Get(key) == nil {
+				if v := src.inheritedValue(page, key); v != nil {
+					page.Set(key, v)
+				}
+			}
+		}
+		page.Del("Parent")
+	}
+	var outline []pdfRef
+	if cat, err := src.catalog(); err == nil {
+		if root := src.dict(cat.Get("Outlines")); root != nil {
+			outline = src.outlineItems(cat, root)
+		}
+		src.resolveLinks(cat, pages)
+	}
+
+	next := 1
+	for num := range doc.objects {
+		next = max(next, num+1)
+	}
+	nums := make(map[int]int)
+	var copyObject func(obj pdfObject) pdfObject
+	copyObject = func(obj pdfObject) pdfObject {
+		switch v := obj.(type) {
+		case pdfRef:
+			if num, ok := nums[v.num]; ok {
+				return pdfRef{num: num}
+			}
+			target, ok := src.objects[v.num]
+			if !ok {
+				return pdfNull{}
+			}
+			num := next
+			next++
+			nums[v.num] = num
+			doc.objects[num] = copyObject(target)
+			return pdfRef{num: num}
+		case pdfArray:
+			copied := make(pdfArray, len(v))
+			for i, item := range v {
+				copied[i] = copyObject(item)
+			}
+			return copied
+		case *pdfDict:
+			copied := newPDFDict()
+			for _, key := range v.keys {
+				copied.Set(key, copyObject(v.values[key]))
+			}
+			return copied
+		case *pdfStream:
+			return &pdfStream{dict: copyObject(v.dict).(*pdfDict), data: v.data}
+		}
+		return obj
+	}
+	imported := make([]pdfRef, len(pages))
+	for i, ref := range pages {
+		imported[i] = copyObject(ref).(pdfRef)
+	}
+	var items []pdfRef
+	for _, ref := range outline {
+		items = append(items, copyObject(ref).(pdfRef))
+	}
+	return imported, items, nil
+}
+
+// outlineItems returns the top-level items of the outline root of the document, prepared for copying: named
+// destinations are replaced by the destinations they name, as the names of the document are not copied, and the
+// links to the root and to pages which don't exist are removed.
+func (doc *pdfDocument) outlineItems(cat, root *pdfDict) []pdfRef {
+	var items []pdfRef
+	seen := make(map[int]bool)
+	var prepare func(ref pdfRef, depth int)
+	prepare = func(ref pdfRef, depth int) {
+		item := doc.dict(ref)
+		if item == nil || depth > 32 {
+			return
+		}
+		dest := item.Get("Dest")
+		if action := doc.dict(item.Get("A")); dest == nil && action != nil && action.Get("S") == pdfName("GoTo") {
+			dest = action.Get("D")
+			item.Del("A")
+		}
+		if dest != nil {
+			if resolved := doc.namedDestination(cat, dest); resolved != nil {
+				item.Set("Dest", resolved)
+			} else {
+				item.Del("Dest")
+			}
+		}
+		for child, ok := item.Get("First").(pdfRef); ok && !seen[child.num] && doc.dict(child) != nil; child, ok = doc.dict(child).Get("Next").(pdfRef) {
+			seen[child.num] = true
+			prepare(child, depth+1)
+		}
+	}
+	for ref, ok := root.Get("First").(pdfRef); ok && !seen[ref.num] && doc.dict(ref) != nil; ref, ok = doc.dict(ref).Get("Next").(pdfRef) {
+		seen[ref.num] = true
+		prepare(ref, 0)
+		items = append(items, ref)
+	}
+	for _, ref := range items {
+		item := doc.dict(ref)
+		for _, key := range []pdfName{"Parent", "Prev", "Next"} {
+			item.Del(key)
+		}
+	}
+	return items
+}
+
+// resolveLinks replaces the named destinations of the links on pages by the destinations they name, as the names
+// of the document are not copied. Links to names which are not found lose their destination.
+func (doc *pdfDocument) resolveLinks(cat *pdfDict, pages []pdfRef) {
+	for _, page := range pages {
+		annots, _ := doc.resolve(doc.dict(page).Get("Annots")).(pdfArray)
+		for _, annot := range annots {
+			link := doc.dict(annot)
+			if link == nil || link.Get("Subtype") != pdfName("Link") {
+				continue
+			}
+			dest := link.Get("Dest")
+			action := doc.dict(link.Get("A"))
+			if dest == nil && action != nil && action.Get("S") == pdfName("GoTo") {
+				dest = action.Get("D")
+			}
+			if dest == nil {
+				continue // links to URLs and other actions
+			}
+			if resolved := doc.namedDestination(cat, dest); resolved != nil {
+				link.Set("Dest", resolved)
+			} else {
+				link.Del("Dest")
+			}
+			link.Del("A")
+		}
+	}
+}
+
+// namedDestination returns the explicit destination (an array starting with the page) for dest, which may be an
+// explicit destination or a name in /Dests of the catalog or the /Dests name tree, or nil if it is not found
+func (doc *pdfDocument) namedDestination(cat *pdfDict, dest pdfObject) pdfObject {
+	dest = doc.resolve(dest)
+	var found pdfObject
+	switch name := dest.(type) {
+	case pdfArray:
+		return name
+	case pdfName:
+		found = doc.resolve(doc.dict(cat.Get("Dests")).Get(name))
+	case pdfString:
+		found = doc.lookupName(doc.dict(doc.dict(cat.Get("Names")).Get("Dests")), string(name), 0)
+	case pdfHexString:
+		found = doc.lookupName(doc.dict(doc.dict(cat.Get("Names")).Get("Dests")), string(name), 0)
+	}
+	if d := doc.dict(found); d != nil {
+		found = doc.resolve(d.Get("D"))
+	}
+	if arr, ok := found.(pdfArray); ok {
+		return arr
+	}
+	return nil
+}
+
+// lookupName returns the value of name in a name tree, or nil if it is not found
+func (doc *pdfDocument) lookupName(node *pdfDict, name string, depth int) pdfObject {
+	if node == nil || depth > 32 {
+		return nil
+	}
+	names, _ := doc.resolve(node.Get("Names")).(pdfArray)
+	for i := 0; i+1 < len(names); i += 2 {
+		var key string
+		switch k := doc.resolve(names[i]).(type) {
+		case pdfString:
+			key = string(k)
+		case pdfHexString:
+			key = string(k)
+		}
+		if key == name {
+			return doc.resolve(names[i+1])
+		}
+	}
+	kids, _ := doc.resolve(node.Get("Kids")).(pdfArray)
+	for _, kid := range kids {
+		if found := doc.lookupName(doc.dict(kid), name, depth+1); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// addBookmarks adds the outline entries of the appended PDFs to the outline of the document, which is created if
+// the document doesn't have one. pages are the pages of the document in order. The entries are inserted before
+// the first top-level entry which points to a later page, entries without a label are replaced by their children.
+func (doc *pdfDocument) addBookmarks(pages []pdfRef, bookmarks []pdfBookmark) error {
+	if !slices.ContainsFunc(bookmarks, func(bm pdfBookmark) bool { return bm.label != "" || len(bm.children) > 0 }) {
+		return nil
+	}
+	cat, err := doc.catalog()
+	if err != nil {
+		return err
+	}
+	rootRef, ok := cat.Get("Outlines").(pdfRef)
+	root := doc.dict(rootRef)
+	if !ok || root == nil {
+		root = newPDFDict()
+		root.Set("Type", pdfName("Outlines"))
+		rootRef = doc.add(root)
+		cat.Set("Outlines", rootRef)
+	}
+	pageIndex := make(map[int]int, len(pages))
+	for i, ref := range pages {
+		pageIndex[ref.num] = i
+	}
+	// targetPage returns the index of the page an outline item points to, or -1 if it is not known
+	targetPage := func(item *pdfDict) int {
+		dest := doc.resolve(item.Get("Dest"))
+		if action := doc.dict(item.Get("A")); dest == nil && action != nil {
+			dest = doc.resolve(action.Get("D"))
+		}
+		if arr, ok := dest.(pdfArray); ok && len(arr) > 0 {
+			if ref, ok := arr[0].(pdfRef); ok {
+				if i, ok := pageIndex[ref.num]; ok {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	var items []pdfRef
+	seen := make(map[int]bool)
+	for ref, ok := root.Get("First").(pdfRef); ok && !seen[ref.num] && doc.dict(ref) != nil; ref, ok = doc.dict(ref).Get("Next").(pdfRef) {
+		seen[ref.num] = true
+		items = append(items, ref)
+	}
+	for _, bm := range bookmarks {
+		entries := bm.children
+		if bm.label != "" {
+			item := newPDFDict()
+			item.Set("Title", pdfTextString(bm.label))
+			item.Set("Dest", pdfArray{bm.page, pdfName("Fit")})
+			ref := doc.add(item)
+			linkOutlineItems(doc, ref, item, bm.children)
+			entries = []pdfRef{ref}
+		}
+		at := len(items)
+		for i, ref := range items {
+			if target := targetPage(doc.dict(ref)); target > bm.order {
+				at = i
+				break
+			}
+		}
+		items = slices.Insert(items, at, entries...)
+	}
+	linkOutlineItems(doc, rootRef, root, items)
+	return nil
+}
+
+// linkOutlineItems makes items the children of the outline item or root parent, which is open
+func linkOutlineItems(doc *pdfDocument, parentRef pdfRef, parent *pdfDict, items []pdfRef) {
+	if len(items) == 0 {
+		return
+	}
+	count := 0
+	for i, ref := range items {
+		item := doc.dict(ref)
+		item.Set("Parent", parentRef)
+		item.Del("Prev")
+		item.Del("Next")
+		if i > 0 {
+			item.Set("Prev", items[i-1])
+		}
+		if i < len(items)-1 {
+			item.Set("Next", items[i+1])
+		}
+		count++
+		if n, ok := doc.intValue(item.Get("Count")); ok && n > 0 {
+			count += n
+		}
+	}
+	parent.Set("First", items[0])
+	parent.Set("Last", items[len(items)-1])
+	parent.Set("Count", pdfInt(count))
+}
@@ -0,0 +1,220 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestOutlinePDF returns a PDF whose pages show "<name> N", with a bookmark for each title pointing to the page
+// with the same index. The first bookmark uses a named destination, like many PDF producers write.
+func newTestOutlinePDF(t *testing.T, name string, pages int, titles ...string) []byte {
+	pdf, err := modifyPDF(newTestPDF(pages), func(doc *pdfDocument) error {
+		refs, err := doc.pages()
+		if err != nil {
+			return err
+		}
+		for i, ref := range refs {
+			content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s %d) Tj ET", name, i+1)
+			doc.dict(ref).Set("Contents", doc.add(newFlateStream(newPDFDict(), []byte(content))))
+		}
+		if len(titles) == 0 {
+			return nil
+		}
+		cat, err := doc.catalog()
+		if err != nil {
+			return err
+		}
+		dests := newPDFDict()
+		root := newPDFDict()
+		rootRef := doc.add(root)
+		var items []pdfRef
+		for i, title := range titles {
+			item := newPDFDict()
+			item.Set("Title", pdfString(title))
+			item.Set("Parent", rootRef)
+			dest := pdfArray{refs[i], pdfName("XYZ"), pdfInt(0), pdfInt(842), pdfInt(0)}
+			if i == 0 {
+				dests.Set("first", dest)
+				item.Set("Dest", pdfName("first"))
+			} else {
+				item.Set("Dest", dest)
+			}
+			items = append(items, doc.add(item))
+		}
+		linkOutlineItems(doc, rootRef, root, items)
+		cat.Set("Outlines", rootRef)
+		cat.Set("Dests", dests)
+		return nil
+	})
+	require.NoError(t, err)
+	return pdf
+}
+
+// testOutline returns the bookmarks of the PDF as "title -> page index", indented by level
+func testOutline(t *testing.T, pdf []byte) []string {
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	var lines []string
+	var walk func(parent *pdfDict, indent string)
+	walk = func(parent *pdfDict, indent string) {
+		for ref, ok := parent.Get("First").(pdfRef); ok; ref, ok = doc.dict(ref).Get("Next").(pdfRef) {
+			item := doc.dict(ref)
+			dest, _ := doc.resolve(item.Get("Dest")).(pdfArray)
+			page := -1
+			for i, p := range pages {
+				if len(dest) > 0 && dest[0] == p {
+					page = i
+				}
+			}
+			lines = append(lines, fmt.Sprintf("%s%s -> %d", indent, doc.resolve(item.Get("Title")), page))
+			walk(item, indent+"  ")
+		}
+	}
+	if root := doc.dict(cat.Get("Outlines")); root != nil {
+		walk(root, "")
+	}
+	return lines
+}
+
+func TestAppendPDFAt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	dir := t.TempDir()
+	write := func(name string, pdf []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pdf, 0644))
+		return path
+	}
+	front := write("front.pdf", newTestOutlinePDF(t, "Front", 1))
+	appendix := write("appendix.pdf", newTestOutlinePDF(t, "Appendix", 2, "Appendix A", "Appendix B"))
+	end := write("end.pdf", newTestOutlinePDF(t, "End", 1, "Closing"))
+
+	// the generated PDF has a cover, 5 pages of multipage.html and 1 page of htmlsimple.html, with a bookmark for
+	// each page; the pages are counted by the fake binary
+	bin := newPageCountBinary(t)
+	generated := newTestOutlinePDF(t, "Page", 7, "Cover", "Multipage")
+	generated, err := modifyPDF(generated, func(doc *pdfDocument) error {
+		cat, _ := doc.catalog()
+		cat.Del("Dests")
+		pages, _ := doc.pages()
+		root := doc.dict(cat.Get("Outlines"))
+		var items []pdfRef
+		for ref, ok := root.Get("First").(pdfRef); ok; ref, ok = doc.dict(ref).Get("Next").(pdfRef) {
+			items = append(items, ref)
+		}
+		doc.dict(items[0]).Set("Dest", pdfArray{pages[0], pdfName("Fit")})
+		simple := newPDFDict()
+		simple.Set("Title", pdfString("Simple"))
+		simple.Set("Dest", pdfArray{pages[6], pdfName("Fit")})
+		linkOutlineItems(doc, cat.Get("Outlines").(pdfRef), root, append(items, doc.add(simple)))
+		return nil
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(bin), "7.pdf"), generated, 0644))
+
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.Cover.Input = "testdata/htmlsimple.html"
+	pdfg.AddPage(NewPage("testdata/multipage.html"))
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.AppendPDFAt(2, end, ""))
+	require.NoError(t, pdfg.AppendPDFAt(1, appendix, "Appendix"))
+	require.NoError(t, pdfg.AppendPDFAt(AppendBeforeCover, front, "Front matter"))
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+
+	var texts []string
+	for _, text := range testPDFPageTexts(t, pdfg.Bytes()) {
+		texts = append(texts, strings.TrimSuffix(strings.TrimPrefix(text, "BT /F1 12 Tf 72 720 Td ("), ") Tj ET"))
+	}
+	assert.Equal(t, []string{"Front 1", "Page 1", "Page 2", "Page 3", "Page 4", "Page 5", "Page 6", "Appendix 1",
+		"Appendix 2", "Page 7", "End 1"}, texts)
+	assert.Equal(t, []string{
+		"Front matter -> 0",
+		"Cover -> 1",
+		"Multipage -> 2",
+		"Appendix -> 7",
+		"  Appendix A -> 7",
+		"  Appendix B -> 8",
+		"Simple -> 9",
+		"Closing -> 10",
+	}, testOutline(t, pdfg.Bytes()))
+}
+
+func TestAppendPDFAtPosition(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.AppendPDFAt(-2, "testdata/a.pdf", ""),
+		"invalid position -2 for appended PDF testdata/a.pdf, use AppendBeforeCover or 0 or more")
+	assert.Empty(t, pdfg.appendedPDFs)
+
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.AppendPDFAt(2, "testdata/htmlsimple.html", ""))
+	assert.EqualError(t, pdfg.Validate(), "position 2 of appended PDF testdata/htmlsimple.html is out of range, there are 1 pages")
+
+	pdfg.appendedPDFs = nil
+	require.NoError(t, pdfg.AppendPDFAt(1, "testdata/missing.pdf", ""))
+	assert.ErrorContains(t, pdfg.Validate(), "appended PDF does not exist")
+}
+
+func TestInsertPDFsLinks(t *testing.T) {
+	appendix, err := modifyPDF(newTestOutlinePDF(t, "Appendix", 2, "Appendix A"), func(doc *pdfDocument) error {
+		pages, err := doc.pages()
+		if err != nil {
+			return err
+		}
+		link := func(key pdfName, value pdfObject) pdfRef {
+			annot := newPDFDict()
+			annot.Set("Type", pdfName("Annot"))
+			annot.Set("Subtype", pdfName("Link"))
+			annot.Set(key, value)
+			return doc.add(annot)
+		}
+		goTo := newPDFDict()
+		goTo.Set("S", pdfName("GoTo"))
+		goTo.Set("D", pdfName("first"))
+		uri := newPDFDict()
+		uri.Set("S", pdfName("URI"))
+		uri.Set("URI", pdfString("https://example.com"))
+		doc.dict(pages[1]).Set("Annots", pdfArray{
+			link("Dest", pdfName("first")),
+			link("A", goTo),
+			link("Dest", pdfName("missing")),
+			link("A", uri),
+		})
+		return nil
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "appendix.pdf")
+	require.NoError(t, os.WriteFile(path, appendix, 0644))
+
+	pdf, err := insertPDFs([]int{1}, []appendedPDF{{Position: 1, Path: path}})(newTestPDF(1))
+	require.NoError(t, err)
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	require.Len(t, pages, 3)
+	annots, _ := doc.resolve(doc.dict(pages[2]).Get("Annots")).(pdfArray)
+	require.Len(t, annots, 4)
+	for _, annot := range annots[:2] {
+		link := doc.dict(annot)
+		dest, _ := doc.resolve(link.Get("Dest")).(pdfArray)
+		require.NotEmpty(t, dest)
+		assert.Equal(t, pages[1], dest[0])
+		assert.Nil(t, link.Get("A"))
+	}
+	assert.Nil(t, doc.dict(annots[2]).Get("Dest"))
+	assert.Equal(t, pdfName("URI"), doc.dict(doc.dict(annots[3]).Get("A")).Get("S"))
+}
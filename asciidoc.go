@@ -0,0 +1,128 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// AsciiDocConverter converts AsciiDoc to HTML for an AsciiDocPage
+type AsciiDocConverter interface {
+	Convert(adoc []byte) ([]byte, error)
+}
+
+// AsciiDocConverterFunc is a function which implements AsciiDocConverter
+type AsciiDocConverterFunc func(adoc []byte) ([]byte, error)
+
+// Convert calls f
+func (f AsciiDocConverterFunc) Convert(adoc []byte) ([]byte, error) {
+	return f(adoc)
+}
+
+// AsciidoctorConverter converts AsciiDoc to a standalone HTML document with the asciidoctor command,
+// it is the default converter of AsciiDocPage.
+type AsciidoctorConverter struct {
+	// Path is the path of the asciidoctor executable, if empty asciidoctor is looked up in PATH
+	Path string
+	// Args are extra arguments for asciidoctor, like "-a" "toc"
+	Args []string
+}
+
+// Convert runs asciidoctor with adoc as input and returns the HTML it writes to stdout
+func (c AsciidoctorConverter) Convert(adoc []byte) ([]byte, error) {
+	path := c.Path
+	if path == "" {
+		path = "asciidoctor"
+	}
+	path, err := exec.LookPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("asciidoctor not found, install it or set AsciiDocPage.Converter: %w", err)
+	}
+
+	args := append(append([]string{}, c.Args...), "--out-file", "-", "-")
+	cmd := exec.Command(path, args...)
+	cmdConfig(cmd)
+	var out, errBuf bytes.Buffer
+	cmd.Stdin = bytes.NewReader(adoc)
+	cmd.Stdout = &out
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		if errStr := strings.TrimSpace(errBuf.String()); errStr != "" {
+			return nil, fmt.Errorf("asciidoctor failed: %w\n%s", err, errStr)
+		}
+		return nil, fmt.Errorf("asciidoctor failed: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// AsciiDocPage represents a page created from an AsciiDoc file.
+// The AsciiDoc content is converted to HTML with Converter and passed to wkhtmltopdf via stdin, like MarkdownPage.
+// It implements the PageProvider interface.
+type AsciiDocPage struct {
+	// InputPath is the filesystem path to the AsciiDoc file.
+	InputPath string
+	// Converter converts the AsciiDoc to HTML, if nil AsciidoctorConverter{} is used.
+	// The converter is not stored by ToJSON, a page restored from JSON uses the default converter.
+	Converter AsciiDocConverter
+	PageOptions
+	htmlCache []byte // Cache for the converted HTML
+	readErr   error  // Store error during file read/conversion
+}
+
+// NewAsciiDocPage creates a new AsciiDocPage provider from an AsciiDoc file path, using asciidoctor to convert it.
+func NewAsciiDocPage(inputPath string) *AsciiDocPage {
+	return &AsciiDocPage{
+		InputPath:   inputPath,
+		PageOptions: NewPageOptions(),
+	}
+}
+
+// Options returns the PageOptions associated with this AsciiDocPage.
+func (ap *AsciiDocPage) Options() *PageOptions {
+	return &ap.PageOptions
+}
+
+// Args returns the argument slice and is part of the page interface
+func (ap *AsciiDocPage) Args() []string {
+	return ap.PageOptions.Args()
+}
+
+// InputFile returns "-" as AsciiDoc is converted and piped via stdin.
+func (ap *AsciiDocPage) InputFile() string {
+	return "-"
+}
+
+// Reader reads the AsciiDoc file, converts it to HTML, and returns it as an io.Reader.
+// It caches the result to avoid re-reading and re-converting.
+func (ap *AsciiDocPage) Reader() io.Reader {
+	if ap.readErr != nil {
+		return &errorReader{err: ap.readErr}
+	}
+	if ap.htmlCache != nil {
+		return bytes.NewReader(ap.htmlCache)
+	}
+
+	adoc, err := os.ReadFile(ap.InputPath)
+	if err != nil {
+		ap.readErr = fmt.Errorf("failed to read asciidoc file %s: %w", ap.InputPath, err)
+		return &errorReader{err: ap.readErr}
+	}
+	converter := ap.Converter
+	if converter == nil {
+		converter = AsciidoctorConverter{}
+	}
+	html, err := converter.Convert(adoc)
+	if err == nil && html == nil {
+		err = errors.New("converter returned no HTML")
+	}
+	if err != nil {
+		ap.readErr = fmt.Errorf("failed to convert asciidoc file %s: %w", ap.InputPath, err)
+		return &errorReader{err: ap.readErr}
+	}
+	ap.htmlCache = html
+	return bytes.NewReader(ap.htmlCache)
+}
@@ -0,0 +1,78 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testAsciiDocConverter wraps the AsciiDoc source in a pre element, instead of requiring asciidoctor
+var testAsciiDocConverter = AsciiDocConverterFunc(func(adoc []byte) ([]byte, error) {
+	return append(append([]byte("<html><body><pre>"), adoc...), "</pre></body></html>"...), nil
+})
+
+func TestAsciiDocPage(t *testing.T) {
+	ap := NewAsciiDocPage("testdata/asciidoc.adoc")
+	ap.Converter = testAsciiDocConverter
+	assert.Equal(t, "-", ap.InputFile())
+
+	html, err := io.ReadAll(ap.Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(html), "<pre>= AsciiDoc Document")
+
+	// the converted HTML is cached
+	ap.Converter = nil
+	cached, err := io.ReadAll(ap.Reader())
+	require.NoError(t, err)
+	assert.Equal(t, html, cached)
+}
+
+func TestAsciiDocPageErrors(t *testing.T) {
+	ap := NewAsciiDocPage("testdata/missing.adoc")
+	_, err := io.ReadAll(ap.Reader())
+	assert.ErrorContains(t, err, "failed to read asciidoc file testdata/missing.adoc")
+
+	ap = NewAsciiDocPage("testdata/asciidoc.adoc")
+	ap.Converter = AsciidoctorConverter{Path: "testdata/no-asciidoctor"}
+	_, err = io.ReadAll(ap.Reader())
+	assert.ErrorContains(t, err, "asciidoctor not found")
+
+	ap = NewAsciiDocPage("testdata/asciidoc.adoc")
+	ap.Converter = AsciiDocConverterFunc(func([]byte) ([]byte, error) { return nil, errors.New("boom") })
+	_, err = io.ReadAll(ap.Reader())
+	assert.ErrorContains(t, err, "boom")
+}
+
+func TestAsciiDocPageJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	ap := NewAsciiDocPage("testdata/asciidoc.adoc")
+	ap.Converter = testAsciiDocConverter
+	ap.Zoom.Set(1.5)
+	pdfg.AddPage(ap)
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	assert.Contains(t, string(j), `"Type":"asciidoc"`)
+
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	require.Len(t, restored.pages, 1)
+	rp, ok := restored.pages[0].(*AsciiDocPage)
+	require.True(t, ok)
+	assert.Equal(t, "testdata/asciidoc.adoc", rp.InputPath)
+	assert.Equal(t, 1.5, rp.Zoom.value)
+}
+
+func TestCreateAsciiDocPage(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	ap := NewAsciiDocPage("testdata/asciidoc.adoc")
+	ap.Converter = testAsciiDocConverter
+	pdfg.AddPage(ap)
+	require.NoError(t, pdfg.Create())
+	assert.NotEmpty(t, pdfg.Bytes())
+}
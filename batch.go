@@ -0,0 +1,270 @@
+package wkhtmltopdf
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// BatchJob is a PDF rendered by GenerateBatch
+type BatchJob struct {
+	// ID identifies the job in the CheckpointStore and in errors, it must be unique within the batch
+	ID string
+	// OutputFile is where the PDF is written, it is set as OutputFile of the generator before Configure is called
+	OutputFile string
+	// Configure sets up the generator of the job, like adding the pages and setting options. It is called with a
+	// new generator from NewPDFGenerator.
+	Configure func(pdfg *PDFGenerator) error
+}
+
+// CheckpointStore records which jobs of a batch are completed, so GenerateBatch can skip them when an interrupted
+// batch is run again. It can be backed by a file (see FileCheckpointStore) or a database. With BatchConcurrency,
+// the methods are called from several goroutines at once.
+type CheckpointStore interface {
+	// Done returns true if the job was marked as done
+	Done(jobID string) (bool, error)
+	// MarkDone records that the job is completed, it is called after the PDF was written
+	MarkDone(jobID string) error
+}
+
+// BatchOption is an option for GenerateBatch
+type BatchOption func(*batchConfig)
+
+type batchConfig struct {
+	checkpoints     CheckpointStore
+	continueOnError bool
+	concurrency     int
+}
+
+// BatchCheckpoint makes GenerateBatch skip the jobs store reports as done and mark jobs as done when their PDF is
+// written, so a batch which was interrupted or canceled continues where it stopped when it is run again.
+func BatchCheckpoint(store CheckpointStore) BatchOption {
+	return func(c *batchConfig) { c.checkpoints = store }
+}
+
+// BatchContinueOnError makes GenerateBatch render the remaining jobs after a job failed, instead of stopping.
+// The errors of all failed jobs are returned together.
+func BatchContinueOnError() BatchOption {
+	return func(c *batchConfig) { c.continueOnError = true }
+}
+
+// BatchConcurrency makes GenerateBatch render up to n jobs at the same time, the default is 1.
+// SetMaxConcurrentRenders limits the wkhtmltopdf processes of all batches and other renders together.
+func BatchConcurrency(n int) BatchOption {
+	return func(c *batchConfig) { c.concurrency = n }
+}
+
+// BatchReport lists the IDs of the jobs of a batch by outcome, in the order of the jobs
+type BatchReport struct {
+	Rendered []string // Jobs rendered by this run
+	Skipped  []string // Jobs skipped because the CheckpointStore reports them as done
+	Failed   []string // Jobs which failed
+}
+
+// GenerateBatch renders the jobs in order, each with its own generator, and stops at the first job which fails.
+// With BatchCheckpoint, completed jobs are skipped and newly rendered jobs are marked as done, so the batch can be
+// resumed. When ctx is canceled, no more jobs are started, running jobs are stopped and ctx.Err() is returned;
+// jobs which were completed before stay marked as done. The report lists what happened to each job, also when an
+// error is returned. An error is returned before rendering anything if a job has no ID or an ID is used twice.
+func GenerateBatch(ctx context.Context, jobs []BatchJob, opts ...BatchOption) (BatchReport, error) {
+	cfg := batchConfig{concurrency: 1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.concurrency < 1 {
+		cfg.concurrency = 1
+	}
+	if err := validateBatchJobs(jobs); err != nil {
+		return BatchReport{}, err
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome int
+	const (
+		notRun outcome = iota
+		rendered
+		skipped
+		failed
+	)
+	outcomes := make([]outcome, len(jobs))
+	errs := make([]error, len(jobs))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				if ctx.Err() != nil {
+					continue // canceled while waiting for the job
+				}
+				isSkipped, err := runBatchJob(ctx, jobs[i], cfg.checkpoints)
+				switch {
+				case err != nil && ctx.Err() != nil:
+					// canceled, the job is not counted as failed
+				case err != nil:
+					outcomes[i], errs[i] = failed, fmt.Errorf("batch job %s: %w", jobs[i].ID, err)
+					if !cfg.continueOnError {
+						cancel()
+					}
+				case isSkipped:
+					outcomes[i] = skipped
+				default:
+					outcomes[i] = rendered
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+		}
+	}
+	close(next)
+	wg.Wait()
+
+	var report BatchReport
+	for i, o := range outcomes {
+		switch o {
+		case rendered:
+			report.Rendered = append(report.Rendered, jobs[i].ID)
+		case skipped:
+			report.Skipped = append(report.Skipped, jobs[i].ID)
+		case failed:
+			report.Failed = append(report.Failed, jobs[i].ID)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return report, err
+	}
+	return report, parent.Err()
+}
+
+// validateBatchJobs returns an error if a job has no ID or an ID is used more than once
+func validateBatchJobs(jobs []BatchJob) error {
+	ids := make(map[string]bool, len(jobs))
+	for i, job := range jobs {
+		if job.ID == "" {
+			return fmt.Errorf("batch job %d has no ID", i+1)
+		}
+		if ids[job.ID] {
+			return fmt.Errorf("batch job ID %q is used more than once", job.ID)
+		}
+		ids[job.ID] = true
+	}
+	return nil
+}
+
+// newBatchGenerator returns a new generator set up by job
+func newBatchGenerator(job BatchJob) (*PDFGenerator, error) {
+	pdfg, err := NewPDFGenerator()
+	if err != nil {
+		return nil, err
+	}
+	pdfg.OutputFile = job.OutputFile
+	if job.Configure != nil {
+		if err := job.Configure(pdfg); err != nil {
+			return nil, err
+		}
+	}
+	return pdfg, nil
+}
+
+// runBatchJob renders job unless it is done according to checkpoints, and marks it as done
+func runBatchJob(ctx context.Context, job BatchJob, checkpoints CheckpointStore) (skipped bool, err error) {
+	if checkpoints != nil {
+		done, err := checkpoints.Done(job.ID)
+		if err != nil {
+			return false, fmt.Errorf("error reading checkpoint: %w", err)
+		}
+		if done {
+			return true, nil
+		}
+	}
+	pdfg, err := newBatchGenerator(job)
+	if err != nil {
+		return false, err
+	}
+	if err := pdfg.CreateContext(ctx); err != nil {
+		return false, err
+	}
+	if checkpoints != nil {
+		if err := checkpoints.MarkDone(job.ID); err != nil {
+			return false, fmt.Errorf("error writing checkpoint: %w", err)
+		}
+	}
+	return false, nil
+}
+
+// FileCheckpointStore is a CheckpointStore which keeps the IDs of the completed jobs in a text file, one per line.
+// Each ID is appended to the file as soon as the job is done, so no progress is lost when the program stops.
+type FileCheckpointStore struct {
+	path string
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+// NewFileCheckpointStore returns a FileCheckpointStore for the file at path, reading the IDs already in it.
+// The file is created when the first job is marked as done. Delete it to render all jobs again.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	s := &FileCheckpointStore{path: path, done: make(map[string]bool)}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading checkpoint file: %w", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if id := scanner.Text(); id != "" {
+			s.done[id] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading checkpoint file: %w", err)
+	}
+	return s, nil
+}
+
+// Done returns true if jobID is in the file
+func (s *FileCheckpointStore) Done(jobID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done[jobID], nil
+}
+
+// MarkDone appends jobID to the file. IDs with line breaks can't be stored and return an error.
+func (s *FileCheckpointStore) MarkDone(jobID string) error {
+	if strings.ContainsAny(jobID, "\r\n") {
+		return fmt.Errorf("job ID %q can't be stored in a checkpoint file, it contains a line break", jobID)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+	_, err = f.WriteString(jobID + "\n")
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("error writing checkpoint file: %w", err)
+	}
+	s.done[jobID] = true
+	return nil
+}
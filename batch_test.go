@@ -0,0 +1,148 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCheckpointStore is a CheckpointStore in memory, which survives a restart of the batch in tests
+type memoryCheckpointStore struct {
+	mu   sync.Mutex
+	done map[string]bool
+}
+
+func (s *memoryCheckpointStore) Done(jobID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done[jobID], nil
+}
+
+func (s *memoryCheckpointStore) MarkDone(jobID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done == nil {
+		s.done = make(map[string]bool)
+	}
+	s.done[jobID] = true
+	return nil
+}
+
+// batchJobs returns jobs which render testdata/htmlsimple.html to files in dir and count how often they run
+func batchJobs(dir string, ids []string, runs map[string]int, configure func(id string, pdfg *PDFGenerator) error) []BatchJob {
+	var mu sync.Mutex
+	var jobs []BatchJob
+	for _, id := range ids {
+		jobs = append(jobs, BatchJob{
+			ID:         id,
+			OutputFile: filepath.Join(dir, id+".pdf"),
+			Configure: func(pdfg *PDFGenerator) error {
+				mu.Lock()
+				runs[id]++
+				mu.Unlock()
+				pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+				if configure != nil {
+					return configure(id, pdfg)
+				}
+				return nil
+			},
+		})
+	}
+	return jobs
+}
+
+func TestGenerateBatchResume(t *testing.T) {
+	dir := t.TempDir()
+	ids := []string{"a", "b", "c", "d", "e"}
+	store := &memoryCheckpointStore{}
+	runs := make(map[string]int)
+
+	// the first run is interrupted while job c is set up
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs := batchJobs(dir, ids, runs, func(id string, pdfg *PDFGenerator) error {
+		if id == "c" {
+			cancel()
+		}
+		return nil
+	})
+	report, err := GenerateBatch(ctx, jobs, BatchCheckpoint(store))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, BatchReport{Rendered: []string{"a", "b"}}, report)
+	assert.Equal(t, map[string]bool{"a": true, "b": true}, store.done)
+	_, err = os.Stat(filepath.Join(dir, "d.pdf"))
+	assert.True(t, os.IsNotExist(err))
+
+	// after the restart only the remaining jobs are rendered
+	report, err = GenerateBatch(context.Background(), batchJobs(dir, ids, runs, nil), BatchCheckpoint(store))
+	require.NoError(t, err)
+	assert.Equal(t, BatchReport{Rendered: []string{"c", "d", "e"}, Skipped: []string{"a", "b"}}, report)
+	assert.Equal(t, map[string]int{"a": 1, "b": 1, "c": 2, "d": 1, "e": 1}, runs)
+	for _, id := range ids {
+		assert.FileExists(t, filepath.Join(dir, id+".pdf"))
+	}
+
+	// a third run has nothing to do
+	report, err = GenerateBatch(context.Background(), batchJobs(dir, ids, runs, nil), BatchCheckpoint(store), BatchConcurrency(3))
+	require.NoError(t, err)
+	assert.Equal(t, ids, report.Skipped)
+	assert.Empty(t, report.Rendered)
+}
+
+func TestGenerateBatchErrors(t *testing.T) {
+	dir := t.TempDir()
+	ids := []string{"a", "b", "c", "d"}
+	failB := func(id string, pdfg *PDFGenerator) error {
+		if id == "b" {
+			pdfg.AddPage(NewPage("testdata/does-not-exist.html"))
+		}
+		return nil
+	}
+
+	// by default the batch stops at the first error, and the failed job is not marked as done
+	store := &memoryCheckpointStore{}
+	report, err := GenerateBatch(context.Background(), batchJobs(dir, ids, make(map[string]int), failB), BatchCheckpoint(store))
+	assert.ErrorContains(t, err, "batch job b: referenced local files do not exist")
+	assert.Equal(t, BatchReport{Rendered: []string{"a"}, Failed: []string{"b"}}, report)
+	assert.Equal(t, map[string]bool{"a": true}, store.done)
+
+	report, err = GenerateBatch(context.Background(), batchJobs(dir, ids, make(map[string]int), failB), BatchCheckpoint(store), BatchContinueOnError())
+	assert.ErrorContains(t, err, "batch job b: ")
+	assert.Equal(t, BatchReport{Rendered: []string{"c", "d"}, Skipped: []string{"a"}, Failed: []string{"b"}}, report)
+
+	_, err = GenerateBatch(context.Background(), []BatchJob{{ID: "a"}, {}})
+	assert.EqualError(t, err, "batch job 2 has no ID")
+	_, err = GenerateBatch(context.Background(), []BatchJob{{ID: "a"}, {ID: "a"}})
+	assert.EqualError(t, err, `batch job ID "a" is used more than once`)
+}
+
+func TestFileCheckpointStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoints.txt")
+	store, err := NewFileCheckpointStore(path)
+	require.NoError(t, err)
+	done, err := store.Done("invoice-1")
+	require.NoError(t, err)
+	assert.False(t, done)
+
+	require.NoError(t, store.MarkDone("invoice-1"))
+	require.NoError(t, store.MarkDone("invoice-2"))
+	assert.EqualError(t, store.MarkDone("bad\nid"), `job ID "bad\nid" can't be stored in a checkpoint file, it contains a line break`)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "invoice-1\ninvoice-2\n", string(b))
+
+	// a new store reads the completed jobs from the file
+	store, err = NewFileCheckpointStore(path)
+	require.NoError(t, err)
+	done, err = store.Done("invoice-2")
+	require.NoError(t, err)
+	assert.True(t, done)
+	done, err = store.Done("invoice-3")
+	require.NoError(t, err)
+	assert.False(t, done)
+}
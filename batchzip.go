@@ -0,0 +1,124 @@
+package wkhtmltopdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+)
+
+// BatchZipErrorsFile is the name of the entry in which BatchToZip lists the jobs which failed
+const BatchZipErrorsFile = "errors.txt"
+
+// batchZipResult is a rendered job of BatchToZip
+type batchZipResult struct {
+	pdf []byte
+	err error
+}
+
+// BatchToZip renders the jobs with up to concurrency jobs at the same time and writes the PDFs to a zip archive
+// on w, in the order of the jobs. Each PDF is named after the ID of its job, with .pdf added unless the ID ends
+// with it; IDs can contain slashes for folders. The OutputFile of the jobs is not used. The PDFs are written as
+// soon as they and the PDFs before them are rendered, so at most concurrency PDFs are kept in memory.
+// A job which fails doesn't stop the others: it gets no PDF, and its error is listed in an entry named
+// BatchZipErrorsFile at the end of the archive. The archive is complete also when jobs failed, then the errors
+// of the failed jobs are returned together. An error is returned before rendering anything if a job has no ID,
+// an ID is used twice, two IDs get the same name (like "a" and "a.pdf") or an ID is not a valid relative path,
+// like "../report".
+func BatchToZip(w io.Writer, jobs []BatchJob, concurrency int) error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if err := validateBatchJobs(jobs); err != nil {
+		return err
+	}
+	names := make([]string, len(jobs))
+	used := make(map[string]string, len(jobs))
+	for i, job := range jobs {
+		names[i] = job.ID
+		if !strings.HasSuffix(strings.ToLower(names[i]), ".pdf") {
+			names[i] += ".pdf"
+		}
+		if !fs.ValidPath(names[i]) {
+			return fmt.Errorf("batch job ID %q can't be used as name in a zip archive", job.ID)
+		}
+		if other, ok := used[names[i]]; ok {
+			return fmt.Errorf("batch job IDs %q and %q have the same name %s in the zip archive", other, job.ID, names[i])
+		}
+		used[names[i]] = job.ID
+	}
+
+	// a job is started when a slot is free, and its slot is freed when its PDF is written to the archive
+	results := make([]chan batchZipResult, len(jobs))
+	for i := range results {
+		results[i] = make(chan batchZipResult, 1)
+	}
+	slots := make(chan struct{}, concurrency)
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		for i, job := range jobs {
+			select {
+			case slots <- struct{}{}:
+			case <-stop:
+				return
+			}
+			go func() {
+				pdf, err := renderBatchJob(job)
+				results[i] <- batchZipResult{pdf: pdf, err: err}
+			}()
+		}
+	}()
+
+	zw := zip.NewWriter(w)
+	var errs []error
+	var failed strings.Builder
+	for i, job := range jobs {
+		result := <-results[i]
+		if result.err != nil {
+			errs = append(errs, fmt.Errorf("batch job %s: %w", job.ID, result.err))
+			fmt.Fprintf(&failed, "%s: %v\n", job.ID, result.err)
+			<-slots
+			continue
+		}
+		f, err := zw.Create(names[i])
+		if err == nil {
+			_, err = f.Write(result.pdf)
+		}
+		if err != nil {
+			return fmt.Errorf("error writing zip archive: %w", err)
+		}
+		<-slots
+	}
+	if failed.Len() > 0 {
+		f, err := zw.Create(BatchZipErrorsFile)
+		if err == nil {
+			_, err = io.WriteString(f, failed.String())
+		}
+		if err != nil {
+			return fmt.Errorf("error writing zip archive: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("error writing zip archive: %w", err)
+	}
+	return errors.Join(errs...)
+}
+
+// renderBatchJob renders job to memory and returns the PDF
+func renderBatchJob(job BatchJob) ([]byte, error) {
+	pdfg, err := newBatchGenerator(job)
+	if err != nil {
+		return nil, err
+	}
+	pdfg.OutputFile = ""
+	var buf bytes.Buffer
+	pdfg.SetOutput(&buf)
+	if err := pdfg.Create(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
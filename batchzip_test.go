@@ -0,0 +1,85 @@
+package wkhtmltopdf
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readZip returns the names and contents of the entries of a zip archive in order
+func readZip(t *testing.T, data []byte) ([]string, map[string]string) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	require.NoError(t, err)
+	var names []string
+	contents := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		b, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		names = append(names, f.Name)
+		contents[f.Name] = string(b)
+	}
+	return names, contents
+}
+
+func TestBatchToZip(t *testing.T) {
+	if _, err := NewPDFGenerator(); err != nil {
+		t.Skip("wkhtmltopdf not found")
+	}
+	var running, highest atomic.Int32
+	job := func(id string, page string) BatchJob {
+		return BatchJob{
+			ID:         id,
+			OutputFile: "ignored.pdf",
+			Configure: func(pdfg *PDFGenerator) error {
+				n := running.Add(1)
+				defer running.Add(-1)
+				for {
+					h := highest.Load()
+					if n <= h || highest.CompareAndSwap(h, n) {
+						break
+					}
+				}
+				pdfg.AddPage(NewPage(page))
+				return nil
+			},
+		}
+	}
+	jobs := []BatchJob{
+		job("invoice-1", "testdata/htmlsimple.html"),
+		job("reports/q1.PDF", "testdata/htmlsimple.html"),
+		job("broken", "testdata/does-not-exist.html"),
+		job("invoice-2", "testdata/htmlsimple.html"),
+	}
+	var buf bytes.Buffer
+	err := BatchToZip(&buf, jobs, 2)
+	require.ErrorContains(t, err, "batch job broken: referenced local files do not exist")
+
+	names, contents := readZip(t, buf.Bytes())
+	require.Equal(t, []string{"invoice-1.pdf", "reports/q1.PDF", "invoice-2.pdf", BatchZipErrorsFile}, names)
+	for _, name := range names[:3] {
+		assert.True(t, bytes.HasPrefix([]byte(contents[name]), []byte("%PDF-")), name)
+	}
+	assert.Contains(t, contents[BatchZipErrorsFile], "broken: referenced local files do not exist")
+	assert.NotContains(t, contents[BatchZipErrorsFile], "invoice")
+	assert.NoFileExists(t, "ignored.pdf")
+	assert.LessOrEqual(t, highest.Load(), int32(2))
+}
+
+func TestBatchToZipInvalid(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, BatchToZip(&buf, nil, 0))
+	names, _ := readZip(t, buf.Bytes())
+	assert.Empty(t, names)
+
+	assert.EqualError(t, BatchToZip(io.Discard, []BatchJob{{ID: "../report"}}, 1), `batch job ID "../report" can't be used as name in a zip archive`)
+	assert.EqualError(t, BatchToZip(io.Discard, []BatchJob{{ID: "a"}, {ID: "a"}}, 1), `batch job ID "a" is used more than once`)
+	assert.EqualError(t, BatchToZip(io.Discard, []BatchJob{{ID: "a"}, {ID: "a.pdf"}}, 1), `batch job IDs "a" and "a.pdf" have the same name a.pdf in the zip archive`)
+}
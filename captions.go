@@ -0,0 +1,117 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	figureRegex     = regexp.MustCompile(`(?is)<figure\b([^>]*)>(.*?)</figure\s*>`)
+	figcaptionRegex = regexp.MustCompile(`(?is)<figcaption\b[^>]*>(.*?)</figcaption\s*>`)
+	tableRegex      = regexp.MustCompile(`(?is)<table\b([^>]*)>(.*?)</table\s*>`)
+	captionRegex    = regexp.MustCompile(`(?is)^\s*(?:<!--.*?-->\s*)*<caption\b[^>]*>(.*?)</caption\s*>`)
+	idAttrRegex     = regexp.MustCompile(`(?is)(?:^|\s)id\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	htmlTagsRegex   = regexp.MustCompile(`(?s)<!--.*?-->|<[^>]*>`)
+)
+
+// captionEntry is a numbered figure or table of a list built by BuildListOfFigures or BuildListOfTables
+type captionEntry struct {
+	number  int
+	caption string
+	href    string // Link to the figure or table, empty if it can't be linked
+}
+
+// BuildListOfFigures returns a page with a "List of Figures", for reports with many figures. It lists the
+// <figure> elements with a <figcaption> in the pages added so far (after Markdown and AsciiDoc conversion), in
+// the order of the pages, numbered as "Figure 1", "Figure 2", etc. with the text of the caption.
+// An entry links to its figure by the id attribute of the <figure> element, like <figure id="fig-sales">. Add the
+// page where it should appear, like after the TOC with SetPages, and set its options like those of other pages.
+// wkhtmltopdf links between pages by their URL, so only figures in pages from local files are linked; figures
+// without id and in pages from memory (PageReader, MarkdownPage, AsciiDocPage) are listed without a link, and
+// pages from URLs are not loaded, so their figures are not listed. An error is returned if a page can't be read.
+func (pdfg *PDFGenerator) BuildListOfFigures() (*PageReader, error) {
+	entries, err := pdfg.captionEntries(figureRegex, figcaptionRegex)
+	if err != nil {
+		return nil, err
+	}
+	return captionListPage("List of Figures", "list-of-figures", "Figure", entries), nil
+}
+
+// BuildListOfTables returns a page with a "List of Tables", like BuildListOfFigures for <table> elements which
+// start with a <caption>, numbered as "Table 1", "Table 2", etc. An entry links to its table by the id attribute
+// of the <table> element.
+func (pdfg *PDFGenerator) BuildListOfTables() (*PageReader, error) {
+	entries, err := pdfg.captionEntries(tableRegex, captionRegex)
+	if err != nil {
+		return nil, err
+	}
+	return captionListPage("List of Tables", "list-of-tables", "Table", entries), nil
+}
+
+// captionEntries returns the elements matched by elemRegex with a caption matched by captionRegex in their
+// content, for all pages. elemRegex must match the attributes and the content of the element.
+func (pdfg *PDFGenerator) captionEntries(elemRegex, captionRegex *regexp.Regexp) ([]captionEntry, error) {
+	var entries []captionEntry
+	for i, page := range pdfg.pages {
+		content, err := pdfg.pageHTML(i)
+		if err != nil {
+			return nil, err
+		}
+		var path string
+		if page.Reader() == nil {
+			path, _ = localPath(page.InputFile())
+		}
+		for _, m := range elemRegex.FindAllSubmatch(content, -1) {
+			caption := captionRegex.FindSubmatch(m[2])
+			if caption == nil {
+				continue
+			}
+			entry := captionEntry{number: len(entries) + 1, caption: htmlText(caption[1])}
+			if id := idAttrRegex.FindSubmatch(m[1]); id != nil && path != "" {
+				entry.href = fileURL(path, html.UnescapeString(string(bytes.Join(id[1:], nil))))
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// captionListPage returns the page of a list of figures or tables, the label is the prefix of the numbers
+func captionListPage(title, class, label string, entries []captionEntry) *PageReader {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", title)
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<ul class=\"%s\">\n", title, class)
+	for _, entry := range entries {
+		text := html.EscapeString(fmt.Sprintf("%s %d: %s", label, entry.number, entry.caption))
+		if entry.href == "" {
+			fmt.Fprintf(&b, "<li>%s</li>\n", text)
+		} else {
+			fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(entry.href), text)
+		}
+	}
+	b.WriteString("</ul>\n</body>\n</html>\n")
+	return NewPageReader(strings.NewReader(b.String()))
+}
+
+// htmlText returns the text of an HTML fragment, without tags and with whitespace collapsed
+func htmlText(fragment []byte) string {
+	text := html.UnescapeString(string(htmlTagsRegex.ReplaceAll(fragment, nil)))
+	return strings.TrimSpace(htmlWhitespaceRegex.ReplaceAllString(text, " "))
+}
+
+// fileURL returns the file:// URL of a local path with a fragment
+func fileURL(path, fragment string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // Windows paths like C:/dir
+	}
+	return (&url.URL{Scheme: "file", Path: path, Fragment: fragment}).String()
+}
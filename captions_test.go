@@ -0,0 +1,70 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readPageHTML returns the HTML of a page returned by BuildListOfFigures or BuildListOfTables
+func readPageHTML(t *testing.T, page *PageReader) string {
+	require.NotNil(t, page)
+	b, err := io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestBuildListOfFigures(t *testing.T) {
+	abs, err := filepath.Abs("testdata/figures.html")
+	require.NoError(t, err)
+	fileURL := "file://" + filepath.ToSlash(abs)
+	if !strings.HasPrefix(filepath.ToSlash(abs), "/") {
+		fileURL = "file:///" + filepath.ToSlash(abs)
+	}
+
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/figures.html"))
+	pdfg.AddPage(NewPage("https://example.com/report.html")) // not loaded
+	figures, err := pdfg.BuildListOfFigures()
+	require.NoError(t, err)
+	html := readPageHTML(t, figures)
+	assert.Contains(t, html, "<h1>List of Figures</h1>\n<ul class=\"list-of-figures\">\n"+
+		`<li><a href="`+fileURL+`#fig-revenue">Figure 1: Revenue per quarter</a></li>`+"\n"+
+		`<li><a href="`+fileURL+`#fig-costs">Figure 2: Costs &amp; margins</a></li>`+"\n"+
+		"<li>Figure 3: The team, without id</li>\n</ul>")
+
+	tables, err := pdfg.BuildListOfTables()
+	require.NoError(t, err)
+	html = readPageHTML(t, tables)
+	assert.Contains(t, html, "<h1>List of Tables</h1>\n<ul class=\"list-of-tables\">\n"+
+		`<li><a href="`+fileURL+`#tab-regions">Table 1: Sales by region</a></li>`+"\n"+
+		`<li><a href="`+fileURL+`#tab-staff">Table 2: Staff per department</a></li>`+"\n</ul>")
+}
+
+func TestBuildListOfFiguresFromMemory(t *testing.T) {
+	content, err := os.ReadFile("testdata/figures.html")
+	require.NoError(t, err)
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPageReader(bytes.NewReader(content)))
+	figures, err := pdfg.BuildListOfFigures()
+	require.NoError(t, err)
+	html := readPageHTML(t, figures)
+	assert.Contains(t, html, "<li>Figure 1: Revenue per quarter</li>")
+	assert.NotContains(t, html, "<a ")
+
+	// the page can still be rendered
+	b, err := io.ReadAll(pdfg.pages[0].Reader())
+	require.NoError(t, err)
+	assert.Equal(t, content, b)
+
+	pdfg = NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/missing.html"))
+	_, err = pdfg.BuildListOfTables()
+	assert.ErrorContains(t, err, "error reading page 1")
+}
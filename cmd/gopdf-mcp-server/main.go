@@ -47,7 +47,7 @@ var generatePdfTool = mcp.ToolDefinition{
 		Properties: map[string]mcp.PropertyDetail{
 			"input":        {Type: "string", Description: "Raw Markdown or HTML content string"}, // Updated description
 			"output":       {Type: "string", Description: "Path for output PDF file"},
-			"inputType":    {Type: "string", Description: "Input type ('markdown' or 'html')"},
+			"inputType":    {Type: "string", Description: "Input type ('markdown', 'html' or 'auto' to detect it)"},
 			"theme":        {Type: "string", Description: "Path to CSS theme file (optional)"},
 			"footer":       {Type: "string", Description: "Path to footer HTML file (optional)"},
 			"header":       {Type: "string", Description: "Path to header HTML file (optional)"},
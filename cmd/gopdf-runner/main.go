@@ -5,13 +5,21 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"regexp"
+	"strconv"
 	"strings"
 
 	wk "github.com/localrivet/gopdf" // Use our forked module path
 )
 
 // Simple map flag for replacements
-type replaceMap map[string]string
+// A replacement is global (key=value), or scoped to a page with its index (pageN:key=value)
+type replaceMap struct {
+	global map[string]string
+	pages  map[int]map[string]string
+}
+
+var pageScopeRegex = regexp.MustCompile(`^page(\d+):(.*)$`)
 
 func (r *replaceMap) String() string {
 	// Just return a placeholder, actual value isn't important for flag package
@@ -19,19 +27,46 @@ func (r *replaceMap) String() string {
 }
 
 func (r *replaceMap) Set(value string) error {
-	parts := strings.SplitN(value, "=", 2)
+	target, pair := r.global, value
+	if m := pageScopeRegex.FindStringSubmatch(value); m != nil {
+		index, err := strconv.Atoi(m[1])
+		if err != nil {
+			return fmt.Errorf("invalid page index in replace flag: %s", value)
+		}
+		if r.pages[index] == nil {
+			r.pages[index] = make(map[string]string)
+		}
+		target = r.pages[index]
+		pair = m[2]
+	}
+	parts := strings.SplitN(pair, "=", 2)
 	if len(parts) != 2 {
-		return fmt.Errorf("invalid format for replace flag: %s. Use key=value", value)
+		return fmt.Errorf("invalid format for replace flag: %s. Use key=value or pageN:key=value", value)
+	}
+	target[parts[0]] = parts[1]
+	return nil
+}
+
+// applyToPages sets the page scoped replacements on the pages, the page index must be within pages
+func (r *replaceMap) applyToPages(pages []wk.PageProvider) error {
+	for index, replacements := range r.pages {
+		if index >= len(pages) {
+			return fmt.Errorf("replace flag for page%d is out of range, there are %d page(s) (page0 to page%d)", index, len(pages), len(pages)-1)
+		}
+		for k, v := range replacements {
+			pages[index].Options().Replace.Set(k, v)
+		}
 	}
-	(*r)[parts[0]] = parts[1]
 	return nil
 }
 
 func main() {
 	// --- Define command-line flags ---
-	input := flag.String("input", "", "The raw Markdown or HTML content string (required)") // Renamed back, accepts content
+	input := flag.String("input", "", "The raw Markdown or HTML content string (required unless -inputFile is set)") // Renamed back, accepts content
+	inputFile := flag.String("inputFile", "", "Path to a Markdown or HTML file, used instead of -input without creating a temporary file (optional)")
+	convertedHTML := flag.String("convertedHTML", "", "Path to HTML already converted from the Markdown input, which is used instead of converting it again (optional)")
 	outputPath := flag.String("output", "", "Path for the generated PDF file (required)")
-	inputType := flag.String("inputType", "markdown", "Type of input content ('markdown' or 'html')")
+	inputType := flag.String("inputType", "markdown", "Type of input content ('markdown', 'html' or 'auto' to detect it)")
 	themePath := flag.String("theme", "", "Path to CSS theme file (optional)")
 	footerPath := flag.String("footer", "", "Path to footer HTML file (optional)")
 	headerPath := flag.String("header", "", "Path to header HTML file (optional)")
@@ -45,14 +80,14 @@ func main() {
 	orientation := flag.String("orientation", "", "Page orientation ('Portrait' or 'Landscape') (optional)")
 	title := flag.String("title", "", "Document title metadata (optional)")
 
-	replacements := make(replaceMap)
-	flag.Var(&replacements, "replace", "Key-value pair for header/footer replacement (key=value). Can be specified multiple times.")
+	replacements := replaceMap{global: make(map[string]string), pages: make(map[int]map[string]string)}
+	flag.Var(&replacements, "replace", "Key-value pair for header/footer replacement (key=value), or for one page only (pageN:key=value, N counts from 0). Can be specified multiple times.")
 
 	flag.Parse()
 
 	// --- Validate required flags ---
-	if *input == "" { // Use input
-		log.Fatal("Error: -input flag is required") // Use correct flag name in message
+	if (*input == "") == (*inputFile == "") {
+		log.Fatal("Error: either the -input or the -inputFile flag is required")
 	}
 	if *outputPath == "" {
 		log.Fatal("Error: -output flag is required")
@@ -74,17 +109,24 @@ func main() {
 	if *orientation != "" {
 		pdfg.Orientation.Set(*orientation)
 	}
-	if *marginTop != "" {
-		pdfg.MarginTopUnit.Set(*marginTop)
-	}
-	if *marginBottom != "" {
-		pdfg.MarginBottomUnit.Set(*marginBottom)
-	}
-	if *marginLeft != "" {
-		pdfg.MarginLeftUnit.Set(*marginLeft)
-	}
-	if *marginRight != "" {
-		pdfg.MarginRightUnit.Set(*marginRight)
+	for _, margin := range []struct {
+		flag   string
+		value  string
+		option interface{ Set(string) }
+	}{
+		{"marginTop", *marginTop, &pdfg.MarginTopUnit},
+		{"marginBottom", *marginBottom, &pdfg.MarginBottomUnit},
+		{"marginLeft", *marginLeft, &pdfg.MarginLeftUnit},
+		{"marginRight", *marginRight, &pdfg.MarginRightUnit},
+	} {
+		if margin.value == "" {
+			continue
+		}
+		length, err := wk.ParseLength(margin.value)
+		if err != nil {
+			log.Fatalf("Error: invalid -%s: %v", margin.flag, err)
+		}
+		margin.option.Set(length.String())
 	}
 	if *themePath != "" {
 		pdfg.SetUserStyleSheet(*themePath)
@@ -103,7 +145,7 @@ func main() {
 			log.Printf("Warning: Cover file not found at %s, skipping cover.", *coverPath)
 		}
 	}
-	for k, v := range replacements {
+	for k, v := range replacements.global {
 		pdfg.SetReplace(k, v)
 	}
 
@@ -111,34 +153,66 @@ func main() {
 	var pageProvider wk.PageProvider
 	var tempFile *os.File // For temporary markdown file
 
+	if strings.EqualFold(*inputType, "auto") {
+		content := []byte(*input)
+		if *inputFile != "" {
+			content, err = os.ReadFile(*inputFile)
+			if err != nil {
+				log.Fatalf("Error reading input file: %v", err)
+			}
+		}
+		*inputType = wk.DetectInputType(content)
+	}
+	if *convertedHTML != "" && !strings.EqualFold(*inputType, "markdown") {
+		log.Fatal("Error: -convertedHTML can only be used with Markdown input")
+	}
+
 	switch strings.ToLower(*inputType) {
 	case "markdown":
-		// Create a temporary file for markdown content
-		tmpFile, err := os.CreateTemp("", "input-*.md")
-		if err != nil {
-			log.Fatalf("Error creating temporary markdown file: %v", err)
-		}
-		tempFile = tmpFile // Store to remove later
-		if _, err := tmpFile.WriteString(*input); err != nil {
-			tmpFile.Close()           // Close on error
-			os.Remove(tmpFile.Name()) // Attempt cleanup
-			log.Fatalf("Error writing to temporary markdown file: %v", err)
+		mdPath := *inputFile
+		if mdPath == "" && *convertedHTML == "" {
+			// Create a temporary file for markdown content
+			tmpFile, err := os.CreateTemp("", "input-*.md")
+			if err != nil {
+				log.Fatalf("Error creating temporary markdown file: %v", err)
+			}
+			tempFile = tmpFile // Store to remove later
+			if _, err := tmpFile.WriteString(*input); err != nil {
+				tmpFile.Close()           // Close on error
+				os.Remove(tmpFile.Name()) // Attempt cleanup
+				log.Fatalf("Error writing to temporary markdown file: %v", err)
+			}
+			if err := tmpFile.Close(); err != nil {
+				os.Remove(tmpFile.Name()) // Attempt cleanup
+				log.Fatalf("Error closing temporary markdown file: %v", err)
+			}
+			mdPath = tmpFile.Name()
 		}
-		if err := tmpFile.Close(); err != nil {
-			os.Remove(tmpFile.Name()) // Attempt cleanup
-			log.Fatalf("Error closing temporary markdown file: %v", err)
+
+		if *convertedHTML != "" {
+			// The Markdown was already converted, the path is only kept as metadata
+			html, err := os.ReadFile(*convertedHTML)
+			if err != nil {
+				log.Fatalf("Error reading converted HTML file: %v", err)
+			}
+			pageProvider = wk.NewMarkdownPageFromHTML(mdPath, html)
+			break
 		}
 
-		// Use the temporary file path with NewMarkdownPage
-		mdPage := wk.NewMarkdownPage(tmpFile.Name())
+		// Use the file path with NewMarkdownPage
+		mdPage := wk.NewMarkdownPage(mdPath)
 		mdPage.SkipFirstH1H2 = *skipH1H2
 		pageProvider = mdPage
 
 	case "html":
+		if *inputFile != "" {
+			pageProvider = wk.NewPage(*inputFile)
+			break
+		}
 		// Use NewPageReader for HTML content string
 		pageProvider = wk.NewPageReader(strings.NewReader(*input))
 	default:
-		log.Fatalf("Error: Invalid -inputType '%s'. Use 'markdown' or 'html'.", *inputType)
+		log.Fatalf("Error: Invalid -inputType '%s'. Use 'markdown', 'html' or 'auto'.", *inputType)
 	}
 
 	// Defer removal of temporary file if it was created
@@ -147,6 +221,10 @@ func main() {
 	}
 
 	pdfg.AddPage(pageProvider)
+	pages := []wk.PageProvider{pageProvider}
+	if err := replacements.applyToPages(pages); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 
 	// --- Generate PDF ---
 	err = pdfg.Create()
@@ -1,8 +1,18 @@
-//go:build !windows
-// +build !windows
+//go:build !windows && !unix
+// +build !windows,!unix
 
 package wkhtmltopdf
 
-import "os/exec"
+import (
+	"fmt"
+	"os/exec"
+)
 
 func cmdConfig(cmd *exec.Cmd) {}
+
+func cmdPriority(cmd *exec.Cmd, priority ProcessPriority) error {
+	if priority != PriorityNormal {
+		return fmt.Errorf("process priority %s is not supported on this system", priority)
+	}
+	return nil
+}
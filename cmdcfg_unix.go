@@ -0,0 +1,33 @@
+//go:build unix
+// +build unix
+
+package wkhtmltopdf
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+// niceIncrements are the increments of the nice value of the priorities, the nice value is at most 19
+var niceIncrements = map[ProcessPriority]int{PriorityBelowNormal: 10, PriorityIdle: 19}
+
+func cmdConfig(cmd *exec.Cmd) {}
+
+// cmdPriority makes cmd run through the nice command, so the process has the nice value from the start, also all
+// the threads it starts
+func cmdPriority(cmd *exec.Cmd, priority ProcessPriority) error {
+	increment, ok := niceIncrements[priority]
+	if !ok {
+		return nil
+	}
+	if _, err := exec.LookPath(cmd.Path); err != nil {
+		return nil // Start reports the missing binary
+	}
+	nice, err := exec.LookPath("nice")
+	if err != nil {
+		return err
+	}
+	cmd.Args = append([]string{"nice", "-n", strconv.Itoa(increment), cmd.Path}, cmd.Args[1:]...)
+	cmd.Path = nice
+	return nil
+}
@@ -8,6 +8,21 @@ import (
 	"syscall"
 )
 
+// priorityClasses are the process creation flags of the priorities
+var priorityClasses = map[ProcessPriority]uint32{
+	PriorityBelowNormal: 0x00004000, // BELOW_NORMAL_PRIORITY_CLASS
+	PriorityIdle:        0x00000040, // IDLE_PRIORITY_CLASS
+}
+
 func cmdConfig(cmd *exec.Cmd) {
 	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: 0x08000000, HideWindow: true}
 }
+
+// cmdPriority adds the priority class to the creation flags, cmdConfig must be called before
+func cmdPriority(cmd *exec.Cmd, priority ProcessPriority) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= priorityClasses[priority]
+	return nil
+}
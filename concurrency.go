@@ -0,0 +1,45 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"sync"
+)
+
+// renderSlots limits the number of wkhtmltopdf processes running at once, see SetMaxConcurrentRenders
+var renderSlots struct {
+	sem chan struct{}
+	sync.Mutex
+}
+
+// SetMaxConcurrentRenders limits the number of wkhtmltopdf processes which run at the same time in this program,
+// over all PDFGenerators, like to keep many concurrent Create calls from overloading the machine. Create waits
+// for a free slot before starting wkhtmltopdf, or until its context is done, and the waiting time is not part of
+// the Duration of the RenderResult. The extra runs which count pages, for SetExcludeCoverFromNumbering,
+// SetForceOddStart, SetTOCMinPages, SetFitToOnePage, IncludePages and AppendPDFAt, take a slot as well. 0 or less
+// means unlimited, which is the default. Runs which already wait or run keep the limit which was set when they
+// started waiting.
+func SetMaxConcurrentRenders(n int) {
+	renderSlots.Lock()
+	defer renderSlots.Unlock()
+	if n <= 0 {
+		renderSlots.sem = nil
+		return
+	}
+	renderSlots.sem = make(chan struct{}, n)
+}
+
+// acquireRenderSlot waits for a free slot to run wkhtmltopdf and returns a function to release it
+func acquireRenderSlot(ctx context.Context) (func(), error) {
+	renderSlots.Lock()
+	sem := renderSlots.sem
+	renderSlots.Unlock()
+	if sem == nil {
+		return func() {}, nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
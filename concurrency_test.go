@@ -0,0 +1,92 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSlowCommand returns the path of a fake wkhtmltopdf which takes a while and logs when it starts and ends,
+// and the path of the log
+func newSlowCommand(t *testing.T) (string, string) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	script := "#!/bin/sh\necho start >> " + log + "\nsleep 0.2\necho end >> " + log + "\nprintf '%%PDF-1.4\\n'\n"
+	path := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path, log
+}
+
+// maxConcurrent returns the highest number of processes which ran at once according to the log of newSlowCommand
+func maxConcurrent(t *testing.T, log string) int {
+	b, err := os.ReadFile(log)
+	require.NoError(t, err)
+	running, highest := 0, 0
+	for _, line := range strings.Fields(string(b)) {
+		if line == "start" {
+			running++
+		} else {
+			running--
+		}
+		highest = max(highest, running)
+	}
+	return highest
+}
+
+// renderConcurrently runs Create on n generators using bin at the same time
+func renderConcurrently(t *testing.T, bin string, n int) {
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		pdfg := NewPDFPreparer()
+		pdfg.binPath = bin
+		pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, pdfg.Create())
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSetMaxConcurrentRenders(t *testing.T) {
+	bin, log := newSlowCommand(t)
+	SetMaxConcurrentRenders(2)
+	defer SetMaxConcurrentRenders(0)
+	renderConcurrently(t, bin, 6)
+	assert.Equal(t, 2, maxConcurrent(t, log))
+
+	// unlimited
+	bin, log = newSlowCommand(t)
+	SetMaxConcurrentRenders(0)
+	renderConcurrently(t, bin, 4)
+	assert.Equal(t, 4, maxConcurrent(t, log))
+}
+
+func TestAcquireRenderSlotContext(t *testing.T) {
+	SetMaxConcurrentRenders(1)
+	defer SetMaxConcurrentRenders(0)
+
+	release, err := acquireRenderSlot(context.Background())
+	require.NoError(t, err)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = acquireRenderSlot(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	release()
+	release, err = acquireRenderSlot(context.Background())
+	require.NoError(t, err)
+	release()
+}
@@ -0,0 +1,154 @@
+package wkhtmltopdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// generatorConfig is the part of a PDFGenerator compared by ConfigHash
+type generatorConfig struct {
+	GlobalArgs       []string
+	OutlineArgs      []string
+	RawArgs          []string
+	CoverInput       string
+	CoverArgs        []string
+	TOC              bool
+	TOCArgs          []string
+	UserStyleSheet   string
+	UserStyleSheets  []string
+	SizeStyleSheets  map[string]string
+	HeaderHTML       string
+	HeaderLogo       string
+	HeaderLogoAt     string
+	FooterHTML       string
+	Replace          map[string]string
+	ReplaceEnv       bool
+	ReplaceEnvStrict bool
+	ReplaceEnvVars   map[string]string
+	CustomHeaders    map[string]string
+	PropagateHeaders bool
+	Lang             string
+	HeaderFont       string
+	HeaderFontSize   uint
+	FooterFont       string
+	FooterFontSize   uint
+	Zoom             float64
+	PrintMediaType   bool
+	EnableForms      bool
+	CSP              string
+	MarkdownChapters bool
+	FontFallback     []string
+	SafeMode         bool
+	NoSmartShrinking bool
+	AutoOrientation  bool
+	PageNumberOffset int
+	ExcludeCover     bool
+	ForceOddStart    bool
+	FitToOnePage     bool
+	TOCMinPages      int
+	TOCConfig        *TOCConfig
+	TrimBlankPages   bool
+	Provenance       bool
+	OutputIntent     []byte
+	OutputIntentID   string
+	PageLabels       []PageLabelRange
+	Viewer           *ViewerPreferences
+	PDFVersion       string
+	NUp              *jsonNUp
+	Tagged           bool
+	Locale           string
+	Deterministic    bool
+	EmbedSource      bool
+	MaxOutputBytes   int64
+	AppendedPDFs     []appendedPDF
+}
+
+// ConfigHash returns a hash of the configuration of the generator, for use as a cache key: generators with the
+// same hash render the same pages the same way. It covers the global and outline options, raw args, the cover and
+// its options, the TOC and its options, appearance and minimum pages, the locale, the global settings applied to
+// pages by AddPage (style sheets, also by page size, header and footer HTML and fonts, replacements, custom
+// headers, language, zoom, print media type, form fields, the Content Security Policy, Markdown chapters, font
+// fallback, safe mode and smart shrinking), the header logo, the automatic orientation and the settings of the
+// built-in post-processing (page numbering, odd start, fitting to one page, trimming blank pages, provenance,
+// output intent, page labels, viewer preferences, PDF version, the n-up layout, the structure tree, deterministic
+// output, the embedded source and the maximum output size) and the PDFs added with AppendPDFAt.
+// It does not cover the pages and their options, OutputFile, the output and stderr writers, post-processors added
+// with AddPostProcessor, the path of the wkhtmltopdf binary and the style sheet fetching settings. The contents of
+// referenced files (like style sheets) are not read, only their paths are compared.
+func (pdfg *PDFGenerator) ConfigHash() string {
+	cfg := generatorConfig{
+		GlobalArgs:       pdfg.globalOptions.Args(),
+		OutlineArgs:      pdfg.outlineOptions.Args(),
+		RawArgs:          pdfg.rawArgs,
+		CoverInput:       pdfg.Cover.Input,
+		CoverArgs:        pdfg.Cover.pageOptions.Args(),
+		TOC:              pdfg.TOC.Include,
+		UserStyleSheet:   pdfg.userStyleSheetPath,
+		UserStyleSheets:  pdfg.userStyleSheets,
+		SizeStyleSheets:  pdfg.sizeStyleSheets,
+		HeaderHTML:       pdfg.headerHTMLPath,
+		FooterHTML:       pdfg.footerHTMLPath,
+		Replace:          pdfg.replace.value,
+		ReplaceEnv:       pdfg.replaceEnv.enabled,
+		ReplaceEnvStrict: pdfg.replaceEnv.strict,
+		ReplaceEnvVars:   pdfg.replaceEnv.vars,
+		CustomHeaders:    pdfg.customHeader.value,
+		PropagateHeaders: pdfg.propagateHeaders,
+		Lang:             pdfg.lang,
+		HeaderFont:       pdfg.headerStyle.fontName,
+		HeaderFontSize:   pdfg.headerStyle.fontSize,
+		FooterFont:       pdfg.footerStyle.fontName,
+		FooterFontSize:   pdfg.footerStyle.fontSize,
+		Zoom:             pdfg.zoom,
+		PrintMediaType:   pdfg.printMediaType,
+		EnableForms:      pdfg.enableForms,
+		CSP:              pdfg.csp,
+		MarkdownChapters: pdfg.markdownChapters,
+		FontFallback:     pdfg.fontFallback,
+		SafeMode:         pdfg.safeMode,
+		NoSmartShrinking: pdfg.imageRendering.DisableSmartShrinking,
+		AutoOrientation:  pdfg.autoOrientation,
+		PageNumberOffset: pdfg.pageNumberOffset,
+		ExcludeCover:     pdfg.excludeCover,
+		ForceOddStart:    pdfg.forceOddStart,
+		FitToOnePage:     pdfg.fitToOnePage,
+		TOCMinPages:      pdfg.tocMinPages,
+		TOCConfig:        pdfg.tocConfig,
+		TrimBlankPages:   pdfg.trimBlankPages,
+		Provenance:       pdfg.provenance,
+		PageLabels:       pdfg.pageLabels,
+		Viewer:           pdfg.viewerPrefs,
+		PDFVersion:       pdfg.pdfVersion,
+		Tagged:           pdfg.tagged,
+		Locale:           pdfg.locale,
+		Deterministic:    pdfg.deterministic,
+		EmbedSource:      pdfg.embedSource,
+		MaxOutputBytes:   pdfg.maxOutputBytes,
+		AppendedPDFs:     pdfg.appendedPDFs,
+	}
+	if pdfg.TOC.Include {
+		cfg.TOCArgs = append(append(pdfg.TOC.pageOptions.Args(), pdfg.TOC.tocOptions.Args()...), pdfg.TOC.headerAndFooterOptions.Args()...)
+	}
+	if n := pdfg.nUp; n != nil {
+		cfg.NUp = &jsonNUp{Cols: n.cols, Rows: n.rows, NUpLayout: n.layout}
+	}
+	if hl := pdfg.headerLogo; hl != nil {
+		cfg.HeaderLogo = hl.path
+		cfg.HeaderLogoAt = hl.position
+	}
+	if oi := pdfg.outputIntent; oi != nil {
+		cfg.OutputIntent = oi.profile
+		cfg.OutputIntentID = oi.identifier
+	}
+
+	// the config only contains types which can be marshaled, and maps are marshaled with sorted keys
+	b, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// ConfigEqual reports whether both generators have the same configuration, see ConfigHash for what is compared
+func (pdfg *PDFGenerator) ConfigEqual(other *PDFGenerator) bool {
+	return pdfg.ConfigHash() == other.ConfigHash()
+}
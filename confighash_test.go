@@ -0,0 +1,68 @@
+package wkhtmltopdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigHash(t *testing.T) {
+	newGenerator := func() *PDFGenerator {
+		pdfg := NewPDFPreparer()
+		pdfg.PageSize.Set(PageSizeA4)
+		pdfg.SetCover("testdata/htmlsimple.html")
+		pdfg.TOC.Include = true
+		pdfg.TOC.TocHeaderText.Set("Contents")
+		pdfg.SetReplace("b", "2")
+		pdfg.SetReplace("a", "1")
+		pdfg.SetLang("en")
+		return pdfg
+	}
+
+	a, b := newGenerator(), newGenerator()
+	assert.Len(t, a.ConfigHash(), 64)
+	assert.Equal(t, a.ConfigHash(), b.ConfigHash())
+
+	// pages and the output are not part of the configuration
+	a.AddPage(NewPage("testdata/htmlsimple.html"))
+	a.OutputFile = "out.pdf"
+	assert.True(t, a.ConfigEqual(b))
+
+	changes := map[string]func(pdfg *PDFGenerator){
+		"global option":  func(pdfg *PDFGenerator) { pdfg.Grayscale.Set(true) },
+		"outline option": func(pdfg *PDFGenerator) { require.NoError(t, pdfg.SetOutlineDepth(2)) },
+		"raw arg":        func(pdfg *PDFGenerator) { pdfg.AddRawArg("--log-level", "none") },
+		"cover":          func(pdfg *PDFGenerator) { pdfg.SetCoverZoom(2) },
+		"toc option":     func(pdfg *PDFGenerator) { pdfg.TOC.DisableDottedLines.Set(true) },
+		"replace":        func(pdfg *PDFGenerator) { pdfg.SetReplace("a", "3") },
+		"style sheet":    func(pdfg *PDFGenerator) { pdfg.SetUserStyleSheet("testdata/theme.css") },
+		"zoom":           func(pdfg *PDFGenerator) { require.NoError(t, pdfg.SetZoom(1.5)) },
+		"font fallback":  func(pdfg *PDFGenerator) { require.NoError(t, pdfg.SetFontFallback([]string{"Noto Sans"})) },
+		"safe mode":      func(pdfg *PDFGenerator) { pdfg.SetSafeMode(true) },
+		"header logo":    func(pdfg *PDFGenerator) { require.NoError(t, pdfg.SetHeaderLogo("testdata/logo.png", "left")) },
+		"pdf version":    func(pdfg *PDFGenerator) { require.NoError(t, pdfg.SetPDFVersion("1.7")) },
+		"n-up":           func(pdfg *PDFGenerator) { require.NoError(t, pdfg.NUp(2, 1, NUpLayout{})) },
+		"toc min pages":  func(pdfg *PDFGenerator) { pdfg.SetTOCMinPages(3) },
+		"locale":         func(pdfg *PDFGenerator) { require.NoError(t, pdfg.SetLocale("de_DE.UTF-8")) },
+		"deterministic":  func(pdfg *PDFGenerator) { pdfg.SetDeterministic(true) },
+		"embed source":   func(pdfg *PDFGenerator) { pdfg.EmbedSource(true) },
+		"toc config":     func(pdfg *PDFGenerator) { require.NoError(t, pdfg.ConfigureTOC(TOCConfig{DepthLimit: 2})) },
+		"append pdf":     func(pdfg *PDFGenerator) { require.NoError(t, pdfg.AppendPDFAt(0, "a.pdf", "A")) },
+		"fit to page":    func(pdfg *PDFGenerator) { pdfg.SetFitToOnePage(true) },
+		"csp":            func(pdfg *PDFGenerator) { pdfg.SetCSP("default-src 'none'") },
+		"md chapters":    func(pdfg *PDFGenerator) { pdfg.SetMarkdownChapters(true) },
+		"size css":       func(pdfg *PDFGenerator) { pdfg.SetUserStyleSheetFor(PageSizeA4, "a4.css") },
+		"page labels": func(pdfg *PDFGenerator) {
+			require.NoError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 1, Style: PageLabelDecimal}}))
+		},
+		"image rendering": func(pdfg *PDFGenerator) {
+			require.NoError(t, pdfg.SetImageRendering(ImageRenderOptions{DisableSmartShrinking: true}))
+		},
+	}
+	for name, change := range changes {
+		pdfg := newGenerator()
+		change(pdfg)
+		assert.False(t, pdfg.ConfigEqual(b), name)
+	}
+}
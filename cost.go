@@ -0,0 +1,141 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// CostRisk is how expensive rendering a document is estimated to be by EstimateCost
+type CostRisk int
+
+const (
+	CostLow    CostRisk = iota // Renders in a few seconds with little memory
+	CostMedium                 // Large documents or many resources, rendering can take a while
+	CostHigh                   // Likely to need a lot of memory or time, or to hit a timeout
+)
+
+func (r CostRisk) String() string {
+	switch r {
+	case CostLow:
+		return "low"
+	case CostMedium:
+		return "medium"
+	case CostHigh:
+		return "high"
+	}
+	return "unknown"
+}
+
+// CostEstimate is the estimated cost of rendering a document, see EstimateCost
+type CostEstimate struct {
+	EstimatedPages      int   // The number of PDF pages, including the cover and the TOC
+	InputBytes          int64 // The size of the HTML of the pages and of the local files they use
+	RemoteResourceCount int   // The http(s) URLs loaded, see ListExternalResources
+	ImageCount          int   // The images in the HTML of the pages, including CSS background images
+	Risk                CostRisk
+}
+
+// thresholds of EstimateCost for a medium and a high risk, any value above them raises the risk
+const (
+	costMediumPages  = 50
+	costMediumBytes  = 5 << 20
+	costMediumRemote = 10
+	costMediumImages = 100
+	costHighPages    = 500
+	costHighBytes    = 50 << 20
+	costHighRemote   = 100
+	costHighImages   = 1000
+)
+
+// page size assumed by EstimateCost, for A4 pages with the default margins and a 12pt font
+const (
+	costCharsPerPage  = 3000
+	costLinesPerPage  = 45
+	costImagesPerPage = 4
+)
+
+var (
+	costNoTextRegex    = regexp.MustCompile(`(?is)<script\b.*?</script\s*>|<style\b.*?</style\s*>`)
+	costLineRegex      = regexp.MustCompile(`(?i)<(?:p|tr|li|br|h[1-6]|dt|dd|pre|blockquote)\b`)
+	costImageRegex     = regexp.MustCompile(`(?i)<(?:img|svg)\b|background(?:-image)?\s*:[^;"'}]*url\(`)
+	costPageBreakRegex = regexp.MustCompile(`(?i)page-break-(?:before|after)\s*:\s*always|break-(?:before|after)\s*:\s*page`)
+)
+
+// EstimateCost estimates how expensive rendering the document is, without rendering it and without running or
+// needing wkhtmltopdf, so a service can reject pathological jobs up front. The estimate is a heuristic: the pages
+// are estimated from the amount of text, the number of paragraphs, table rows and list items, the images and the
+// forced page breaks in the HTML of each page (after Markdown and AsciiDoc conversion, see RenderHTMLOnly),
+// assuming A4 pages with the default margins and font size. Page breaks set by a CSS rule are counted once, not
+// for every element they apply to. URLs are not loaded, so pages loaded from URLs count as one page and their
+// size and resources are unknown. The risk is high when any value is far beyond a typical document, like more
+// than 500 pages, 50MB of input, 100 remote resources or 1000 images, and medium from a tenth of that. Use the
+// values for limits of your own, together with a deadline for CreateContext and SetMaxOutputBytes for the cases
+// the estimate misses.
+func (pdfg *PDFGenerator) EstimateCost() (CostEstimate, error) {
+	remote, err := pdfg.ListExternalResources()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+	est := CostEstimate{RemoteResourceCount: len(remote)}
+
+	pageInputs := make(map[string]bool)
+	for _, page := range pdfg.pages {
+		pageInputs[page.InputFile()] = true
+	}
+	seen := make(map[string]bool)
+	for _, ref := range pdfg.fileRefs() {
+		path, ok := localPath(ref.path)
+		if !ok || pageInputs[ref.path] || seen[path] {
+			continue // pages are measured below
+		}
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil {
+			return CostEstimate{}, fmt.Errorf("error reading %s: %w", ref.location, err)
+		}
+		est.InputBytes += info.Size()
+	}
+
+	if pdfg.Cover.Input != "" {
+		est.EstimatedPages++
+	}
+	if pdfg.TOC.Include {
+		est.EstimatedPages++
+	}
+	for i := range pdfg.pages {
+		html, err := pdfg.pageHTML(i)
+		if err != nil {
+			return CostEstimate{}, err
+		}
+		images := len(costImageRegex.FindAllIndex(html, -1))
+		est.InputBytes += int64(len(html))
+		est.ImageCount += images
+		est.EstimatedPages += estimatePages(html, images)
+	}
+	est.Risk = est.risk()
+	return est, nil
+}
+
+// estimatePages estimates the number of PDF pages of a HTML document with the given number of images, at least 1
+func estimatePages(html []byte, images int) int {
+	text := costNoTextRegex.ReplaceAll(html, nil)
+	text = htmlTagsRegex.ReplaceAll(text, []byte(" "))
+	text = htmlWhitespaceRegex.ReplaceAll(text, []byte(" "))
+	lines := len(costLineRegex.FindAllIndex(html, -1))
+	breaks := len(costPageBreakRegex.FindAllIndex(html, -1))
+	return 1 + max(len(text)/costCharsPerPage, lines/costLinesPerPage) + images/costImagesPerPage + breaks
+}
+
+// risk returns the risk for the estimated values
+func (e CostEstimate) risk() CostRisk {
+	switch {
+	case e.EstimatedPages > costHighPages || e.InputBytes > costHighBytes ||
+		e.RemoteResourceCount > costHighRemote || e.ImageCount > costHighImages:
+		return CostHigh
+	case e.EstimatedPages > costMediumPages || e.InputBytes > costMediumBytes ||
+		e.RemoteResourceCount > costMediumRemote || e.ImageCount > costMediumImages:
+		return CostMedium
+	}
+	return CostLow
+}
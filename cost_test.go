@@ -0,0 +1,62 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	tests := []struct {
+		name   string
+		inputs []string
+		toc    bool
+		want   CostEstimate
+	}{
+		{"simple", []string{"testdata/htmlsimple.html"}, false, CostEstimate{EstimatedPages: 1, InputBytes: 94}},
+		{"page breaks", []string{"testdata/cost/chapters.html"}, true, CostEstimate{EstimatedPages: 4, InputBytes: 339, ImageCount: 1}},
+		{"resources", []string{"testdata/resources/page.html"}, false, CostEstimate{EstimatedPages: 2, InputBytes: 907, RemoteResourceCount: 8, ImageCount: 5}},
+		{"url", []string{"https://example.com/report.html", "testdata/htmlsimple.html"}, false, CostEstimate{EstimatedPages: 2, InputBytes: 94, RemoteResourceCount: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdfg := NewPDFPreparer()
+			for _, input := range tt.inputs {
+				pdfg.AddPage(NewPage(input))
+			}
+			pdfg.TOC.Include = tt.toc
+			est, err := pdfg.EstimateCost()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, est)
+		})
+	}
+}
+
+func TestEstimateCostRisk(t *testing.T) {
+	var html strings.Builder
+	html.WriteString("<html><body>")
+	for i := range 3000 {
+		fmt.Fprintf(&html, "<p>Paragraph %d of a very long report.</p>\n", i)
+	}
+	html.WriteString("</body></html>")
+
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPageReader(strings.NewReader(html.String())))
+	est, err := pdfg.EstimateCost()
+	require.NoError(t, err)
+	assert.Equal(t, 1+3000/costLinesPerPage, est.EstimatedPages)
+	assert.Equal(t, CostMedium, est.Risk)
+
+	// the page can still be rendered afterwards
+	b, err := io.ReadAll(pdfg.pages[0].Reader())
+	require.NoError(t, err)
+	assert.Equal(t, html.String(), string(b))
+
+	assert.Equal(t, CostHigh, CostEstimate{ImageCount: costHighImages + 1}.risk())
+	assert.Equal(t, CostLow, CostEstimate{EstimatedPages: costMediumPages}.risk())
+	assert.Equal(t, "medium", CostMedium.String())
+}
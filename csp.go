@@ -0,0 +1,42 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"html"
+)
+
+// SetCSP sets a Content Security Policy for the pages, like "default-src 'none'; style-src 'unsafe-inline'", to
+// block scripts and external resources of untrusted HTML in WebKit. A <meta http-equiv="Content-Security-Policy">
+// element with the policy is inserted at the start of the <head> of the HTML of all pages (also pages added later),
+// before any script or resource of the page. Pages read from a local file get it in a temporary copy with a
+// <base> pointing to the original directory, like for SetResourceAllowlist; URL pages can't be changed, Validate
+// returns an error for them. The cover and the TOC are not changed. An empty policy, the default, adds no policy.
+// The WebKit of wkhtmltopdf 0.12 (QtWebKit 2.2) is older than the Content Security Policy standard, so most
+// builds ignore the policy or only enforce parts of it: nonces, hashes, 'strict-dynamic', report-uri and the
+// directives of later versions are not supported. Don't rely on it alone for untrusted content: use SetSafeMode,
+// which disables JavaScript, local file access and network requests in wkhtmltopdf itself, and
+// SetResourceAllowlist, which removes disallowed resources from the HTML.
+func (pdfg *PDFGenerator) SetCSP(policy string) {
+	pdfg.csp = policy
+	for _, page := range pdfg.pages {
+		page.Options().csp = policy
+	}
+}
+
+// cspMeta returns the meta element which sets the Content Security Policy
+func cspMeta(policy string) string {
+	return `<meta http-equiv="Content-Security-Policy" content="` + html.EscapeString(policy) + `">`
+}
+
+// checkCSP returns an error for URL pages with a Content Security Policy, which can't be injected
+func (pdfg *PDFGenerator) checkCSP() error {
+	for i, page := range pdfg.pages {
+		if page.Options().csp == "" || page.Reader() != nil {
+			continue
+		}
+		if _, ok := localPath(page.InputFile()); !ok {
+			return fmt.Errorf("page %d: the content security policy can't be applied to URL %s", i+1, page.InputFile())
+		}
+	}
+	return nil
+}
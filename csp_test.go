@@ -0,0 +1,99 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSP = `script-src 'none'; img-src "data:"`
+
+func TestCSPReader(t *testing.T) {
+	html, err := os.ReadFile("testdata/csp.html")
+	require.NoError(t, err)
+	pdfg := NewPDFPreparer()
+	pdfg.SetCSP(testCSP)
+	pdfg.AddPage(NewPageReader(bytes.NewReader(html)))
+
+	r, err := stdinReader(pdfg.pages[0])
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	meta := `<meta http-equiv="Content-Security-Policy" content="script-src &#39;none&#39;; img-src &#34;data:&#34;">`
+	assert.Contains(t, string(b), "<head>"+meta+`<meta charset="utf-8">`)
+	assert.Less(t, strings.Index(string(b), meta), strings.Index(string(b), "<script>"))
+}
+
+func TestCSPFilePage(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/csp.html"))
+	pdfg.SetCSP(testCSP)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.Validate())
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	defer cleanup()
+	args := pdfg.Args()
+	assert.NotContains(t, args, "testdata/csp.html")
+	assert.NotContains(t, args, "testdata/htmlsimple.html")
+	b, err := os.ReadFile(pdfg.spilled[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `<meta http-equiv="Content-Security-Policy"`)
+	assert.Contains(t, string(b), "<script>document.title")
+}
+
+func TestCSPURLPage(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCSP("default-src 'self'")
+	pdfg.AddPage(NewPage("https://example.com/report.html"))
+	assert.EqualError(t, pdfg.Validate(), "page 1: the content security policy can't be applied to URL https://example.com/report.html")
+
+	pdfg.SetCSP("")
+	assert.NoError(t, pdfg.Validate())
+}
+
+func TestCSPJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCSP("default-src 'none'")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	b, err := pdfg.ToJSON()
+	require.NoError(t, err)
+
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	restored.AddPage(NewPage("testdata/htmlsimple.html"))
+	for _, page := range restored.pages {
+		assert.Equal(t, "default-src 'none'", page.Options().csp)
+	}
+}
+
+func TestCSPBlocksInlineScript(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	if err != nil {
+		t.Skip("wkhtmltopdf not found")
+	}
+	html, err := os.ReadFile("testdata/csp.html")
+	require.NoError(t, err)
+	pdfg.SetCSP("script-src 'none'")
+	pdfg.AddPage(NewPageReader(bytes.NewReader(html)))
+	require.NoError(t, pdfg.Create())
+
+	// the script changes the document title, which wkhtmltopdf uses as the PDF title
+	doc, err := parsePDF(pdfg.Bytes())
+	require.NoError(t, err)
+	title, ok := doc.resolve(doc.dict(doc.trailer.Get("Info")).Get("Title")).(pdfString)
+	if !ok {
+		t.Skip("this wkhtmltopdf doesn't set the PDF title")
+	}
+	if strings.Contains(strings.ReplaceAll(string(title), "\x00", ""), "SCRIPT RAN") {
+		t.Skip("this wkhtmltopdf doesn't enforce the Content Security Policy")
+	}
+	assert.Contains(t, strings.ReplaceAll(string(title), "\x00", ""), "Original")
+}
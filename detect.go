@@ -0,0 +1,52 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Input types returned by DetectInputType
+const (
+	InputTypeHTML     = "html"
+	InputTypeMarkdown = "markdown"
+)
+
+var (
+	htmlDocumentRegex = regexp.MustCompile(`(?i)^(<\?xml[^>]*>\s*)?(<!--.*?-->\s*)*(<!DOCTYPE\s+html|<html[\s>])`)
+	htmlTagRegex      = regexp.MustCompile(`</?[a-zA-Z][a-zA-Z0-9-]*(\s[^<>]*)?/?>`)
+	htmlCloseTagRegex = regexp.MustCompile(`</[a-zA-Z][a-zA-Z0-9-]*\s*>`)
+)
+
+// DetectInputType guesses whether content is HTML or Markdown and returns InputTypeHTML or InputTypeMarkdown.
+// Content is HTML if it starts with <!DOCTYPE html> or <html>, or if it starts with a tag and most of it
+// consists of tags, including closing tags. Everything else, including Markdown with some inline HTML and
+// content which is too ambiguous to tell, is Markdown.
+func DetectInputType(content []byte) string {
+	content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if htmlDocumentRegex.Match(content) {
+		return InputTypeHTML
+	}
+	if len(content) == 0 || content[0] != '<' || !htmlCloseTagRegex.Match(content) {
+		return InputTypeMarkdown
+	}
+
+	// HTML fragments: at least half of the non-whitespace characters must be part of a tag
+	var tagBytes int
+	for _, loc := range htmlTagRegex.FindAllIndex(content, -1) {
+		tagBytes += nonSpaceCount(content[loc[0]:loc[1]])
+	}
+	if total := nonSpaceCount(content); tagBytes*2 >= total {
+		return InputTypeHTML
+	}
+	return InputTypeMarkdown
+}
+
+func nonSpaceCount(b []byte) int {
+	var n int
+	for _, c := range b {
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			n++
+		}
+	}
+	return n
+}
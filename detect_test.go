@@ -0,0 +1,39 @@
+package wkhtmltopdf
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectInputType(t *testing.T) {
+	html, err := os.ReadFile("testdata/htmlsimple.html")
+	require.NoError(t, err)
+	md, err := os.ReadFile("testdata/testmd.md")
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		content string
+		want    string
+	}{
+		"html file":          {string(html), InputTypeHTML},
+		"doctype":            {"\n  <!doctype html><title>x</title>", InputTypeHTML},
+		"html tag with bom":  {"\xef\xbb\xbf<html lang=\"en\"><body>Hi</body></html>", InputTypeHTML},
+		"comment first":      {"<!-- generated -->\n<!DOCTYPE html>", InputTypeHTML},
+		"html fragment":      {"<div class=\"note\"><p>Short</p></div>", InputTypeHTML},
+		"markdown file":      {string(md), InputTypeMarkdown},
+		"heading":            {"# Title\n\nSome *text*.", InputTypeMarkdown},
+		"markdown with html": {"# Title\n\n<div>inline html</div>\n\nMore text here.", InputTypeMarkdown},
+		"tag with long text": {"<p>This paragraph has far more text than markup, it may be Markdown too.</p>", InputTypeMarkdown},
+		"no closing tag":     {"<br> plain text", InputTypeMarkdown},
+		"plain text":         {"just some words", InputTypeMarkdown},
+		"empty":              {"", InputTypeMarkdown},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectInputType([]byte(tt.content)))
+		})
+	}
+}
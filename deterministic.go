@@ -0,0 +1,62 @@
+package wkhtmltopdf
+
+import (
+	"crypto/sha256"
+	"os"
+	"strconv"
+	"time"
+)
+
+// SetDeterministic makes the PDF byte-identical for the same input and settings, for reproducible builds and
+// content hashes. wkhtmltopdf writes the time of the run as CreationDate of the PDF, so the PDF is post-processed
+// to set CreationDate and ModDate to a fixed time and to replace the file identifier (/ID), if there is one, with
+// a hash of the content. The time is taken from the SOURCE_DATE_EPOCH environment variable (seconds since 1970,
+// the convention for reproducible builds) if it is set, otherwise it is 1970-01-01 00:00:00 UTC; the generation
+// time written by SetProvenance is set to it as well. The PDF loses its real creation time.
+// This runs after all other post-processing, including the post-processors added with AddPostProcessor. The PDF
+// is still different for different versions of wkhtmltopdf or fonts, or when the pages change themselves, like
+// with JavaScript which shows the current date.
+func (pdfg *PDFGenerator) SetDeterministic(deterministic bool) {
+	pdfg.deterministic = deterministic
+}
+
+// deterministicTime returns the fixed time used by SetDeterministic
+func deterministicTime() time.Time {
+	if epoch := os.Getenv("SOURCE_DATE_EPOCH"); epoch != "" {
+		if sec, err := strconv.ParseInt(epoch, 10, 64); err == nil {
+			return time.Unix(sec, 0).UTC()
+		}
+	}
+	return time.Unix(0, 0).UTC()
+}
+
+// pdfDate returns t as PDF date string, like D:20240101120000Z
+func pdfDate(t time.Time) pdfString {
+	return pdfString(t.UTC().Format("D:20060102150405Z"))
+}
+
+// normalizeForReproducibility returns a post-processor which sets the dates in the Info dictionary to at and the
+// file identifier to a hash of the content, see SetDeterministic
+func normalizeForReproducibility(at time.Time) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		doc, err := parsePDF(pdf)
+		if err != nil {
+			return nil, err
+		}
+		info := doc.info()
+		info.Set("CreationDate", pdfDate(at))
+		if info.Get("ModDate") != nil {
+			info.Set("ModDate", pdfDate(at))
+		}
+		if doc.trailer.Get("ID") == nil {
+			return doc.bytes(), nil
+		}
+		// the identifier is the hash of the PDF with an empty identifier of the same length
+		placeholder := pdfHexString(make([]byte, 16))
+		doc.trailer.Set("ID", pdfArray{placeholder, placeholder})
+		sum := sha256.Sum256(doc.bytes())
+		id := pdfHexString(sum[:16])
+		doc.trailer.Set("ID", pdfArray{id, id})
+		return doc.bytes(), nil
+	}
+}
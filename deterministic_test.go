@@ -0,0 +1,101 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPDFAt returns a test PDF created at t, with a file identifier
+func newTestPDFAt(t *testing.T, at time.Time, id string) []byte {
+	pdf, err := modifyPDF(newTestPDF(2), func(doc *pdfDocument) error {
+		doc.info().Set("CreationDate", pdfDate(at))
+		doc.trailer.Set("ID", pdfArray{pdfHexString(id), pdfHexString(id)})
+		return nil
+	})
+	require.NoError(t, err)
+	return pdf
+}
+
+func TestSetDeterministic(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	// a fake wkhtmltopdf which writes the PDF of a different time and identifier on each run
+	dir := t.TempDir()
+	first := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.pdf"), newTestPDFAt(t, first, "run one identifier"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2.pdf"), newTestPDFAt(t, first.Add(time.Second), "run two identifier"), 0644))
+	script := "#!/bin/sh\ncat > /dev/null\ncd " + dir + "\necho x >> runs\ncat $(wc -l < runs | tr -d ' ').pdf\n"
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+
+	create := func(deterministic bool) []byte {
+		pdfg := NewPDFPreparer()
+		pdfg.binPath = bin
+		pdfg.SetDeterministic(deterministic)
+		pdfg.SetProvenance(true)
+		pdfg.AddPage(NewPageReader(strings.NewReader("<p>same input</p>")))
+		require.NoError(t, pdfg.CreateContext(context.Background()))
+		return pdfg.Bytes()
+	}
+
+	assert.NotEqual(t, create(false), create(false))
+	require.NoError(t, os.Remove(filepath.Join(dir, "runs")))
+	a, b := create(true), create(true)
+	assert.Equal(t, a, b)
+
+	doc, err := parsePDF(a)
+	require.NoError(t, err)
+	assert.Equal(t, pdfString("D:19700101000000Z"), doc.info().Get("CreationDate"))
+	assert.Equal(t, pdfString("1970-01-01T00:00:00Z"), doc.info().Get(ProvenanceGeneratedAtKey))
+	id, ok := doc.trailer.Get("ID").(pdfArray)
+	require.True(t, ok)
+	assert.Len(t, id[0], 16)
+	assert.NotEqual(t, pdfHexString("run one identifier"), id[0])
+}
+
+func TestNormalizeForReproducibility(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	at := deterministicTime()
+	assert.Equal(t, time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC), at)
+
+	pdf, err := modifyPDF(newTestPDF(1), func(doc *pdfDocument) error {
+		doc.info().Set("ModDate", pdfDate(time.Now()))
+		return nil
+	})
+	require.NoError(t, err)
+	pdf, err = normalizeForReproducibility(at)(pdf)
+	require.NoError(t, err)
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, pdfString("D:20231114221320Z"), doc.info().Get("CreationDate"))
+	assert.Equal(t, pdfString("D:20231114221320Z"), doc.info().Get("ModDate"))
+	assert.Nil(t, doc.trailer.Get("ID"))
+
+	// the identifier depends on the content
+	other, err := normalizeForReproducibility(at)(newTestPDFAt(t, at, "x"))
+	require.NoError(t, err)
+	changed, err := modifyPDF(newTestPDFAt(t, at, "x"), func(doc *pdfDocument) error {
+		doc.info().Set("Title", pdfString("Changed"))
+		return nil
+	})
+	require.NoError(t, err)
+	changed, err = normalizeForReproducibility(at)(changed)
+	require.NoError(t, err)
+	docA, err := parsePDF(other)
+	require.NoError(t, err)
+	docB, err := parsePDF(changed)
+	require.NoError(t, err)
+	assert.NotEqual(t, docA.trailer.Get("ID"), docB.trailer.Get("ID"))
+
+	t.Setenv("SOURCE_DATE_EPOCH", "invalid")
+	assert.Equal(t, time.Unix(0, 0).UTC(), deterministicTime())
+}
@@ -0,0 +1,89 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// SetForceOddStart makes the body (the pages added with AddPage) start on an odd (recto) page when printed
+// double-sided, by inserting a blank page after the cover and TOC if they have an odd number of pages.
+// wkhtmltopdf can't do this itself and the length of the TOC is only known after rendering, so the body is
+// rendered once more without the cover and TOC to count its pages, and the blank page is inserted by
+// post-processing the PDF. This costs an extra wkhtmltopdf run when a cover or TOC is used.
+func (pdfg *PDFGenerator) SetForceOddStart(force bool) {
+	pdfg.forceOddStart = force
+}
+
+// PadToOddPages returns a post-processor which inserts blank pages so each of the given pages starts on an odd
+// page, for documents printed double-sided. The pages are the numbers, counting from 1, in the PDF before
+// padding, like the first page of each chapter. A blank page has the size of the page before it.
+func PadToOddPages(starts ...int) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			starts := slices.Sorted(slices.Values(starts))
+			padded := make([]pdfRef, 0, len(pages)+len(starts))
+			next := 0
+			for i, page := range pages {
+				for next < len(starts) && starts[next] == i+1 {
+					if len(padded)%2 == 1 {
+						padded = append(padded, doc.newBlankPage(pages[i-1]))
+					}
+					next++
+				}
+				padded = append(padded, page)
+			}
+			if next < len(starts) {
+				return fmt.Errorf("page %d does not exist, the PDF has %d pages", starts[next], len(pages))
+			}
+			return doc.setPages(padded)
+		})
+	}
+}
+
+// padBodyToOdd returns a post-processor which makes the last bodyPages pages start on an odd page
+func padBodyToOdd(bodyPages int) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		doc, err := parsePDF(pdf)
+		if err != nil {
+			return nil, err
+		}
+		pages, err := doc.pages()
+		if err != nil {
+			return nil, err
+		}
+		start := len(pages) - bodyPages + 1
+		if start <= 1 {
+			return pdf, nil
+		}
+		return PadToOddPages(start)(pdf)
+	}
+}
+
+// countBodyPages renders the pages without cover and TOC to count them for SetForceOddStart, stdin is the
+// content of the page read from stdin. The returned function resets the count, it must always be called.
+func (pdfg *PDFGenerator) countBodyPages(ctx context.Context, stdin []byte) (func(), error) {
+	reset := func() { pdfg.bodyPages = 0 }
+	if !pdfg.forceOddStart || (pdfg.Cover.Input == "" && !pdfg.includeTOC()) || len(pdfg.pages) == 0 {
+		return reset, nil
+	}
+
+	args := pdfg.countArgs()
+	args = append(args, pdfg.outlineOptions.Args()...)
+	for i, page := range pdfg.pages {
+		args = append(args, "page", pdfg.pageInput(i))
+		args = append(args, page.Args()...)
+	}
+	args = append(args, "-")
+
+	pages, err := pdfg.renderPageCount(ctx, args, stdin)
+	if err != nil {
+		return reset, fmt.Errorf("error counting body pages: %w", err)
+	}
+	pdfg.bodyPages = pages
+	return reset, nil
+}
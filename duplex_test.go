@@ -0,0 +1,74 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPadToOddPages(t *testing.T) {
+	pdf, err := PadToOddPages(2)(newTestPDF(3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testPageContent(1), "", testPageContent(2), testPageContent(3)}, testPDFPageTexts(t, pdf))
+
+	// pages which already start on an odd page are not padded
+	pdf, err = PadToOddPages(1, 3)(newTestPDF(3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testPageContent(1), testPageContent(2), testPageContent(3)}, testPDFPageTexts(t, pdf))
+
+	// the padding of earlier pages is taken into account
+	pdf, err = PadToOddPages(3, 2)(newTestPDF(4))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testPageContent(1), "", testPageContent(2), "", testPageContent(3), testPageContent(4)}, testPDFPageTexts(t, pdf))
+
+	// blank pages have the size of the page before them
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	assert.Equal(t, doc.inheritedValue(doc.dict(pages[0]), "MediaBox"), doc.dict(pages[1]).Get("MediaBox"))
+
+	_, err = PadToOddPages(5)(newTestPDF(3))
+	assert.EqualError(t, err, "page 5 does not exist, the PDF has 3 pages")
+}
+
+func TestPadBodyToOdd(t *testing.T) {
+	// 2 pages before a body of 2 pages: no padding needed
+	pdf, err := padBodyToOdd(2)(newTestPDF(4))
+	require.NoError(t, err)
+	assert.Len(t, testPDFPageTexts(t, pdf), 4)
+
+	// 1 page before a body of 2 pages: a blank page is inserted
+	pdf, err = padBodyToOdd(2)(newTestPDF(3))
+	require.NoError(t, err)
+	assert.Equal(t, []string{testPageContent(1), "", testPageContent(2), testPageContent(3)}, testPDFPageTexts(t, pdf))
+
+	// nothing before the body
+	pdf, err = padBodyToOdd(3)(newTestPDF(3))
+	require.NoError(t, err)
+	assert.Len(t, testPDFPageTexts(t, pdf), 3)
+}
+
+func TestCountBodyPages(t *testing.T) {
+	t.Setenv("FAKE_PAGES", "3")
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.SetForceOddStart(true)
+
+	// without cover and TOC the body starts on the first page
+	reset, err := pdfg.countBodyPages(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 0, pdfg.bodyPages)
+	reset()
+
+	pdfg.TOC.Include = true
+	reset, err = pdfg.countBodyPages(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, pdfg.bodyPages)
+	assert.Len(t, pdfg.postProcessors(), 1)
+	reset()
+	assert.Equal(t, 0, pdfg.bodyPages)
+}
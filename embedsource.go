@@ -0,0 +1,202 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// sourcePageKey is the key in the file specification of an embedded source with the index of its page
+const sourcePageKey = pdfName("GopdfSourcePage")
+
+// pageSource is the source of a page embedded by EmbedSource
+type pageSource struct {
+	index   int // Index of the page, counting from 0 in the order the pages were added
+	name    string
+	subtype string // MIME type of the source
+	data    []byte
+}
+
+// EmbedSource enables embedding the source of each page in the PDF, so it can be recovered with ExtractSource,
+// like for a "re-edit this PDF" feature. The source is stored as an attached file (an embedded file of the
+// document, shown as attachment by viewers) named like gopdf-source-0001.md, compressed with FlateDecode:
+//   - the Markdown of a MarkdownPage as written, without expanding includes (or the executed template of
+//     NewMarkdownTemplatePage, or the HTML of NewMarkdownPageFromHTML without input path),
+//   - the AsciiDoc of an AsciiDocPage,
+//   - the HTML of a local file or a PageReader.
+//
+// Pages from URLs, the cover and the TOC are not embedded. The PDF grows by the compressed size of the sources,
+// typically a quarter to a third of their size for Markdown and HTML; images and other files referenced by the
+// pages are not embedded. It is disabled by default.
+func (pdfg *PDFGenerator) EmbedSource(embed bool) {
+	pdfg.embedSource = embed
+}
+
+// pageSources returns the sources of all pages which can be embedded, stdin is the content of the page read from
+// stdin
+func (pdfg *PDFGenerator) pageSources(stdin []byte) ([]pageSource, error) {
+	var sources []pageSource
+	for i, page := range pdfg.pages {
+		src := pageSource{index: i, subtype: "text/html"}
+		var err error
+		switch p := page.(type) {
+		case *MarkdownPage:
+			src.subtype = "text/markdown"
+			switch {
+			case p.source != nil:
+				src.data = p.source
+			case p.InputPath != "":
+				src.data, err = os.ReadFile(p.InputPath)
+			default:
+				src.subtype, src.data = "text/html", p.htmlCache
+			}
+		case *AsciiDocPage:
+			src.subtype = "text/asciidoc"
+			src.data, err = os.ReadFile(p.InputPath)
+		default:
+			input := pdfg.pageInput(i)
+			if input == "-" {
+				src.data = stdin
+			} else if path, ok := localPath(input); ok {
+				src.data, err = os.ReadFile(path)
+			} else {
+				continue
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading source of page %d: %w", i+1, err)
+		}
+		ext := map[string]string{"text/markdown": "md", "text/asciidoc": "adoc", "text/html": "html"}[src.subtype]
+		src.name = fmt.Sprintf("gopdf-source-%04d.%s", i+1, ext)
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// embedSources adds the sources as embedded files to the EmbeddedFiles name tree of the document
+func embedSources(sources []pageSource) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			names := doc.dict(cat.Get("Names"))
+			if names == nil {
+				names = newPDFDict()
+				cat.Set("Names", doc.add(names))
+			}
+
+			// existing embedded files are kept if they are in a flat name tree, which is what is written here
+			type entry struct {
+				name  string
+				value pdfObject
+			}
+			var entries []entry
+			if tree := doc.dict(names.Get("EmbeddedFiles")); tree != nil {
+				if tree.Get("Kids") != nil {
+					return errors.New("can't embed the source in a PDF with a nested tree of embedded files")
+				}
+				arr, _ := doc.resolve(tree.Get("Names")).(pdfArray)
+				for i := 0; i+1 < len(arr); i += 2 {
+					if name, ok := doc.resolve(arr[i]).(pdfString); ok {
+						entries = append(entries, entry{string(name), arr[i+1]})
+					}
+				}
+			}
+
+			for _, src := range sources {
+				params := newPDFDict()
+				params.Set("Size", pdfInt(len(src.data)))
+				stream := newPDFDict()
+				stream.Set("Type", pdfName("EmbeddedFile"))
+				stream.Set("Subtype", pdfName(src.subtype))
+				stream.Set("Params", params)
+				ef := newPDFDict()
+				ef.Set("F", doc.add(newFlateStream(stream, src.data)))
+
+				spec := newPDFDict()
+				spec.Set("Type", pdfName("Filespec"))
+				spec.Set("F", pdfString(src.name))
+				spec.Set("UF", pdfTextString(src.name))
+				spec.Set("Desc", pdfTextString(fmt.Sprintf("Source of page %d", src.index+1)))
+				spec.Set("AFRelationship", pdfName("Source"))
+				spec.Set(sourcePageKey, pdfInt(src.index))
+				spec.Set("EF", ef)
+				entries = append(entries, entry{src.name, doc.add(spec)})
+			}
+
+			// the names of a name tree must be sorted
+			sort.SliceStable(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+			var arr pdfArray
+			for _, e := range entries {
+				arr = append(arr, pdfString(e.name), e.value)
+			}
+			tree := newPDFDict()
+			tree.Set("Names", arr)
+			names.Set("EmbeddedFiles", doc.add(tree))
+			return nil
+		})
+	}
+}
+
+// ExtractSource returns the page sources embedded in a PDF created with EmbedSource, by page index (counting from
+// 0, in the order the pages were added to the generator). Pages without embedded source, like pages from URLs,
+// are left out. An empty map is returned for a PDF without embedded source, and an error if the PDF can't be
+// read.
+func ExtractSource(pdf []byte) (map[int][]byte, error) {
+	doc, err := parsePDF(pdf)
+	if err != nil {
+		return nil, err
+	}
+	cat, err := doc.catalog()
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[int][]byte)
+	names := doc.dict(cat.Get("Names"))
+	if names == nil {
+		return sources, nil
+	}
+
+	var walk func(node *pdfDict, depth int) error
+	walk = func(node *pdfDict, depth int) error {
+		if node == nil || depth > 32 {
+			return nil
+		}
+		kids, _ := doc.resolve(node.Get("Kids")).(pdfArray)
+		for _, kid := range kids {
+			if err := walk(doc.dict(kid), depth+1); err != nil {
+				return err
+			}
+		}
+		arr, _ := doc.resolve(node.Get("Names")).(pdfArray)
+		for i := 1; i < len(arr); i += 2 {
+			spec := doc.dict(arr[i])
+			if spec == nil {
+				continue
+			}
+			index, ok := doc.intValue(spec.Get(sourcePageKey))
+			ef := doc.dict(spec.Get("EF"))
+			if !ok || ef == nil {
+				continue
+			}
+			stream, ok := doc.resolve(ef.Get("F")).(*pdfStream)
+			if !ok {
+				continue
+			}
+			data, err := doc.decodeStream(stream)
+			if err != nil {
+				return fmt.Errorf("error reading source of page %d: %w", index+1, err)
+			}
+			sources[index] = bytes.Clone(data)
+		}
+		return nil
+	}
+	if err := walk(doc.dict(names.Get("EmbeddedFiles")), 0); err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
@@ -0,0 +1,75 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbedSource(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "out.pdf"), newTestPDF(4), 0644))
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\ncat > /dev/null\ncat "+filepath.Join(dir, "out.pdf")+"\n"), 0755))
+
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.EmbedSource(true)
+	pdfg.AddPage(NewMarkdownPage("testdata/titled.md"))
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPage(NewPage("https://example.com"))
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>from memory</p>")))
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+
+	markdown, err := os.ReadFile("testdata/titled.md")
+	require.NoError(t, err)
+	html, err := os.ReadFile("testdata/htmlsimple.html")
+	require.NoError(t, err)
+	sources, err := ExtractSource(pdfg.Bytes())
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]byte{0: markdown, 1: html, 3: []byte("<p>from memory</p>")}, sources)
+
+	// the sources are attached files, which viewers list by name
+	assert.Contains(t, pdfg.Buffer().String(), "(gopdf-source-0001.md)")
+	assert.Contains(t, pdfg.Buffer().String(), "/Subtype /text#2Fmarkdown")
+}
+
+func TestExtractSourceWithoutSource(t *testing.T) {
+	sources, err := ExtractSource(newTestPDF(1))
+	require.NoError(t, err)
+	assert.Empty(t, sources)
+
+	_, err = ExtractSource([]byte("not a PDF"))
+	assert.Error(t, err)
+}
+
+func TestEmbedSourcesKeepsAttachments(t *testing.T) {
+	pdf, err := embedSources([]pageSource{{index: 0, name: "b.html", subtype: "text/html", data: []byte("<p>b</p>")}})(newTestPDF(1))
+	require.NoError(t, err)
+	pdf, err = embedSources([]pageSource{{index: 1, name: "a.md", subtype: "text/markdown", data: []byte("# a")}})(pdf)
+	require.NoError(t, err)
+
+	sources, err := ExtractSource(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, map[int][]byte{0: []byte("<p>b</p>"), 1: []byte("# a")}, sources)
+
+	// names in the tree are sorted
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	tree := doc.dict(doc.dict(cat.Get("Names")).Get("EmbeddedFiles"))
+	names := doc.resolve(tree.Get("Names")).(pdfArray)
+	require.Len(t, names, 4)
+	assert.Equal(t, pdfString("a.md"), names[0])
+	assert.Equal(t, pdfString("b.html"), names[2])
+}
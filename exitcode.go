@@ -0,0 +1,30 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// ExitCodePolicy decides if a run of wkhtmltopdf succeeded, from its exit code, its stderr output and the PDF it
+// wrote to stdout. It returns nil for success and the error Create returns otherwise. output is empty when the PDF
+// is written to OutputFile, or to the writer set with SetOutput without post-processing.
+type ExitCodePolicy func(code int, stderr string, output []byte) error
+
+// SetExitCodePolicy sets the policy which decides which exit codes of wkhtmltopdf mean success, for builds which
+// use exit codes differently, like exiting with 1 after warnings about resources which failed to load. The policy
+// is called after every run in which wkhtmltopdf exited by itself, also with exit code 0, and for the extra runs to
+// count pages. It is not called when wkhtmltopdf could not be started, was killed because the context was done or
+// the output exceeded SetMaxOutputBytes. nil restores the default, which treats every exit code except 0 as error.
+func (pdfg *PDFGenerator) SetExitCodePolicy(policy ExitCodePolicy) {
+	pdfg.exitCodePolicy = policy
+}
+
+// applyExitCodePolicy returns the result of the exit code policy for a run of cmd which returned err. If there is
+// no policy or the process didn't exit by itself, err is returned and applied is false.
+func (pdfg *PDFGenerator) applyExitCodePolicy(cmd *exec.Cmd, err error, stderr string, output []byte) (policyErr error, applied bool) {
+	var exitErr *exec.ExitError
+	if pdfg.exitCodePolicy == nil || cmd.ProcessState == nil || !cmd.ProcessState.Exited() || (err != nil && !errors.As(err, &exitErr)) {
+		return err, false
+	}
+	return pdfg.exitCodePolicy(cmd.ProcessState.ExitCode(), stderr, output), true
+}
@@ -0,0 +1,95 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExitCommand returns the path of a fake wkhtmltopdf which writes a PDF, prints a warning and exits with code
+func newExitCommand(t *testing.T, code int) string {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	script := fmt.Sprintf("#!/bin/sh\necho 'Warning: Failed to load file:///missing.png (ignore)' >&2\nprintf '%%%%PDF-1.4\\n'\nexit %d\n", code)
+	path := filepath.Join(t.TempDir(), "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path
+}
+
+func TestSetExitCodePolicy(t *testing.T) {
+	newGenerator := func(code int) *PDFGenerator {
+		pdfg := NewPDFPreparer()
+		pdfg.binPath = newExitCommand(t, code)
+		pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+		return pdfg
+	}
+
+	// by default exit code 1 is an error
+	pdfg := newGenerator(1)
+	err := pdfg.Create()
+	assert.ErrorContains(t, err, "exit status 1")
+	assert.ErrorContains(t, err, "Warning: Failed to load")
+
+	// a build which exits with 1 after warnings
+	pdfg = newGenerator(1)
+	var gotStderr string
+	var gotOutput []byte
+	pdfg.SetExitCodePolicy(func(code int, stderr string, output []byte) error {
+		gotStderr, gotOutput = stderr, output
+		if code == 0 || code == 1 {
+			return nil
+		}
+		return fmt.Errorf("wkhtmltopdf failed with exit code %d", code)
+	})
+	require.NoError(t, pdfg.Create())
+	assert.Equal(t, "%PDF-1.4\n", string(pdfg.Bytes()))
+	assert.Equal(t, "%PDF-1.4\n", string(gotOutput))
+	assert.Contains(t, gotStderr, "Failed to load file:///missing.png")
+
+	// the policy can fail runs with exit code 0
+	errWarnings := errors.New("warnings are not allowed")
+	pdfg = newGenerator(0)
+	pdfg.SetExitCodePolicy(func(code int, stderr string, output []byte) error {
+		if strings.Contains(stderr, "Warning:") {
+			return errWarnings
+		}
+		return nil
+	})
+	result, err := pdfg.CreateWithResult(context.Background())
+	assert.ErrorIs(t, err, errWarnings)
+	assert.Equal(t, 0, result.ExitCode)
+
+	// nil restores the default
+	pdfg = newGenerator(2)
+	pdfg.SetExitCodePolicy(func(int, string, []byte) error { return nil })
+	pdfg.SetExitCodePolicy(nil)
+	assert.ErrorContains(t, pdfg.Create(), "exit status 2")
+}
+
+func TestSetExitCodePolicyOutputWriter(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = newExitCommand(t, 1)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	var buf bytes.Buffer
+	pdfg.SetOutput(&buf)
+	called := false
+	pdfg.SetExitCodePolicy(func(code int, stderr string, output []byte) error {
+		called = true
+		assert.Equal(t, 1, code)
+		assert.Empty(t, output) // streamed to the writer
+		return nil
+	})
+	require.NoError(t, pdfg.Create())
+	assert.True(t, called)
+	assert.Equal(t, "%PDF-1.4\n", buf.String())
+}
@@ -0,0 +1,100 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+)
+
+// Limits of the search for the zoom of SetFitToOnePage
+const (
+	fitMaxRuns      = 6    // Maximum number of wkhtmltopdf runs to find the zoom, before the PDF is rendered
+	fitMinZoom      = 0.1  // Smallest zoom tried, smaller content is not readable anyway
+	fitZoomAccuracy = 0.05 // The search stops when the zoom is known within this fraction
+)
+
+// SetFitToOnePage shrinks the content of the page to fit on one PDF page, for single-page summaries. wkhtmltopdf
+// can't fit content to a page, so this is a heuristic: the page is rendered by itself to count its PDF pages, and
+// if it has more than one, it is rendered again with a zoom reduced by the number of pages, followed by a search for
+// the largest zoom which still fits. This takes at most 6 extra wkhtmltopdf runs, or one if the page already fits,
+// before the PDF is rendered with the zoom found. The page is only shrunk, never enlarged, and the zoom is not
+// exact: the content may not fill the page, and content which doesn't fit at a zoom of 0.1 (or fits only at a
+// zoom between the ones tried) still spans several pages. The cover and the TOC are not included.
+// Create returns an error if there is not exactly one page. The Zoom of the page is the starting point and is
+// restored after Create.
+func (pdfg *PDFGenerator) SetFitToOnePage(fit bool) {
+	pdfg.fitToOnePage = fit
+}
+
+// fitZoom sets the zoom of the page for SetFitToOnePage, stdin is the content of the page read from stdin. The
+// returned function restores the zoom, it must always be called.
+func (pdfg *PDFGenerator) fitZoom(ctx context.Context, stdin []byte) (func(), error) {
+	restore := func() {}
+	if !pdfg.fitToOnePage {
+		return restore, nil
+	}
+	if len(pdfg.pages) != 1 {
+		return restore, fmt.Errorf("fitting to one page needs exactly one page, there are %d", len(pdfg.pages))
+	}
+
+	opts := pdfg.pages[0].Options()
+	zoom, wasSet := opts.Zoom.value, opts.Zoom.isSet
+	if !wasSet {
+		zoom = 1
+	}
+	count := func(z float64) (int, error) {
+		opts.Zoom.Set(z)
+		args := pdfg.countArgs()
+		args = append(args, "page", pdfg.pageInput(0))
+		args = append(args, opts.Args()...)
+		args = append(args, "-")
+		pages, err := pdfg.renderPageCount(ctx, args, stdin)
+		if err != nil {
+			return 0, fmt.Errorf("error counting pages to fit to one page: %w", err)
+		}
+		return pages, nil
+	}
+	restore = func() {
+		if wasSet {
+			opts.Zoom.Set(zoom)
+		} else {
+			opts.Zoom.Unset()
+		}
+	}
+
+	pages, err := count(zoom)
+	if err != nil || pages <= 1 {
+		restore()
+		return restore, err
+	}
+	// fits is the largest zoom which fits on one page, overflows the smallest which doesn't
+	fits, overflows := 0.0, zoom
+	next := max(zoom/float64(pages), fitMinZoom)
+	for run := 1; run < fitMaxRuns; run++ {
+		if pages, err = count(next); err != nil {
+			restore()
+			return restore, err
+		}
+		if pages <= 1 {
+			fits = next
+		} else {
+			overflows = next
+		}
+		if fits == 0 {
+			if next == fitMinZoom {
+				break
+			}
+			next = max(next/float64(pages), fitMinZoom)
+			continue
+		}
+		if overflows-fits < fitZoomAccuracy*overflows {
+			break
+		}
+		next = (fits + overflows) / 2
+	}
+	if fits > 0 {
+		opts.Zoom.Set(fits)
+	} else {
+		opts.Zoom.Set(overflows) // best effort, the content doesn't fit
+	}
+	return restore, nil
+}
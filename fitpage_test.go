@@ -0,0 +1,71 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newZoomBinary returns a fake wkhtmltopdf which writes 2 pages for a zoom above 0.6 (or without zoom) and 1 page
+// otherwise, and appends the zoom of each run to the returned log file
+func newZoomBinary(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.pdf"), newTestPDF(1), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2.pdf"), newTestPDF(2), 0644))
+	log := filepath.Join(dir, "zoom.log")
+	script := "#!/bin/sh\nzoom=1\nprev=\nfor a in \"$@\"; do\n\tif [ \"$prev\" = --zoom ]; then zoom=$a; fi\n\tprev=$a\ndone\n" +
+		"echo $zoom >> " + log + "\ncat > /dev/null\n" +
+		"if awk \"BEGIN { exit !($zoom > 0.6) }\"; then cat " + dir + "/2.pdf; else cat " + dir + "/1.pdf; fi\n"
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	return bin, log
+}
+
+func TestFitToOnePage(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	bin, log := newZoomBinary(t)
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.SetFitToOnePage(true)
+	page := NewPageReader(strings.NewReader("<p>two pages of content</p>"))
+	pdfg.AddPage(page)
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+	assert.Len(t, testPDFPageTexts(t, pdfg.Bytes()), 1)
+
+	// the zoom is halved for two pages, then the largest zoom which fits is searched
+	runs, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.000", "0.500", "0.750", "0.625", "0.562", "0.594", "0.594"}, strings.Fields(string(runs)))
+	assert.False(t, page.Zoom.isSet, "the zoom is restored")
+}
+
+func TestFitToOnePageAlreadyFits(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	bin, log := newZoomBinary(t)
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.SetFitToOnePage(true)
+	page := NewPage("testdata/htmlsimple.html")
+	page.Zoom.Set(0.5)
+	pdfg.AddPage(page)
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+
+	runs, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.500", "0.500"}, strings.Fields(string(runs)))
+	assert.Equal(t, 0.5, page.Zoom.value)
+
+	// only a single page can be fitted
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.EqualError(t, pdfg.CreateContext(context.Background()), "fitting to one page needs exactly one page, there are 2")
+}
@@ -0,0 +1,52 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// genericFontFamilies are the CSS generic font families, which are not quoted
+var genericFontFamilies = map[string]bool{
+	"serif": true, "sans-serif": true, "monospace": true, "cursive": true, "fantasy": true, "system-ui": true,
+}
+
+// SetFontFallback sets the fonts used for the text of pages added after this call, for documents which mix scripts
+// (like Latin and CJK) and show boxes for characters the default font doesn't have. The fonts are injected as CSS
+// font-family of the body, like SetInlineCSS: WebKit takes each character from the first font in the list which
+// has it, so list the main font first, followed by fonts for the other scripts. sans-serif is added at the end
+// unless the last font is a generic family. Elements with their own font-family in the document keep it.
+// The fonts must be installed on the system which runs wkhtmltopdf, or be loaded with @font-face in a style
+// sheet. An error is returned for empty names and names with quotes or CSS syntax, in which case nothing is
+// changed. An empty list removes the fallback for pages added afterwards.
+func (pdfg *PDFGenerator) SetFontFallback(fonts []string) error {
+	for _, font := range fonts {
+		if strings.TrimSpace(font) == "" {
+			return fmt.Errorf("invalid font name %q, it is empty", font)
+		}
+		if strings.ContainsAny(font, `"'\;{}<>`) {
+			return fmt.Errorf("invalid font name %q, it contains quotes or CSS syntax", font)
+		}
+	}
+	pdfg.fontFallback = append([]string(nil), fonts...)
+	return nil
+}
+
+// fontFallbackCSS returns the CSS which sets the font-family stack of fonts for the body
+func fontFallbackCSS(fonts []string) string {
+	if len(fonts) == 0 {
+		return ""
+	}
+	families := make([]string, 0, len(fonts)+1)
+	for _, font := range fonts {
+		font = strings.TrimSpace(font)
+		if genericFontFamilies[strings.ToLower(font)] {
+			families = append(families, strings.ToLower(font))
+		} else {
+			families = append(families, `"`+font+`"`)
+		}
+	}
+	if !genericFontFamilies[families[len(families)-1]] {
+		families = append(families, "sans-serif")
+	}
+	return "body { font-family: " + strings.Join(families, ", ") + "; }\n"
+}
@@ -0,0 +1,55 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFontFallback(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.SetFontFallback([]string{"Noto Sans", " Noto Sans CJK JP", "Noto Sans KR"}))
+	page := NewMarkdownPage("testdata/mixedscripts.md")
+	page.SetInlineCSS("h1 { font-family: serif; }")
+	pdfg.AddPage(page)
+
+	// only the page added after SetFontFallback gets the font stack, before its own inline CSS
+	assert.Empty(t, pdfg.pages[0].Options().injectedCSS())
+	r, err := stdinReader(page)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	html := string(b)
+	assert.Contains(t, html, `<style>body { font-family: "Noto Sans", "Noto Sans CJK JP", "Noto Sans KR", sans-serif; }`+"\nh1 { font-family: serif; }</style></head>")
+	assert.Contains(t, html, "今期の売上は12%増加しました。")
+	assert.Contains(t, html, "<td>서울</td>")
+
+	// a page from a file gets the font stack as user style sheet
+	require.NoError(t, pdfg.SetFontFallback([]string{"DejaVu Serif", "Serif"}))
+	file := NewPage("testdata/htmlsimple.html")
+	pdfg.AddPage(file)
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	defer cleanup()
+	css, err := os.ReadFile(file.UserStyleSheet.value)
+	require.NoError(t, err)
+	assert.Equal(t, `body { font-family: "DejaVu Serif", serif; }`+"\n", string(css))
+}
+
+func TestSetFontFallbackInvalid(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	require.NoError(t, pdfg.SetFontFallback([]string{"Noto Sans"}))
+	assert.EqualError(t, pdfg.SetFontFallback([]string{"Noto Sans", " "}), `invalid font name " ", it is empty`)
+	assert.EqualError(t, pdfg.SetFontFallback([]string{`Evil"; } body { color: red`}), `invalid font name "Evil\"; } body { color: red", it contains quotes or CSS syntax`)
+	assert.Equal(t, []string{"Noto Sans"}, pdfg.fontFallback)
+
+	require.NoError(t, pdfg.SetFontFallback(nil))
+	page := NewPage("testdata/htmlsimple.html")
+	pdfg.AddPage(page)
+	assert.Empty(t, page.injectedCSS())
+}
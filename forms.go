@@ -0,0 +1,14 @@
+package wkhtmltopdf
+
+// SetEnableForms turns the HTML form elements of pages added after this call into interactive PDF form fields
+// (--enable-forms), for fillable PDF forms generated from HTML templates. The fields are named after the name
+// attribute of the elements and start with their value. wkhtmltopdf (with the patched Qt) maps
+//   - <input type="text"> and other text inputs to text fields, <input type="password"> to password fields,
+//   - <textarea> to multi-line text fields,
+//   - <input type="checkbox"> to check boxes, checked if the checkbox is checked.
+//
+// Other elements, like radio buttons, <select>, buttons and file inputs, are printed as they look in the page but
+// are not interactive. The cover and the TOC are not changed.
+func (pdfg *PDFGenerator) SetEnableForms(enable bool) {
+	pdfg.enableForms = enable
+}
@@ -0,0 +1,53 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetEnableForms(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.SetEnableForms(true)
+	pdfg.AddPage(NewPage("testdata/form.html"))
+
+	// only the page added after SetEnableForms has form fields
+	assert.Equal(t, []string{"page", "testdata/htmlsimple.html", "page", "testdata/form.html", "--enable-forms", "-"}, pdfg.Args())
+
+	// the setting is restored from JSON and applied to pages added after loading
+	b, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	restored.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.Equal(t, "page testdata/htmlsimple.html page testdata/form.html --enable-forms page testdata/htmlsimple.html --enable-forms -", restored.ArgString())
+}
+
+func TestSetEnableFormsFields(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	if err != nil {
+		t.Skip("wkhtmltopdf not found")
+	}
+	if v, err := pdfg.DetectVersion(); err != nil || !v.PatchedQt {
+		t.Skip("form fields need wkhtmltopdf with the patched Qt")
+	}
+	pdfg.SetEnableForms(true)
+	pdfg.AddPage(NewPage("testdata/form.html"))
+	require.NoError(t, pdfg.Create())
+
+	// the text input is a text field named after the input
+	doc, err := parsePDF(pdfg.Bytes())
+	require.NoError(t, err)
+	var names []string
+	for _, obj := range doc.objects {
+		if field := doc.dict(obj); field != nil && field.Get("FT") == pdfName("Tx") {
+			if name, ok := doc.resolve(field.Get("T")).(pdfString); ok {
+				names = append(names, string(name))
+			}
+		}
+	}
+	assert.Contains(t, names, "applicant")
+}
@@ -0,0 +1,217 @@
+package wkhtmltopdf
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// textStyle is the typography of the text headers or footers
+type textStyle struct {
+	fontName string
+	fontSize uint
+}
+
+// apply sets the style on the font options, unless these are already set
+func (ts textStyle) apply(fontName *stringOption, fontSize *uintOption) {
+	if ts.fontName != "" && fontName.value == "" {
+		fontName.Set(ts.fontName)
+	}
+	if ts.fontSize > 0 && !fontSize.isSet {
+		fontSize.Set(ts.fontSize)
+	}
+}
+
+func newTextStyle(part, fontName string, size float64, color string) (textStyle, error) {
+	if size < 0 || size != math.Trunc(size) || size > math.MaxUint32 {
+		return textStyle{}, fmt.Errorf("invalid %s font size %v, must be a positive whole number", part, size)
+	}
+	if color != "" {
+		return textStyle{}, fmt.Errorf("%s color is not supported by wkhtmltopdf for text %ss, use an HTML %s with CSS instead", part, part, part)
+	}
+	return textStyle{fontName: fontName, fontSize: uint(size)}, nil
+}
+
+// SetFooterStyle sets the font name (--footer-font-name) and font size (--footer-font-size) of the text footers
+// (FooterLeft, FooterCenter and FooterRight) of the TOC and of all pages added after this call,
+// unless these have their own font name or size set. An empty fontName or a size of 0 leaves that option unchanged.
+// wkhtmltopdf only supports whole font sizes and has no option for the text color of text footers, so an error is
+// returned for a size with a fraction or any color; use SetFooterHTML with CSS for colored footers.
+// The style is stored in the page options, so it is included in ToJSON.
+func (pdfg *PDFGenerator) SetFooterStyle(fontName string, size float64, color string) error {
+	style, err := newTextStyle("footer", fontName, size, color)
+	if err != nil {
+		return err
+	}
+	pdfg.footerStyle = style
+	style.apply(&pdfg.TOC.FooterFontName, &pdfg.TOC.FooterFontSize)
+	return nil
+}
+
+// SetHeaderStyle is SetFooterStyle for the text headers (HeaderLeft, HeaderCenter and HeaderRight), using
+// --header-font-name and --header-font-size.
+func (pdfg *PDFGenerator) SetHeaderStyle(fontName string, size float64, color string) error {
+	style, err := newTextStyle("header", fontName, size, color)
+	if err != nil {
+		return err
+	}
+	pdfg.headerStyle = style
+	style.apply(&pdfg.TOC.HeaderFontName, &pdfg.TOC.HeaderFontSize)
+	return nil
+}
+
+// HeaderFooterToken is a part of a text header or footer, see HF.
+// The Token constants are replaced by wkhtmltopdf, other values are used as text.
+type HeaderFooterToken string
+
+// The tokens wkhtmltopdf replaces in text headers and footers
+const (
+	TokenPage       HeaderFooterToken = "[page]"       // Number of the current page
+	TokenFrompage   HeaderFooterToken = "[frompage]"   // Number of the first page
+	TokenTopage     HeaderFooterToken = "[topage]"     // Number of the last page
+	TokenWebpage    HeaderFooterToken = "[webpage]"    // URL of the page being printed
+	TokenSection    HeaderFooterToken = "[section]"    // Name of the current section (h1)
+	TokenSubsection HeaderFooterToken = "[subsection]" // Name of the current subsection (h2)
+	TokenDate       HeaderFooterToken = "[date]"       // Current date in the system local format
+	TokenIsodate    HeaderFooterToken = "[isodate]"    // Current date in ISO 8601 extended format
+	TokenTime       HeaderFooterToken = "[time]"       // Current time in the system local format
+	TokenTitle      HeaderFooterToken = "[title]"      // Title of the current page
+	TokenDoctitle   HeaderFooterToken = "[doctitle]"   // Title of the output document
+	TokenSitepage   HeaderFooterToken = "[sitepage]"   // Number of the page within the current input page
+	TokenSitepages  HeaderFooterToken = "[sitepages]"  // Number of pages of the current input page
+)
+
+// ReplaceToken returns the token for a replacement set with SetReplace or the Replace option
+func ReplaceToken(key string) HeaderFooterToken {
+	return HeaderFooterToken("[" + key + "]")
+}
+
+// Text returns a token for text taken from a string variable; string constants can be used directly.
+func Text(s string) HeaderFooterToken {
+	return HeaderFooterToken(s)
+}
+
+// HeaderFooterText is the left, center and right text of a header or footer, created with HF
+type HeaderFooterText struct {
+	Left   string
+	Center string
+	Right  string
+}
+
+// HeaderFooterBuilder composes a text header or footer from tokens, see HF
+type HeaderFooterBuilder struct {
+	text HeaderFooterText
+}
+
+// HF returns a builder for a text header or footer, to compose it from the Token constants instead of typing
+// the token names in strings, like:
+//
+//	page.SetFooterText(HF().Left(TokenTitle).Right("Page ", TokenPage, " / ", TokenTopage).Build())
+func HF() *HeaderFooterBuilder {
+	return &HeaderFooterBuilder{}
+}
+
+// Left sets the left aligned text to the concatenated parts
+func (b *HeaderFooterBuilder) Left(parts ...HeaderFooterToken) *HeaderFooterBuilder {
+	b.text.Left = joinTokens(parts)
+	return b
+}
+
+// Center sets the centered text to the concatenated parts
+func (b *HeaderFooterBuilder) Center(parts ...HeaderFooterToken) *HeaderFooterBuilder {
+	b.text.Center = joinTokens(parts)
+	return b
+}
+
+// Right sets the right aligned text to the concatenated parts
+func (b *HeaderFooterBuilder) Right(parts ...HeaderFooterToken) *HeaderFooterBuilder {
+	b.text.Right = joinTokens(parts)
+	return b
+}
+
+// Build returns the composed text
+func (b *HeaderFooterBuilder) Build() HeaderFooterText {
+	return b.text
+}
+
+func joinTokens(parts []HeaderFooterToken) string {
+	var s strings.Builder
+	for _, part := range parts {
+		s.WriteString(string(part))
+	}
+	return s.String()
+}
+
+// SetHeaderText sets HeaderLeft, HeaderCenter and HeaderRight from text, parts which are empty are not changed
+func (hopt *headerAndFooterOptions) SetHeaderText(text HeaderFooterText) {
+	setText(&hopt.HeaderLeft, text.Left)
+	setText(&hopt.HeaderCenter, text.Center)
+	setText(&hopt.HeaderRight, text.Right)
+}
+
+// SetFooterText sets FooterLeft, FooterCenter and FooterRight from text, parts which are empty are not changed
+func (hopt *headerAndFooterOptions) SetFooterText(text HeaderFooterText) {
+	setText(&hopt.FooterLeft, text.Left)
+	setText(&hopt.FooterCenter, text.Center)
+	setText(&hopt.FooterRight, text.Right)
+}
+
+func setText(option *stringOption, text string) {
+	if text != "" {
+		option.Set(text)
+	}
+}
+
+// logoTypes are the media types of the image files SetHeaderLogo accepts, by extension
+var logoTypes = map[string]string{
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".gif":  "image/gif",
+	".svg":  "image/svg+xml",
+}
+
+// headerLogo is an image set with SetHeaderLogo
+type headerLogo struct {
+	path     string
+	position string
+}
+
+// SetHeaderLogo shows the image at imagePath in the header of all pages, aligned "left", "center" or "right",
+// without writing header HTML. When the PDF is created, the image is embedded as a data URL in a minimal header HTML
+// file, so wkhtmltopdf doesn't need access to local files, and the file is used as --header-html for all pages
+// without their own header HTML (including one set with SetHeaderHTML). The temporary file is removed after Create.
+// The logo is at most 12mm high; set a top margin which leaves room for it, like 20mm.
+// PNG, JPEG, GIF and SVG images are supported. An error is returned for other file types and positions, a missing
+// image is reported by Validate. An empty imagePath removes the logo.
+func (pdfg *PDFGenerator) SetHeaderLogo(imagePath string, position string) error {
+	if imagePath == "" {
+		pdfg.headerLogo = nil
+		return nil
+	}
+	if _, ok := logoTypes[strings.ToLower(filepath.Ext(imagePath))]; !ok {
+		return fmt.Errorf("unsupported header logo %s, use a PNG, JPEG, GIF or SVG image", imagePath)
+	}
+	switch position {
+	case "left", "center", "right":
+	default:
+		return fmt.Errorf("invalid header logo position %q, use left, center or right", position)
+	}
+	pdfg.headerLogo = &headerLogo{path: imagePath, position: position}
+	return nil
+}
+
+// html returns the header HTML which shows the logo
+func (hl *headerLogo) html() ([]byte, error) {
+	image, err := os.ReadFile(hl.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading header logo: %w", err)
+	}
+	src := "data:" + logoTypes[strings.ToLower(filepath.Ext(hl.path))] + ";base64," + base64.StdEncoding.EncodeToString(image)
+	return []byte(`<!DOCTYPE html><html><head><meta charset="utf-8"><style>` +
+		`body{margin:0;text-align:` + hl.position + `}img{max-height:12mm}</style></head>` +
+		`<body><img src="` + src + `" alt=""></body></html>`), nil
+}
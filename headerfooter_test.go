@@ -0,0 +1,117 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFooterAndHeaderStyle(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	require.NoError(t, pdfg.SetFooterStyle("Times", 9, ""))
+	require.NoError(t, pdfg.SetHeaderStyle("", 14, ""))
+
+	page1 := NewPage("https://www.google.com")
+	page2 := NewPage("https://www.github.com")
+	page2.FooterFontSize.Set(12)
+	pdfg.AddPage(page1)
+	pdfg.AddPage(page2)
+
+	want := "page https://www.google.com --footer-font-name Times --footer-font-size 9 --header-font-size 14 " +
+		"page https://www.github.com --footer-font-name Times --footer-font-size 12 --header-font-size 14 -"
+	assert.Equal(t, want, pdfg.ArgString())
+
+	// the style is stored with the pages and survives a JSON round-trip
+	jb, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	SetPath("/path/to/wkhtmltopdf")
+	defer SetPath("")
+	pdfgFromJSON, err := NewPDFGeneratorFromJSON(bytes.NewReader(jb))
+	require.NoError(t, err)
+	assert.Equal(t, want, pdfgFromJSON.ArgString())
+}
+
+func TestSetFooterStyleErrors(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.SetFooterStyle("Arial", -1, ""), "invalid footer font size -1, must be a positive whole number")
+	assert.EqualError(t, pdfg.SetHeaderStyle("Arial", 10.5, ""), "invalid header font size 10.5, must be a positive whole number")
+	assert.Error(t, pdfg.SetFooterStyle("Arial", 10, "#ff0000"))
+
+	// nothing is applied after an error
+	pdfg.AddPage(NewPage("https://www.google.com"))
+	assert.Equal(t, "page https://www.google.com -", pdfg.ArgString())
+}
+
+func TestHeaderFooterBuilder(t *testing.T) {
+	text := HF().Left(TokenTitle).Center(ReplaceToken("author")).Right("Page ", TokenPage, " / ", TokenTopage).Build()
+	assert.Equal(t, HeaderFooterText{Left: "[title]", Center: "[author]", Right: "Page [page] / [topage]"}, text)
+
+	prefix := "Printed "
+	assert.Equal(t, HeaderFooterText{Left: "Printed [isodate]"}, HF().Left(Text(prefix), TokenIsodate).Build())
+
+	page := NewPage("testdata/htmlsimple.html")
+	page.FooterCenter.Set("keep")
+	page.SetFooterText(HF().Left(TokenSection).Right(TokenPage).Build())
+	page.SetHeaderText(HF().Center(TokenDoctitle).Build())
+	args := strings.Join(page.Args(), " ")
+	assert.Contains(t, args, "--footer-left [section]")
+	assert.Contains(t, args, "--footer-center keep")
+	assert.Contains(t, args, "--footer-right [page]")
+	assert.Contains(t, args, "--header-center [doctitle]")
+	assert.NotContains(t, args, "--header-left")
+
+	pdfg := NewPDFPreparer()
+	pdfg.TOC.Include = true
+	pdfg.TOC.SetFooterText(HF().Center(TokenPage).Build())
+	assert.Contains(t, pdfg.ArgString(), "--footer-center [page]")
+}
+
+func TestSetHeaderLogo(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.SetHeaderLogo("testdata/logo.bmp", "left"), "unsupported header logo testdata/logo.bmp, use a PNG, JPEG, GIF or SVG image")
+	assert.EqualError(t, pdfg.SetHeaderLogo("testdata/logo.png", "top"), `invalid header logo position "top", use left, center or right`)
+	require.NoError(t, pdfg.SetHeaderLogo("testdata/logo.png", "right"))
+
+	page1 := NewPage("testdata/htmlsimple.html")
+	page2 := NewPage("testdata/htmlsimple.html")
+	page2.HeaderHTML.Set("testdata/footer.html")
+	pdfg.AddPage(page1)
+	pdfg.AddPage(page2)
+	require.NoError(t, pdfg.Validate())
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	headerPath := page1.HeaderHTML.value
+	assert.Contains(t, pdfg.ArgString(), "--header-html "+headerPath)
+	header, err := os.ReadFile(headerPath)
+	require.NoError(t, err)
+	logo, err := os.ReadFile("testdata/logo.png")
+	require.NoError(t, err)
+	assert.Contains(t, string(header), `<img src="data:image/png;base64,`+base64.StdEncoding.EncodeToString(logo)+`"`)
+	assert.Contains(t, string(header), "text-align:right")
+	// a page with its own header keeps it
+	assert.Equal(t, "testdata/footer.html", page2.HeaderHTML.value)
+
+	cleanup()
+	assert.Equal(t, "", page1.HeaderHTML.value)
+	_, err = os.Stat(headerPath)
+	assert.True(t, os.IsNotExist(err))
+
+	// the logo is embedded, so it is not an external resource
+	urls, err := pdfg.ListExternalResources()
+	require.NoError(t, err)
+	assert.Empty(t, urls)
+
+	// a missing image is reported by Validate
+	require.NoError(t, pdfg.SetHeaderLogo("testdata/missing.svg", "center"))
+	assert.ErrorContains(t, pdfg.Validate(), "header logo: testdata/missing.svg")
+
+	require.NoError(t, pdfg.SetHeaderLogo("", ""))
+	assert.Nil(t, pdfg.headerLogo)
+}
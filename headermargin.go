@@ -0,0 +1,81 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+)
+
+// Heights of headers and footers assumed by Lint, see SetHeaderFooterMinHeight
+const (
+	textHeaderLines  = 1.5 // Height of a text header or footer as a multiple of its font size
+	htmlHeaderHeight = 10  // Height of an HTML header or footer in mm
+	logoHeaderHeight = 12  // Height of the header with a logo in mm, see SetHeaderLogo
+)
+
+// SetHeaderFooterMinHeight sets the height in mm which Lint assumes for headers and footers when it checks that
+// the top and bottom margins leave room for them. wkhtmltopdf draws headers and footers in the margins, so if a
+// margin is smaller than the header or footer and its spacing (see HeaderSpacing and FooterSpacing), they
+// overlap the content. By default Lint assumes 1.5 times the font size for text headers and footers (about
+// 6.4mm for 12pt), 10mm for HTML headers and footers and 12mm for the header with a logo (see SetHeaderLogo).
+// 0 restores these defaults, a negative height turns the check off.
+func (pdfg *PDFGenerator) SetHeaderFooterMinHeight(mm float64) {
+	pdfg.hfMinHeight = mm
+}
+
+// lintHeaderFooterMargins returns a warning for each header and footer of the pages and the TOC which doesn't
+// fit in the top or bottom margin
+func (pdfg *PDFGenerator) lintHeaderFooterMargins() []LintIssue {
+	if pdfg.hfMinHeight < 0 {
+		return nil
+	}
+	top := marginMillimeters(pdfg.MarginTopUnit, pdfg.MarginTop)
+	bottom := marginMillimeters(pdfg.MarginBottomUnit, pdfg.MarginBottom)
+
+	var issues []LintIssue
+	check := func(location string, opts *headerAndFooterOptions, logo bool) {
+		header := pdfg.headerHeight(opts.HeaderHTML, opts.HeaderFontSize, opts.HeaderLeft, opts.HeaderCenter, opts.HeaderRight, logo)
+		footer := pdfg.headerHeight(opts.FooterHTML, opts.FooterFontSize, opts.FooterLeft, opts.FooterCenter, opts.FooterRight, false)
+		if header > 0 {
+			if need := header + opts.HeaderSpacing.value; top < need {
+				issues = append(issues, LintIssue{Severity: LintWarning, Location: location + " header", Message: fmt.Sprintf(
+					"the top margin of %gmm is smaller than the %.1fmm needed for the header and its spacing, the header overlaps the content", top, need)})
+			}
+		}
+		if footer > 0 {
+			if need := footer + opts.FooterSpacing.value; bottom < need {
+				issues = append(issues, LintIssue{Severity: LintWarning, Location: location + " footer", Message: fmt.Sprintf(
+					"the bottom margin of %gmm is smaller than the %.1fmm needed for the footer and its spacing, the footer overlaps the content", bottom, need)})
+			}
+		}
+	}
+	if pdfg.TOC.Include {
+		check("toc", &pdfg.TOC.headerAndFooterOptions, false) // the header logo is only used for the pages
+	}
+	for i, page := range pdfg.pages {
+		check(fmt.Sprintf("page %d", i+1), &page.Options().headerAndFooterOptions, pdfg.headerLogo != nil)
+	}
+	return issues
+}
+
+// headerHeight returns the height in mm assumed for a header or footer with these options, or 0 if there is
+// none. logo is true if the header logo is used when there is no header HTML.
+func (pdfg *PDFGenerator) headerHeight(html stringOption, fontSize uintOption, left, center, right stringOption, logo bool) float64 {
+	isHTML := html.value != ""
+	isText := left.value != "" || center.value != "" || right.value != ""
+	if !isHTML && !isText && !logo {
+		return 0
+	}
+	if pdfg.hfMinHeight > 0 {
+		return pdfg.hfMinHeight
+	}
+	switch {
+	case isHTML:
+		return htmlHeaderHeight
+	case logo:
+		return logoHeaderHeight
+	}
+	size := uint(12)
+	if fontSize.isSet {
+		size = fontSize.value
+	}
+	return Length{Value: float64(size), Unit: Point}.Millimeters() * textHeaderLines
+}
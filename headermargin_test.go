@@ -0,0 +1,66 @@
+package wkhtmltopdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintHeaderFooterMargins(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.MarginTop.Set(5)
+	pdfg.MarginBottomUnit.Set("0.5in")
+	page := NewPageReader(strings.NewReader("<p>text</p>"))
+	page.HeaderCenter.Set("[title]")
+	page.HeaderSpacing.Set(2)
+	page.FooterHTML.Set("testdata/htmlsimple.html")
+	page.FooterSpacing.Set(5)
+	pdfg.AddPage(page)
+	fits := NewPageReader(strings.NewReader("<p>text</p>"))
+	fits.HeaderFontSize.Set(6)
+	fits.HeaderRight.Set("[page]")
+	pdfg.AddPage(fits)
+
+	// 1.5 lines of 12pt text are 6.35mm high, 8.3mm with the spacing; the HTML footer is 10mm with 5mm spacing
+	want := []LintIssue{
+		{Severity: LintWarning, Location: "page 1 header",
+			Message: "the top margin of 5mm is smaller than the 8.3mm needed for the header and its spacing, the header overlaps the content"},
+		{Severity: LintWarning, Location: "page 1 footer",
+			Message: "the bottom margin of 12.7mm is smaller than the 15.0mm needed for the footer and its spacing, the footer overlaps the content"},
+	}
+	assert.Equal(t, want, pdfg.Lint())
+
+	// the threshold can be lowered or the check turned off
+	pdfg.SetHeaderFooterMinHeight(2)
+	assert.Nil(t, pdfg.Lint())
+	pdfg.SetHeaderFooterMinHeight(-1)
+	pdfg.MarginTop.Set(0)
+	assert.Nil(t, pdfg.Lint())
+
+	// the defaults are restored with 0, the TOC is checked too
+	pdfg.SetHeaderFooterMinHeight(0)
+	pdfg.MarginTop.Set(20)
+	pdfg.MarginBottom.Set(20)
+	pdfg.MarginBottomUnit.Unset()
+	pdfg.TOC.Include = true
+	pdfg.TOC.HeaderHTML.Set("testdata/htmlsimple.html")
+	pdfg.TOC.HeaderSpacing.Set(15)
+	issues := pdfg.Lint()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "toc header", issues[0].Location)
+}
+
+func TestLintHeaderLogoMargin(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	require.NoError(t, pdfg.SetHeaderLogo("testdata/logo.png", "left"))
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>text</p>")))
+	issues := pdfg.Lint()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "page 1 header", issues[0].Location)
+	assert.Contains(t, issues[0].Message, "12.0mm needed")
+
+	pdfg.MarginTop.Set(20)
+	assert.Nil(t, pdfg.Lint())
+}
@@ -0,0 +1,88 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RenderHTMLOnly returns the HTML of each page as it would be passed to wkhtmltopdf, by page index (counting from
+// 0, in the order the pages were added), without running wkhtmltopdf, like for a HTML preview or for debugging.
+// This is the HTML after Markdown and AsciiDoc conversion, with the inline CSS injected in pages from memory and
+// the resources removed which are not allowed (see SetResourceAllowlist). Style sheets passed as
+// --user-style-sheet are applied by wkhtmltopdf and not part of the HTML. Pages loaded from URLs are left out.
+// The wkhtmltopdf executable is not needed. PageReader pages can still be rendered afterwards, their content is
+// kept in memory.
+func (pdfg *PDFGenerator) RenderHTMLOnly() (map[int][]byte, error) {
+	// the content of PageReader pages is read more than once, when it is spilled to a file and to get the HTML
+	for i, page := range pdfg.pages {
+		if pr, ok := page.(*PageReader); ok && pr.Input != nil {
+			content, err := io.ReadAll(pr.Input)
+			if err != nil {
+				return nil, fmt.Errorf("error reading page %d: %w", i+1, err)
+			}
+			pr.Input = bytes.NewReader(content)
+			defer func() { pr.Input = bytes.NewReader(content) }()
+		}
+	}
+
+	cleanup, err := pdfg.prepare(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	pages := make(map[int][]byte, len(pdfg.pages))
+	for i := range pdfg.pages {
+		html, err := pdfg.pageHTML(i)
+		if err != nil {
+			return nil, err
+		}
+		if html != nil {
+			pages[i] = html
+		}
+	}
+	return pages, nil
+}
+
+// pageHTML returns the HTML of page i as it is passed to wkhtmltopdf, or nil if it can't be read, like for URLs.
+// During a run, after prepare, the temporary files of spilled and filtered pages are read. A PageReader is
+// buffered, so it can still be read afterwards.
+func (pdfg *PDFGenerator) pageHTML(i int) ([]byte, error) {
+	page := pdfg.pages[i]
+	if _, ok := pdfg.spilled[i]; ok || page.Reader() == nil {
+		path, ok := localPath(pdfg.pageInput(i))
+		if !ok {
+			return nil, nil
+		}
+		html, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading page %d: %w", i+1, err)
+		}
+		return html, nil
+	}
+
+	pr, isReader := page.(*PageReader)
+	var content []byte
+	if isReader {
+		var err error
+		if content, err = io.ReadAll(pr.Input); err != nil {
+			return nil, fmt.Errorf("error reading page %d: %w", i+1, err)
+		}
+		pr.Input = bytes.NewReader(content)
+	}
+	r, err := stdinReader(page)
+	if err != nil {
+		return nil, fmt.Errorf("error reading page %d: %w", i+1, err)
+	}
+	html, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading page %d: %w", i+1, err)
+	}
+	if isReader {
+		pr.Input = bytes.NewReader(content)
+	}
+	return html, nil
+}
@@ -0,0 +1,40 @@
+package wkhtmltopdf
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderHTMLOnly(t *testing.T) {
+	pdfg := NewPDFPreparer() // wkhtmltopdf is not needed
+	md := NewMarkdownPage("testdata/testmd.md")
+	md.SetInlineCSS("h1 { color: red; }")
+	pdfg.AddPage(md)
+	reader := NewPageReader(strings.NewReader(`<html><head><script src="https://evil.example.net/a.js"></script></head><body>Reader</body></html>`))
+	reader.SetResourceAllowlist([]string{})
+	pdfg.AddPage(reader)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPage(NewPage("https://example.com"))
+
+	pages, err := pdfg.RenderHTMLOnly()
+	require.NoError(t, err)
+	require.Len(t, pages, 3)
+	assert.Contains(t, string(pages[0]), "<style>h1 { color: red; }</style></head>")
+	assert.Contains(t, string(pages[0]), "<h1")
+	assert.Equal(t, `<html><head></head><body>Reader</body></html>`, string(pages[1]))
+	simple, err := os.ReadFile("testdata/htmlsimple.html")
+	require.NoError(t, err)
+	assert.Equal(t, simple, pages[2])
+
+	// the reader page still has its content, and temporary files are removed
+	b, err := io.ReadAll(reader.Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "evil.example.net")
+	assert.Empty(t, pdfg.tempFiles)
+	assert.Nil(t, pdfg.spilled)
+}
@@ -0,0 +1,34 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strconv"
+)
+
+// WritePDFResponse writes the PDF in the internal buffer as response of an HTTP handler, after Create has been
+// called. It sets the Content-Type to application/pdf, the Content-Length, and the Content-Disposition to
+// attachment with filename, so browsers download the PDF under that name; the name is encoded for non-ASCII
+// characters and only its base name is used. An empty filename sets the Content-Disposition to inline, so
+// browsers show the PDF. An error is returned, before anything is written, if there is no PDF in the internal
+// buffer, like when Create failed or the PDF was written to OutputFile or with SetOutput. Errors writing the
+// response, like a closed connection, are returned as well, then the headers have already been sent.
+func (pdfg *PDFGenerator) WritePDFResponse(w http.ResponseWriter, filename string) error {
+	if pdfg.outbuf.Len() == 0 {
+		return errNoPDF
+	}
+	disposition := "inline"
+	if filename != "" {
+		disposition = mime.FormatMediaType("attachment", map[string]string{"filename": filepath.Base(filename)})
+	}
+	header := w.Header()
+	header.Set("Content-Type", "application/pdf")
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Length", strconv.Itoa(pdfg.outbuf.Len()))
+	if _, err := w.Write(pdfg.outbuf.Bytes()); err != nil {
+		return fmt.Errorf("error writing PDF response: %w", err)
+	}
+	return nil
+}
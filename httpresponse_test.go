@@ -0,0 +1,34 @@
+package wkhtmltopdf
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePDFResponse(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	rec := httptest.NewRecorder()
+	assert.Equal(t, errNoPDF, pdfg.WritePDFResponse(rec, "report.pdf"))
+	assert.Empty(t, rec.Header())
+	assert.Zero(t, rec.Body.Len())
+
+	pdf := newTestPDF(1)
+	pdfg.outbuf.Write(pdf)
+	require.NoError(t, pdfg.WritePDFResponse(rec, "reports/report.pdf"))
+	assert.Equal(t, "application/pdf", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename=report.pdf", rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, strconv.Itoa(len(pdf)), rec.Header().Get("Content-Length"))
+	assert.Equal(t, pdf, rec.Body.Bytes())
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, pdfg.WritePDFResponse(rec, "Übersicht 2024.pdf"))
+	assert.Equal(t, "attachment; filename*=utf-8''%C3%9Cbersicht%202024.pdf", rec.Header().Get("Content-Disposition"))
+
+	rec = httptest.NewRecorder()
+	require.NoError(t, pdfg.WritePDFResponse(rec, ""))
+	assert.Equal(t, "inline", rec.Header().Get("Content-Disposition"))
+}
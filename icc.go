@@ -0,0 +1,85 @@
+package wkhtmltopdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// outputIntent is an ICC profile set with SetOutputIntent
+type outputIntent struct {
+	profile    []byte
+	components int
+	identifier string
+}
+
+// SetOutputIntent embeds the ICC color profile at iccPath in the generated PDF as output intent, which is
+// required by many print vendors and for PDF/A and PDF/X. identifier is the name of the output condition,
+// like "sRGB IEC61966-2.1" or "FOGRA39". The profile is added to the /OutputIntents of the document catalog
+// for both PDF/X (GTS_PDFX) and PDF/A (GTS_PDFA1), sharing the same profile.
+// An error is returned if the file can not be read or is not an RGB, CMYK or gray ICC profile.
+func (pdfg *PDFGenerator) SetOutputIntent(iccPath string, identifier string) error {
+	profile, err := os.ReadFile(iccPath)
+	if err != nil {
+		return fmt.Errorf("error reading ICC profile: %w", err)
+	}
+	components, err := iccComponents(profile)
+	if err != nil {
+		return fmt.Errorf("invalid ICC profile %s: %w", iccPath, err)
+	}
+	pdfg.outputIntent = &outputIntent{
+		profile:    profile,
+		components: components,
+		identifier: identifier,
+	}
+	return nil
+}
+
+// iccComponents checks the header of an ICC profile and returns the number of color components of its color space
+func iccComponents(profile []byte) (int, error) {
+	if len(profile) < 128 || string(profile[36:40]) != "acsp" {
+		return 0, errors.New("not an ICC profile")
+	}
+	if size := binary.BigEndian.Uint32(profile[0:4]); int(size) != len(profile) {
+		return 0, fmt.Errorf("profile size %d does not match file size %d", size, len(profile))
+	}
+	switch string(profile[16:20]) {
+	case "GRAY":
+		return 1, nil
+	case "RGB ":
+		return 3, nil
+	case "CMYK":
+		return 4, nil
+	}
+	return 0, fmt.Errorf("unsupported color space %q", profile[16:20])
+}
+
+// setOutputIntent adds the output intent to the document catalog
+func setOutputIntent(oi *outputIntent) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			dict := newPDFDict()
+			dict.Set("N", pdfInt(oi.components))
+			dict.Set("Alternate", pdfName(map[int]string{1: "DeviceGray", 3: "DeviceRGB", 4: "DeviceCMYK"}[oi.components]))
+			profileRef := doc.add(newFlateStream(dict, oi.profile))
+
+			var intents pdfArray
+			for _, subtype := range []pdfName{"GTS_PDFX", "GTS_PDFA1"} {
+				intent := newPDFDict()
+				intent.Set("Type", pdfName("OutputIntent"))
+				intent.Set("S", subtype)
+				intent.Set("OutputConditionIdentifier", pdfString(oi.identifier))
+				intent.Set("Info", pdfString(oi.identifier))
+				intent.Set("DestOutputProfile", profileRef)
+				intents = append(intents, intent)
+			}
+			cat.Set("OutputIntents", intents)
+			return nil
+		})
+	}
+}
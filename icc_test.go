@@ -0,0 +1,72 @@
+package wkhtmltopdf
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestICCProfile returns a minimal ICC profile header for the given color space
+func newTestICCProfile(colorSpace string) []byte {
+	profile := make([]byte, 132)
+	binary.BigEndian.PutUint32(profile[0:4], uint32(len(profile)))
+	copy(profile[12:16], "mntr")
+	copy(profile[16:20], colorSpace)
+	copy(profile[20:24], "XYZ ")
+	copy(profile[36:40], "acsp")
+	return profile
+}
+
+func TestICCComponents(t *testing.T) {
+	for colorSpace, want := range map[string]int{"GRAY": 1, "RGB ": 3, "CMYK": 4} {
+		n, err := iccComponents(newTestICCProfile(colorSpace))
+		require.NoError(t, err)
+		assert.Equal(t, want, n)
+	}
+
+	_, err := iccComponents(newTestICCProfile("Lab "))
+	assert.EqualError(t, err, `unsupported color space "Lab "`)
+	_, err = iccComponents([]byte("not a profile"))
+	assert.EqualError(t, err, "not an ICC profile")
+	_, err = iccComponents(append(newTestICCProfile("RGB "), 0))
+	assert.EqualError(t, err, "profile size 132 does not match file size 133")
+}
+
+func TestSetOutputIntent(t *testing.T) {
+	dir := t.TempDir()
+	iccPath := filepath.Join(dir, "test.icc")
+	require.NoError(t, os.WriteFile(iccPath, newTestICCProfile("CMYK"), 0644))
+
+	pdfg := NewPDFPreparer()
+	assert.Error(t, pdfg.SetOutputIntent(filepath.Join(dir, "missing.icc"), "FOGRA39"))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "invalid.icc"), []byte("invalid"), 0644))
+	assert.Error(t, pdfg.SetOutputIntent(filepath.Join(dir, "invalid.icc"), "FOGRA39"))
+	assert.Nil(t, pdfg.outputIntent)
+
+	require.NoError(t, pdfg.SetOutputIntent(iccPath, "FOGRA39"))
+	pdfg.outbuf.Write(newTestPDF(1))
+	require.NoError(t, pdfg.postProcess(pdfg.postProcessors(), nil))
+
+	doc, err := parsePDF(pdfg.Bytes())
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	intents, ok := cat.Get("OutputIntents").(pdfArray)
+	require.True(t, ok)
+	require.Len(t, intents, 2)
+
+	intent := doc.dict(intents[1])
+	assert.Equal(t, pdfName("GTS_PDFA1"), intent.Get("S"))
+	assert.Equal(t, pdfString("FOGRA39"), intent.Get("OutputConditionIdentifier"))
+	profile, ok := doc.resolve(intent.Get("DestOutputProfile")).(*pdfStream)
+	require.True(t, ok)
+	assert.Equal(t, pdfNumber("4"), profile.dict.Get("N"))
+	data, err := doc.decodeStream(profile)
+	require.NoError(t, err)
+	assert.Equal(t, newTestICCProfile("CMYK"), data)
+	assert.Equal(t, intent.Get("DestOutputProfile"), doc.dict(intents[0]).Get("DestOutputProfile"))
+}
@@ -0,0 +1,66 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// MaxImageDPI is the highest DPI SetImageRendering accepts, higher values only make the PDF larger
+const MaxImageDPI = 2400
+
+// ErrConflictingImageOptions is returned by SetImageRendering when the settings are applied, but other options
+// work against them
+var ErrConflictingImageOptions = errors.New("conflicting image options")
+
+// ImageRenderOptions are the settings for the quality of images, see SetImageRendering.
+// Zero values keep the defaults of wkhtmltopdf.
+type ImageRenderOptions struct {
+	DPI                   uint // Images are scaled down to this resolution (--image-dpi, default 600)
+	Quality               uint // JPEG quality of images from 1 to 100 (--image-quality, default 94)
+	DisableSmartShrinking bool // Render with a constant pixel/DPI ratio (--disable-smart-shrinking)
+}
+
+// SetImageRendering sets the options which control the quality of images together: DPI and Quality set the
+// --image-dpi and --image-quality global options, and DisableSmartShrinking sets --disable-smart-shrinking for the
+// cover, the TOC and all pages added after this call, so content like line art is not scaled by a varying factor.
+// Zero values remove the global options, so the defaults of wkhtmltopdf are used.
+// An error is returned if DPI is above MaxImageDPI or Quality above 100, in which case nothing is changed. The
+// options are set, but an error wrapping ErrConflictingImageOptions is returned, if other options work against
+// them: --lowquality (LowQuality), which lowers the quality of images anyway, or a --dpi (Dpi) above DPI, which
+// makes images blurrier than the rest of the page. The options are stored by ToJSON.
+func (pdfg *PDFGenerator) SetImageRendering(opts ImageRenderOptions) error {
+	if opts.DPI > MaxImageDPI {
+		return fmt.Errorf("invalid image DPI %d, must be at most %d", opts.DPI, MaxImageDPI)
+	}
+	if opts.Quality > 100 {
+		return fmt.Errorf("invalid image quality %d, must be from 1 to 100", opts.Quality)
+	}
+
+	setUint := func(option *uintOption, value uint) {
+		if value > 0 {
+			option.Set(value)
+		} else {
+			option.Unset()
+		}
+	}
+	setUint(&pdfg.ImageDpi, opts.DPI)
+	setUint(&pdfg.ImageQuality, opts.Quality)
+	pdfg.imageRendering = opts
+	if opts.DisableSmartShrinking {
+		pdfg.Cover.DisableSmartShrinking.Set(true)
+		pdfg.TOC.DisableSmartShrinking.Set(true)
+	}
+
+	var conflicts []string
+	if pdfg.LowQuality.value && (opts.DPI > 0 || opts.Quality > 0) {
+		conflicts = append(conflicts, "LowQuality (--lowquality) lowers the quality of images regardless of their DPI and quality")
+	}
+	if pdfg.Dpi.isSet && opts.DPI > 0 && opts.DPI < pdfg.Dpi.value {
+		conflicts = append(conflicts, fmt.Sprintf("the image DPI %d is lower than the DPI %d of the page (--dpi), images are blurrier than the page", opts.DPI, pdfg.Dpi.value))
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrConflictingImageOptions, strings.Join(conflicts, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetImageRendering(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.SetImageRendering(ImageRenderOptions{DPI: 4800}), "invalid image DPI 4800, must be at most 2400")
+	assert.EqualError(t, pdfg.SetImageRendering(ImageRenderOptions{Quality: 101}), "invalid image quality 101, must be from 1 to 100")
+	assert.Equal(t, "-", pdfg.ArgString())
+
+	pdfg.TOC.Include = true
+	require.NoError(t, pdfg.SetImageRendering(ImageRenderOptions{DPI: 300, Quality: 100, DisableSmartShrinking: true}))
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.Equal(t, "--image-dpi 300 --image-quality 100 toc --disable-smart-shrinking "+
+		"page testdata/htmlsimple.html --disable-smart-shrinking -", pdfg.ArgString())
+
+	// the settings survive a JSON round-trip and apply to pages added after loading
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+	assert.Equal(t, pdfg.imageRendering, restored.imageRendering)
+	restored.AddPage(NewPage("testdata/html5.html"))
+	assert.Contains(t, restored.ArgString(), "page testdata/html5.html --disable-smart-shrinking")
+
+	// zero values restore the defaults of wkhtmltopdf
+	require.NoError(t, pdfg.SetImageRendering(ImageRenderOptions{}))
+	assert.NotContains(t, pdfg.ArgString(), "--image-")
+}
+
+func TestSetImageRenderingConflicts(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.LowQuality.Set(true)
+	pdfg.Dpi.Set(600)
+	err := pdfg.SetImageRendering(ImageRenderOptions{DPI: 300})
+	assert.ErrorIs(t, err, ErrConflictingImageOptions)
+	assert.EqualError(t, err, "conflicting image options: LowQuality (--lowquality) lowers the quality of images regardless of "+
+		"their DPI and quality; the image DPI 300 is lower than the DPI 600 of the page (--dpi), images are blurrier than the page")
+	// the options are set anyway
+	assert.Contains(t, pdfg.ArgString(), "--image-dpi 300")
+
+	pdfg.LowQuality.Set(false)
+	assert.NoError(t, pdfg.SetImageRendering(ImageRenderOptions{DPI: 600, Quality: 90}))
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -13,26 +14,156 @@ type jsonPDFGenerator struct {
 	OutlineOptions outlineOptions
 	Cover          cover
 	TOC            toc
+	TOCConfig      *TOCConfig `json:",omitempty"`
 	Pages          []jsonPage
+	RawArgs        []string         `json:",omitempty"`
+	AppendedPDFs   []appendedPDF    `json:",omitempty"`
+	PostProcessing *jsonPostProcess `json:",omitempty"`
+
+	// Global custom headers, which are applied to pages added after loading too
+	CustomHeaders           map[string]string `json:",omitempty"`
+	CustomHeaderPropagation bool              `json:",omitempty"`
+
+	// Page numbers in headers and footers, see SetPageNumberOffset and SetExcludeCoverFromNumbering
+	PageNumberOffset          int  `json:",omitempty"`
+	ExcludeCoverFromNumbering bool `json:",omitempty"`
+
+	// Image quality settings, smart shrinking is applied to pages added after loading too
+	ImageRendering *ImageRenderOptions `json:",omitempty"`
+
+	// Form fields, which are enabled for pages added after loading too
+	EnableForms bool `json:",omitempty"`
+
+	// Content Security Policy, which is applied to pages added after loading too
+	CSP string `json:",omitempty"`
+
+	// Markdown chapters, which are applied to the Markdown pages loaded and added after loading
+	MarkdownChapters bool `json:",omitempty"`
+
+	// Default style sheet and style sheets by page size, which are applied to pages added after loading too
+	UserStyleSheet        string            `json:",omitempty"`
+	UserStyleSheetsBySize map[string]string `json:",omitempty"`
+
+	// Safe mode, which is applied to pages added after loading too
+	SafeMode bool `json:",omitempty"`
+}
+
+// jsonPostProcess contains the settings of the built-in post-processing
+type jsonPostProcess struct {
+	Lang          string             `json:",omitempty"`
+	OutputIntent  *jsonOutputIntent  `json:",omitempty"`
+	Provenance    bool               `json:",omitempty"`
+	ForceOddStart bool               `json:",omitempty"`
+	FitToOnePage  bool               `json:",omitempty"`
+	TrimBlank     bool               `json:",omitempty"`
+	PageLabels    []PageLabelRange   `json:",omitempty"`
+	Viewer        *ViewerPreferences `json:",omitempty"`
+	PDFVersion    string             `json:",omitempty"`
+	NUp           *jsonNUp           `json:",omitempty"`
+	Tagged        bool               `json:",omitempty"`
+	Deterministic bool               `json:",omitempty"`
+	EmbedSource   bool               `json:",omitempty"`
+}
+
+// jsonNUp is the layout set with NUp
+type jsonNUp struct {
+	Cols, Rows int
+	NUpLayout
+}
+
+type jsonOutputIntent struct {
+	Profile    []byte // the ICC profile, as Base64 in JSON
+	Identifier string
 }
 
 type jsonPage struct {
-	Type           string // "page", "reader", or "markdown"
+	Type           string // "page", "reader", "markdown" or "asciidoc"
 	PageOptions    PageOptions
-	InputFile      string // URL/Path for Page, "-" for Reader/Markdown
-	InputPath      string // Path for MarkdownPage
-	Base64PageData string // Base64 content for Reader/Markdown
+	InputFile      string // URL/Path for Page, "-" for Reader/Markdown/AsciiDoc
+	InputPath      string // Path for MarkdownPage and AsciiDocPage
+	Base64PageData string // Base64 content for Reader/Markdown/AsciiDoc
+
+	// ResourceAllowlist is set by SetResourceAllowlist, left out when off and [] when all origins are blocked
+	ResourceAllowlist *[]string `json:",omitempty"`
+	InlineCSS         string    `json:",omitempty"` // set by SetInlineCSS
+	IncludePages      string    `json:",omitempty"` // set by IncludePages, like "1-3,5"
+}
+
+// pageOptions returns the options of the page with the options that aren't exported, as stored by ToJSON
+func (jp jsonPage) pageOptions() (PageOptions, error) {
+	po := jp.PageOptions
+	if jp.ResourceAllowlist != nil {
+		po.allowlist = *jp.ResourceAllowlist
+	}
+	po.inlineCSS = jp.InlineCSS
+	err := po.IncludePages(jp.IncludePages)
+	return po, err
 }
 
+// ErrPostProcessorNotSerializable is returned by ToJSON when post-processors were added with AddPostProcessor
+var ErrPostProcessorNotSerializable = errors.New("post-processors added with AddPostProcessor can not be stored in JSON")
+
 // ToJSON creates JSON of the complete representation of the PDFGenerator.
 // It also saves all pages. For a PageReader page, the content is stored as a Base64 string in the JSON.
+// A RenderedPage is stored with its HTML and restored as a PageReader, as its renderer can't be stored.
+// The resource allowlist (SetResourceAllowlist), the inline CSS (SetInlineCSS) and the included pages
+// (IncludePages) of each page are stored with the page.
+// Arguments added with AddRawArg, the page numbering (SetPageNumberOffset and SetExcludeCoverFromNumbering), the
+// PDFs added with AppendPDFAt (their paths, not their content) and the settings of the built-in post-processing
+// (SetLang, SetOutputIntent, SetProvenance, SetForceOddStart, SetFitToOnePage, SetTrimTrailingBlankPages,
+// SetPageLabels, SetViewerPreferences, SetPDFVersion, NUp, SetTagged, SetDeterministic and EmbedSource) are
+// stored as well. Functions added with AddPostProcessor can't be stored, ToJSON returns
+// ErrPostProcessorNotSerializable if there are any.
 func (pdfg *PDFGenerator) ToJSON() ([]byte, error) {
+	if len(pdfg.postProcessFuncs) > 0 {
+		return nil, ErrPostProcessorNotSerializable
+	}
 
 	jpdf := &jsonPDFGenerator{
 		TOC:            pdfg.TOC,
+		TOCConfig:      pdfg.tocConfig,
 		Cover:          pdfg.Cover,
 		GlobalOptions:  pdfg.globalOptions,
 		OutlineOptions: pdfg.outlineOptions,
+		RawArgs:        pdfg.rawArgs,
+		AppendedPDFs:   pdfg.appendedPDFs,
+
+		CustomHeaders:             pdfg.customHeader.value,
+		CustomHeaderPropagation:   pdfg.propagateHeaders,
+		PageNumberOffset:          pdfg.pageNumberOffset,
+		ExcludeCoverFromNumbering: pdfg.excludeCover,
+		EnableForms:               pdfg.enableForms,
+		CSP:                       pdfg.csp,
+		MarkdownChapters:          pdfg.markdownChapters,
+		UserStyleSheet:            pdfg.userStyleSheetPath,
+		UserStyleSheetsBySize:     pdfg.sizeStyleSheets,
+		SafeMode:                  pdfg.safeMode,
+	}
+	if pdfg.imageRendering != (ImageRenderOptions{}) {
+		jpdf.ImageRendering = &pdfg.imageRendering
+	}
+	if pdfg.lang != "" || pdfg.outputIntent != nil || pdfg.provenance || pdfg.forceOddStart || pdfg.trimBlankPages ||
+		len(pdfg.pageLabels) > 0 || pdfg.viewerPrefs != nil || pdfg.pdfVersion != "" || pdfg.nUp != nil || pdfg.tagged ||
+		pdfg.deterministic || pdfg.embedSource || pdfg.fitToOnePage {
+		jpdf.PostProcessing = &jsonPostProcess{
+			Lang:          pdfg.lang,
+			Provenance:    pdfg.provenance,
+			ForceOddStart: pdfg.forceOddStart,
+			FitToOnePage:  pdfg.fitToOnePage,
+			TrimBlank:     pdfg.trimBlankPages,
+			PageLabels:    pdfg.pageLabels,
+			Viewer:        pdfg.viewerPrefs,
+			PDFVersion:    pdfg.pdfVersion,
+			Tagged:        pdfg.tagged,
+			Deterministic: pdfg.deterministic,
+			EmbedSource:   pdfg.embedSource,
+		}
+		if n := pdfg.nUp; n != nil {
+			jpdf.PostProcessing.NUp = &jsonNUp{Cols: n.cols, Rows: n.rows, NUpLayout: n.layout}
+		}
+		if oi := pdfg.outputIntent; oi != nil {
+			jpdf.PostProcessing.OutputIntent = &jsonOutputIntent{Profile: oi.profile, Identifier: oi.identifier}
+		}
 	}
 
 	for _, p := range pdfg.pages {
@@ -55,12 +186,26 @@ func (pdfg *PDFGenerator) ToJSON() ([]byte, error) {
 			jp.PageOptions = *tp.Options()
 			jp.InputPath = tp.InputPath     // Store original Markdown path
 			pageContentReader = tp.Reader() // Get the reader (provides converted HTML) for Base64 encoding
+		case *AsciiDocPage:
+			jp.Type = "asciidoc"
+			jp.PageOptions = *tp.Options()
+			jp.InputPath = tp.InputPath     // Store original AsciiDoc path
+			pageContentReader = tp.Reader() // Get the reader (provides converted HTML) for Base64 encoding
+		case *RenderedPage:
+			jp.Type = "reader" // the renderer can't be stored, the page is restored as PageReader with its HTML
+			jp.PageOptions = *tp.Options()
+			pageContentReader = tp.Reader()
 		default:
 			// Should not happen if all PageProvider types are handled
 			return nil, fmt.Errorf("unknown PageProvider type encountered during JSON serialization: %T", p)
 		}
+		if jp.PageOptions.allowlist != nil {
+			jp.ResourceAllowlist = &jp.PageOptions.allowlist
+		}
+		jp.InlineCSS = jp.PageOptions.inlineCSS
+		jp.IncludePages = formatPageRanges(jp.PageOptions.includePages)
 
-		// If it's a type that provides content via Reader (PageReader or MarkdownPage)
+		// If it's a type that provides content via Reader (PageReader, MarkdownPage or AsciiDocPage)
 		if pageContentReader != nil {
 			buf, err := io.ReadAll(pageContentReader)
 			if err != nil {
@@ -95,11 +240,71 @@ func NewPDFGeneratorFromJSON(jsonReader io.Reader) (*PDFGenerator, error) {
 	}
 
 	pdfg.TOC = jp.TOC
+	if jp.TOCConfig != nil {
+		if err := jp.TOCConfig.validate(); err != nil {
+			return nil, err
+		}
+		pdfg.tocConfig = jp.TOCConfig
+	}
 	pdfg.Cover = jp.Cover
 	pdfg.globalOptions = jp.GlobalOptions
 	pdfg.outlineOptions = jp.OutlineOptions
+	pdfg.rawArgs = jp.RawArgs
+	for _, ap := range jp.AppendedPDFs {
+		if err := pdfg.AppendPDFAt(ap.Position, ap.Path, ap.Label); err != nil {
+			return nil, err
+		}
+	}
+	pdfg.customHeader.value = jp.CustomHeaders
+	pdfg.propagateHeaders = jp.CustomHeaderPropagation
+	pdfg.SetPageNumberOffset(jp.PageNumberOffset)
+	pdfg.SetExcludeCoverFromNumbering(jp.ExcludeCoverFromNumbering)
+	if jp.ImageRendering != nil {
+		// the global options were loaded already, conflicts were reported when the options were set
+		if err := pdfg.SetImageRendering(*jp.ImageRendering); err != nil && !errors.Is(err, ErrConflictingImageOptions) {
+			return nil, err
+		}
+	}
+	if pp := jp.PostProcessing; pp != nil {
+		pdfg.lang = pp.Lang
+		pdfg.provenance = pp.Provenance
+		pdfg.forceOddStart = pp.ForceOddStart
+		pdfg.fitToOnePage = pp.FitToOnePage
+		pdfg.trimBlankPages = pp.TrimBlank
+		pdfg.tagged = pp.Tagged
+		pdfg.deterministic = pp.Deterministic
+		pdfg.embedSource = pp.EmbedSource
+		if err := pdfg.SetPageLabels(pp.PageLabels); err != nil {
+			return nil, err
+		}
+		if pp.Viewer != nil {
+			if err := pdfg.SetViewerPreferences(*pp.Viewer); err != nil {
+				return nil, err
+			}
+		}
+		if err := pdfg.SetPDFVersion(pp.PDFVersion); err != nil {
+			return nil, err
+		}
+		if pp.NUp != nil {
+			if err := pdfg.NUp(pp.NUp.Cols, pp.NUp.Rows, pp.NUp.NUpLayout); err != nil {
+				return nil, err
+			}
+		}
+		if pp.OutputIntent != nil {
+			components, err := iccComponents(pp.OutputIntent.Profile)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ICC profile for output intent: %w", err)
+			}
+			pdfg.outputIntent = &outputIntent{profile: pp.OutputIntent.Profile, components: components, identifier: pp.OutputIntent.Identifier}
+		}
+	}
 
+	pdfg.markdownChapters = jp.MarkdownChapters
 	for i, p := range jp.Pages {
+		opts, err := p.pageOptions()
+		if err != nil {
+			return nil, fmt.Errorf("invalid IncludePages on page %d: %w", i, err)
+		}
 		switch p.Type {
 		case "page":
 			// InputFile should contain the URL or path
@@ -107,7 +312,7 @@ func NewPDFGeneratorFromJSON(jsonReader io.Reader) (*PDFGenerator, error) {
 				return nil, fmt.Errorf("invalid InputFile value for page type on page %d", i)
 			}
 			page := NewPage(p.InputFile)
-			page.PageOptions = p.PageOptions // Restore options
+			page.PageOptions = opts // Restore options
 			pdfg.AddPage(page)
 
 		case "reader":
@@ -120,25 +325,54 @@ func NewPDFGeneratorFromJSON(jsonReader io.Reader) (*PDFGenerator, error) {
 				return nil, fmt.Errorf("error decoding base64 input for reader type on page %d: %w", i, err)
 			}
 			pageReader := NewPageReader(bytes.NewReader(buf))
-			pageReader.PageOptions = p.PageOptions // Restore options
+			pageReader.PageOptions = opts // Restore options
 			pdfg.AddPage(pageReader)
 
 		case "markdown":
 			// InputPath should contain the original Markdown file path
 			if p.InputPath == "" {
-				return nil, fmt.Errorf("missing InputPath for markdown type on page %d", i)
+				// pages without a file, like from NewMarkdownPageFromHTML, use the stored HTML
+				if p.Base64PageData == "" {
+					return nil, fmt.Errorf("missing InputPath for markdown type on page %d", i)
+				}
+				buf, err := base64.StdEncoding.DecodeString(p.Base64PageData)
+				if err != nil {
+					return nil, fmt.Errorf("error decoding base64 input for markdown type on page %d: %w", i, err)
+				}
+				markdownPage := NewMarkdownPageFromHTML("", buf)
+				markdownPage.PageOptions = opts // Restore options
+				pdfg.AddPage(markdownPage)
+				break
 			}
 			// Recreate MarkdownPage from the path; it will handle reading/conversion
 			markdownPage := NewMarkdownPage(p.InputPath)
-			markdownPage.PageOptions = p.PageOptions // Restore options
+			markdownPage.PageOptions = opts // Restore options
 			pdfg.AddPage(markdownPage)
 			// Note: We ignore Base64PageData here, relying on InputPath for Markdown
 
+		case "asciidoc":
+			// InputPath should contain the original AsciiDoc file path
+			if p.InputPath == "" {
+				return nil, fmt.Errorf("missing InputPath for asciidoc type on page %d", i)
+			}
+			asciiDocPage := NewAsciiDocPage(p.InputPath)
+			asciiDocPage.PageOptions = opts // Restore options
+			pdfg.AddPage(asciiDocPage)
+			// Note: like for Markdown, Base64PageData is ignored and the file is converted again
+
 		default:
 			return nil, fmt.Errorf("unknown page type %q encountered during JSON deserialization on page %d", p.Type, i)
 		}
 	}
 
+	// set after the pages were added, which have their own options
+	pdfg.enableForms = jp.EnableForms
+	pdfg.SetCSP(jp.CSP)
+	pdfg.SetUserStyleSheet(jp.UserStyleSheet)
+	for size, path := range jp.UserStyleSheetsBySize {
+		pdfg.SetUserStyleSheetFor(size, path)
+	}
+	pdfg.SetSafeMode(jp.SafeMode)
 	return pdfg, nil
 }
 
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPDFGenerator_ToJSON(t *testing.T) {
@@ -138,3 +140,85 @@ func assertJSON(t *testing.T, option, newOption interface{}) {
 		t.Errorf("Diff after marshal and unmarshal:\n%+v\n%+v", option, newOption)
 	}
 }
+
+func TestSetOutlineDepth(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.Error(t, pdfg.SetOutlineDepth(0))
+	assert.NotContains(t, pdfg.ArgString(), "--outline-depth")
+
+	require.NoError(t, pdfg.SetOutlineDepth(2))
+	pdfg.AddPage(NewMarkdownPage("testdata/testmd.md"))
+	assert.Contains(t, pdfg.ArgString(), "--outline-depth 2 ")
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+}
+
+func TestCoverOptions(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.SetCoverZoom(0.75)
+	pdfg.CoverOptions().DisableJavascript.Set(true)
+	assert.Equal(t, 0.75, pdfg.Cover.Zoom.value)
+	assert.Contains(t, pdfg.ArgString(), "cover testdata/htmlsimple.html --disable-javascript --zoom 0.750")
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+	assert.Equal(t, 0.75, restored.CoverOptions().Zoom.value)
+}
+
+func TestJSONRawArgsAndPostProcessing(t *testing.T) {
+	iccPath := filepath.Join(t.TempDir(), "test.icc")
+	require.NoError(t, os.WriteFile(iccPath, newTestICCProfile("RGB "), 0o644))
+
+	pdfg := NewPDFPreparer()
+	pdfg.AddRawArg("--log-level", "warn")
+	pdfg.SetLang("de-DE")
+	require.NoError(t, pdfg.SetOutputIntent(iccPath, "sRGB IEC61966-2.1"))
+	pdfg.SetProvenance(true)
+	pdfg.SetTrimTrailingBlankPages(true)
+	require.NoError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 1, Style: PageLabelRomanLower}, {StartPage: 2, Prefix: "A-"}}))
+	require.NoError(t, pdfg.SetPDFVersion("1.7"))
+	require.NoError(t, pdfg.NUp(1, 1, NUpLayout{Orientation: OrientationLandscape, Gutter: "5mm"}))
+	pdfg.SetDeterministic(true)
+	pdfg.EmbedSource(true)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.Contains(t, pdfg.ArgString(), "--log-level warn page testdata/htmlsimple.html")
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+	assert.True(t, restored.provenance)
+	assert.True(t, restored.trimBlankPages)
+	assert.Equal(t, "1.7", restored.pdfVersion)
+	assert.Equal(t, pdfg.nUp, restored.nUp)
+	assert.True(t, restored.deterministic)
+	assert.True(t, restored.embedSource)
+
+	apply := func(processors []PostProcessor) []byte {
+		pdf := newTestPDF(2)
+		for _, process := range processors {
+			pdf, err = process(pdf)
+			require.NoError(t, err)
+		}
+		return pdf
+	}
+	assert.Len(t, restored.postProcessors(), 7)
+	assert.Equal(t, apply(pdfg.postProcessors()), apply(restored.postProcessors()))
+}
+
+func TestToJSONPostProcessorFunc(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) { return pdf, nil })
+	_, err := pdfg.ToJSON()
+	assert.ErrorIs(t, err, ErrPostProcessorNotSerializable)
+}
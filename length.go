@@ -0,0 +1,112 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Unit is a unit of length supported by wkhtmltopdf for margins and page sizes
+type Unit string
+
+// The units of length
+const (
+	Millimeter Unit = "mm"
+	Centimeter Unit = "cm"
+	Inch       Unit = "in"
+	Pixel      Unit = "px" // 1/96 inch, as in CSS
+	Point      Unit = "pt" // 1/72 inch
+)
+
+// millimeters is the length of each unit in millimeters
+var millimeters = map[Unit]float64{
+	Millimeter: 1,
+	Centimeter: 10,
+	Inch:       25.4,
+	Pixel:      25.4 / 96,
+	Point:      25.4 / 72,
+}
+
+// Length is a length with a unit, like a margin of 25mm
+type Length struct {
+	Value float64
+	Unit  Unit
+}
+
+var lengthRegex = regexp.MustCompile(`^\s*(\d+(?:\.\d+)?|\.\d+)\s*([a-zA-Z]+)\s*$`)
+
+// ParseLength parses a length like "25mm", "2.5cm", "1in", "96px" or "72pt".
+// An error is returned for a missing or unknown unit, or a value which is not a positive number.
+func ParseLength(s string) (Length, error) {
+	m := lengthRegex.FindStringSubmatch(s)
+	if m == nil {
+		return Length{}, fmt.Errorf("invalid length %q, use a number and a unit like 25mm", s)
+	}
+	unit := Unit(strings.ToLower(m[2]))
+	if _, ok := millimeters[unit]; !ok {
+		return Length{}, fmt.Errorf("invalid length %q, unknown unit %q, use mm, cm, in, px or pt", s, m[2])
+	}
+	value, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return Length{}, fmt.Errorf("invalid length %q: %w", s, err)
+	}
+	return Length{Value: value, Unit: unit}, nil
+}
+
+// String returns the length as accepted by wkhtmltopdf, like "25mm"
+func (l Length) String() string {
+	return strconv.FormatFloat(l.Value, 'f', -1, 64) + string(l.Unit)
+}
+
+// To returns the length converted to unit
+func (l Length) To(unit Unit) Length {
+	return Length{Value: l.Millimeters() / millimeters[unit], Unit: unit}
+}
+
+// Millimeters returns the length in millimeters
+func (l Length) Millimeters() float64 {
+	return l.Value * millimeters[l.Unit]
+}
+
+// parseLengths parses the lengths and returns the first error, with name to tell which length is invalid
+func parseLengths(names []string, values []string) ([]Length, error) {
+	lengths := make([]Length, len(values))
+	for i, v := range values {
+		l, err := ParseLength(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", names[i], err)
+		}
+		lengths[i] = l
+	}
+	return lengths, nil
+}
+
+// SetMargins sets the page margins, each with a unit like "25mm" (see ParseLength).
+// An error is returned if one of the margins is invalid, in which case no margin is changed.
+func (pdfg *PDFGenerator) SetMargins(top, right, bottom, left string) error {
+	l, err := parseLengths([]string{"top margin", "right margin", "bottom margin", "left margin"}, []string{top, right, bottom, left})
+	if err != nil {
+		return err
+	}
+	pdfg.MarginTopUnit.Set(l[0].String())
+	pdfg.MarginRightUnit.Set(l[1].String())
+	pdfg.MarginBottomUnit.Set(l[2].String())
+	pdfg.MarginLeftUnit.Set(l[3].String())
+	return nil
+}
+
+// SetCustomPageSize sets the page width and height, each with a unit like "148mm" (see ParseLength).
+// It is used instead of PageSize. An error is returned if the width or height is invalid or zero.
+func (pdfg *PDFGenerator) SetCustomPageSize(width, height string) error {
+	l, err := parseLengths([]string{"page width", "page height"}, []string{width, height})
+	if err != nil {
+		return err
+	}
+	if l[0].Value == 0 || l[1].Value == 0 {
+		return fmt.Errorf("invalid page size %s x %s, the width and height must be larger than 0", l[0], l[1])
+	}
+	pdfg.PageWidthUnit.Set(l[0].String())
+	pdfg.PageHeightUnit.Set(l[1].String())
+	return nil
+}
@@ -0,0 +1,64 @@
+package wkhtmltopdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLength(t *testing.T) {
+	tests := map[string]Length{
+		"25mm":    {25, Millimeter},
+		"2.5cm":   {2.5, Centimeter},
+		"1in":     {1, Inch},
+		" 96 PX ": {96, Pixel},
+		"72pt":    {72, Point},
+		".5in":    {0.5, Inch},
+		"0mm":     {0, Millimeter},
+	}
+	for s, want := range tests {
+		l, err := ParseLength(s)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, l, s)
+	}
+
+	for _, s := range []string{"", "25", "25m", "mm", "-5mm", "1,5cm", "2.cm", "1e3mm", "25 mm mm"} {
+		_, err := ParseLength(s)
+		assert.Error(t, err, s)
+	}
+	_, err := ParseLength("25m")
+	assert.EqualError(t, err, `invalid length "25m", unknown unit "m", use mm, cm, in, px or pt`)
+}
+
+func TestLengthConversion(t *testing.T) {
+	inch := Length{1, Inch}
+	assert.InDelta(t, 25.4, inch.To(Millimeter).Value, 1e-9)
+	assert.InDelta(t, 2.54, inch.To(Centimeter).Value, 1e-9)
+	assert.InDelta(t, 96, inch.To(Pixel).Value, 1e-9)
+	assert.InDelta(t, 72, inch.To(Point).Value, 1e-9)
+	assert.InDelta(t, 1, Length{72, Point}.To(Inch).Value, 1e-9)
+	assert.Equal(t, Centimeter, inch.To(Centimeter).Unit)
+	assert.InDelta(t, 25.4, inch.Millimeters(), 1e-9)
+	assert.Equal(t, "2.5cm", Length{2.5, Centimeter}.String())
+}
+
+func TestSetMargins(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	require.NoError(t, pdfg.SetMargins("25mm", "1in", "2.5cm", "10 pt"))
+	assert.Contains(t, pdfg.ArgString(), "--margin-bottom 2.5cm --margin-left 10pt --margin-right 1in --margin-top 25mm")
+
+	err := pdfg.SetMargins("10mm", "10mm", "25m", "10mm")
+	assert.ErrorContains(t, err, "bottom margin: invalid length")
+	assert.Equal(t, "25mm", pdfg.MarginTopUnit.value, "no margin is changed on an error")
+}
+
+func TestSetCustomPageSize(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	require.NoError(t, pdfg.SetCustomPageSize("148mm", "210mm"))
+	assert.Contains(t, pdfg.ArgString(), "--page-height 210mm")
+	assert.Contains(t, pdfg.ArgString(), "--page-width 148mm")
+
+	assert.ErrorContains(t, pdfg.SetCustomPageSize("0mm", "210mm"), "must be larger than 0")
+	assert.ErrorContains(t, pdfg.SetCustomPageSize("148", "210mm"), "page width: invalid length")
+}
@@ -0,0 +1,61 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrOutputTooLarge is returned by Create when the PDF is larger than the limit set with SetMaxOutputBytes
+var ErrOutputTooLarge = errors.New("output too large")
+
+// SetMaxOutputBytes limits the size of the PDF generated by wkhtmltopdf to n bytes, to protect a service from
+// inputs which produce huge PDFs. When the output exceeds the limit, wkhtmltopdf is killed and Create returns
+// ErrOutputTooLarge. When OutputFile is set wkhtmltopdf writes the file itself, so the size is checked after it
+// has finished and the file is removed if it is too large. 0 (the default) means no limit.
+func (pdfg *PDFGenerator) SetMaxOutputBytes(n int64) {
+	pdfg.maxOutputBytes = n
+}
+
+// limitWriter passes writes to w until more than max bytes are written, then it calls exceeded and
+// returns ErrOutputTooLarge
+type limitWriter struct {
+	w        io.Writer
+	max      int64
+	n        int64
+	exceeded func()
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	lw.n += int64(len(p))
+	if lw.n > lw.max {
+		lw.exceeded()
+		return 0, lw.err()
+	}
+	return lw.w.Write(p)
+}
+
+func (lw *limitWriter) isExceeded() bool {
+	return lw.n > lw.max
+}
+
+func (lw *limitWriter) err() error {
+	return fmt.Errorf("%w: the maximum is %d bytes", ErrOutputTooLarge, lw.max)
+}
+
+// checkOutputFileSize removes OutputFile and returns ErrOutputTooLarge if it is larger than the maximum size
+func (pdfg *PDFGenerator) checkOutputFileSize() error {
+	if pdfg.maxOutputBytes <= 0 || pdfg.OutputFile == "" {
+		return nil
+	}
+	fi, err := os.Stat(pdfg.OutputFile)
+	if err != nil {
+		return fmt.Errorf("error checking output file size: %w", err)
+	}
+	if fi.Size() > pdfg.maxOutputBytes {
+		os.Remove(pdfg.OutputFile)
+		return fmt.Errorf("%w: the maximum is %d bytes, the output file has %d bytes", ErrOutputTooLarge, pdfg.maxOutputBytes, fi.Size())
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetMaxOutputBytes(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>too large</p>")))
+	pdfg.SetMaxOutputBytes(100)
+	err = pdfg.Create()
+	assert.ErrorIs(t, err, ErrOutputTooLarge)
+	assert.Empty(t, pdfg.Bytes())
+
+	// a custom writer gets at most the limit
+	var out bytes.Buffer
+	pdfg.SetOutput(&out)
+	pdfg.pages[0] = NewPageReader(strings.NewReader("<p>too large</p>"))
+	assert.ErrorIs(t, pdfg.Create(), ErrOutputTooLarge)
+	assert.LessOrEqual(t, out.Len(), 100)
+
+	pdfg.SetOutput(nil)
+	pdfg.pages[0] = NewPageReader(strings.NewReader("<p>small enough</p>"))
+	pdfg.SetMaxOutputBytes(1 << 20)
+	require.NoError(t, pdfg.Create())
+	assert.NotEmpty(t, pdfg.Bytes())
+}
+
+func TestSetMaxOutputBytesOutputFile(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>too large</p>")))
+	pdfg.OutputFile = filepath.Join(t.TempDir(), "large.pdf")
+	pdfg.SetMaxOutputBytes(100)
+	assert.ErrorIs(t, pdfg.Create(), ErrOutputTooLarge)
+	_, err = os.Stat(pdfg.OutputFile)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,331 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintSeverity is how serious a LintIssue is
+type LintSeverity int
+
+const (
+	LintWarning LintSeverity = iota // The input can be rendered, but probably not as intended
+	LintError                       // Rendering fails or content is lost
+)
+
+func (s LintSeverity) String() string {
+	switch s {
+	case LintWarning:
+		return "warning"
+	case LintError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// LintIssue is a problem found by Lint
+type LintIssue struct {
+	Severity LintSeverity
+	Location string // Where the input is used, like "page 2" or "page 1 --user-style-sheet"
+	File     string // The file with the problem, empty for pages from memory
+	Line     int    // The line of the problem, counting from 1, or 0 for the whole input
+	Message  string
+}
+
+// String returns the issue like "page 1 (doc.html:12): warning: <div> is not closed"
+func (li LintIssue) String() string {
+	where := li.Location
+	switch {
+	case li.File != "" && li.Line > 0:
+		where += fmt.Sprintf(" (%s:%d)", li.File, li.Line)
+	case li.File != "":
+		where += " (" + li.File + ")"
+	case li.Line > 0:
+		where += fmt.Sprintf(" (line %d)", li.Line)
+	}
+	return where + ": " + li.Severity.String() + ": " + li.Message
+}
+
+var (
+	htmlTokenRegex   = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)|<![^>]*>|<(/?)([a-zA-Z][a-zA-Z0-9-]*)(?:\s[^>]*)?>`)
+	anchorStartRegex = regexp.MustCompile(`(?is)<a\s[^>]*>`)
+)
+
+// voidElements are the HTML elements without an end tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// optionalEndElements are the HTML elements whose end tag may be left out
+var optionalEndElements = map[string]bool{
+	"html": true, "head": true, "body": true, "p": true, "li": true, "dt": true, "dd": true, "option": true,
+	"optgroup": true, "rt": true, "rp": true, "tr": true, "td": true, "th": true, "thead": true, "tbody": true,
+	"tfoot": true, "colgroup": true,
+}
+
+// rawTextElements are the HTML elements whose content is not parsed as HTML
+var rawTextElements = map[string]bool{"script": true, "style": true, "textarea": true, "title": true}
+
+// Lint checks the HTML of the pages, the cover, header and footer HTML files and the style sheets for obvious
+// problems before rendering, like for quick feedback in an editor. It finds unreadable files, HTML elements which
+// are not closed or closed without being opened, CSS blocks, comments and strings which are not closed, and
+// references to local files (images, scripts, style sheets, CSS url() and @import) which don't exist. Relative
+// references are checked against the directory of the file; in pages from memory only file:// URLs are checked.
+// Pages are checked after Markdown and AsciiDoc conversion, so for these the lines are those of the generated HTML.
+// It also warns about top and bottom margins which are too small for the header or footer of a page or the TOC,
+// which then overlaps the content (see SetHeaderFooterMinHeight).
+// Lint doesn't run or need wkhtmltopdf, doesn't load URLs and doesn't change the generator; PageReader pages can
+// still be rendered afterwards. It returns nil if nothing was found.
+func (pdfg *PDFGenerator) Lint() []LintIssue {
+	var issues []LintIssue
+	linted := make(map[string]bool)
+	for _, ref := range pdfg.fileRefs() {
+		path, ok := localPath(ref.path)
+		if !ok || linted[path] || strings.HasPrefix(ref.location, "page ") && !strings.Contains(ref.location, " --") {
+			continue // pages are checked below
+		}
+		linted[path] = true
+		content, err := os.ReadFile(path)
+		if err != nil {
+			issues = append(issues, LintIssue{Severity: LintError, Location: ref.location, File: ref.path, Message: fmt.Sprintf("can't read file: %v", err)})
+			continue
+		}
+		var found []LintIssue
+		switch {
+		case ref.location == "user style sheets" || strings.HasSuffix(ref.location, "--user-style-sheet"):
+			found = lintCSS(content, filepath.Dir(path))
+		case ref.location == "cover" || strings.HasSuffix(ref.location, "--header-html") || strings.HasSuffix(ref.location, "--footer-html"):
+			found = lintHTML(content, filepath.Dir(path))
+		}
+		for _, issue := range found {
+			issue.Location, issue.File = ref.location, ref.path
+			issues = append(issues, issue)
+		}
+	}
+
+	for i, page := range pdfg.pages {
+		location := fmt.Sprintf("page %d", i+1)
+		html, err := pdfg.pageHTML(i)
+		if err != nil {
+			issues = append(issues, LintIssue{Severity: LintError, Location: location, Message: err.Error()})
+			continue
+		}
+		dir, file := "", ""
+		if page.Reader() == nil {
+			if html == nil {
+				continue // URLs are not loaded
+			}
+			file = page.InputFile()
+			path, _ := localPath(file)
+			dir = filepath.Dir(path)
+		}
+		for _, issue := range lintHTML(html, dir) {
+			issue.Location, issue.File = location, file
+			issues = append(issues, issue)
+		}
+		if css := page.Options().injectedCSS(); css != "" {
+			for _, issue := range lintCSS([]byte(css), "") {
+				issue.Location = location + " inline CSS"
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return append(issues, pdfg.lintHeaderFooterMargins()...)
+}
+
+// lineAt returns the line of the byte at offset in content, counting from 1
+func lineAt(content []byte, offset int) int {
+	return bytes.Count(content[:offset], []byte("\n")) + 1
+}
+
+// sortByLine sorts the issues of an input by line
+func sortByLine(issues []LintIssue) []LintIssue {
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues
+}
+
+// lintHTML returns the issues in HTML content: unbalanced tags, unclosed comments and missing local files
+// relative to dir. The issues don't have a location yet.
+func lintHTML(content []byte, dir string) []LintIssue {
+	var issues []LintIssue
+	warn := func(offset int, format string, args ...any) {
+		issues = append(issues, LintIssue{Severity: LintWarning, Line: lineAt(content, offset), Message: fmt.Sprintf(format, args...)})
+	}
+
+	type openElement struct {
+		name   string
+		offset int
+	}
+	var open []openElement
+	lower := bytes.ToLower(content)
+	pos := 0
+	for {
+		loc := htmlTokenRegex.FindSubmatchIndex(content[pos:])
+		if loc == nil {
+			break
+		}
+		for i := range loc {
+			if loc[i] >= 0 {
+				loc[i] += pos
+			}
+		}
+		start := loc[0]
+		pos = loc[1]
+		if bytes.HasPrefix(content[start:], []byte("<!--")) {
+			if !bytes.HasSuffix(content[start:pos], []byte("-->")) {
+				warn(start, "comment is not closed")
+			}
+			continue
+		}
+		if loc[4] < 0 {
+			continue // <!DOCTYPE html> and the like
+		}
+		name := string(lower[loc[4]:loc[5]])
+		if loc[3] == loc[2] { // start tag
+			if voidElements[name] {
+				continue
+			}
+			if rawTextElements[name] {
+				// the content is skipped up to the end tag
+				end := bytes.Index(lower[pos:], []byte("</"+name))
+				if end < 0 {
+					warn(start, "<%s> is not closed", name)
+					break
+				}
+				pos += end
+				if gt := bytes.IndexByte(content[pos:], '>'); gt >= 0 {
+					pos += gt + 1
+				}
+				continue
+			}
+			open = append(open, openElement{name: name, offset: start})
+			continue
+		}
+
+		// end tag
+		if voidElements[name] {
+			continue
+		}
+		i := len(open) - 1
+		for i >= 0 && open[i].name != name {
+			i--
+		}
+		if i < 0 {
+			warn(start, "</%s> has no matching start tag", name)
+			continue
+		}
+		for _, e := range open[i+1:] {
+			if !optionalEndElements[e.name] {
+				warn(e.offset, "<%s> is not closed before </%s> on line %d", e.name, name, lineAt(content, start))
+			}
+		}
+		open = open[:i]
+	}
+	for _, e := range open {
+		if !optionalEndElements[e.name] {
+			warn(e.offset, "<%s> is not closed", e.name)
+		}
+	}
+
+	// links to other documents are not loaded, so only the references to resources are checked
+	resources := anchorStartRegex.ReplaceAll(content, []byte("<a>"))
+	for _, ref := range extractResourceURLs(resources) {
+		issues = append(issues, lintLocalRef(content, ref, dir)...)
+	}
+	return sortByLine(issues)
+}
+
+// lintCSS returns the issues in CSS content: unbalanced braces, unclosed comments and strings and missing local
+// files relative to dir. The issues don't have a location yet.
+func lintCSS(content []byte, dir string) []LintIssue {
+	var issues []LintIssue
+	add := func(severity LintSeverity, offset int, format string, args ...any) {
+		issues = append(issues, LintIssue{Severity: severity, Line: lineAt(content, offset), Message: fmt.Sprintf(format, args...)})
+	}
+
+	var blocks []int // offsets of the open {
+	for i := 0; i < len(content); i++ {
+		switch c := content[i]; c {
+		case '/':
+			if i+1 < len(content) && content[i+1] == '*' {
+				end := bytes.Index(content[i+2:], []byte("*/"))
+				if end < 0 {
+					add(LintError, i, "comment is not closed")
+					i = len(content)
+					break
+				}
+				i += 2 + end + 1
+			}
+		case '"', '\'':
+			j := i + 1
+			for j < len(content) && content[j] != c && content[j] != '\n' {
+				if content[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(content) || content[j] != c {
+				add(LintWarning, i, "string is not closed")
+			}
+			i = j
+		case '{':
+			blocks = append(blocks, i)
+		case '}':
+			if len(blocks) == 0 {
+				add(LintWarning, i, "} without matching {")
+				continue
+			}
+			blocks = blocks[:len(blocks)-1]
+		}
+	}
+	for _, offset := range blocks {
+		add(LintError, offset, "{ is not closed, the rest of the style sheet is ignored")
+	}
+
+	for _, ref := range extractResourceURLs(content) {
+		issues = append(issues, lintLocalRef(content, ref, dir)...)
+	}
+	return sortByLine(issues)
+}
+
+// lintLocalRef returns a warning if ref is a reference to a local file which doesn't exist. Relative references
+// are resolved against dir, and not checked if dir is empty.
+func lintLocalRef(content []byte, ref string, dir string) []LintIssue {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "//") {
+		return nil
+	}
+	u, err := url.Parse(ref)
+	if err != nil {
+		return nil
+	}
+	var path string
+	switch {
+	case u.Scheme == "file":
+		path = u.Path
+	case len(u.Scheme) > 1 || dir == "": // a single letter scheme is a Windows drive letter
+		return nil
+	case filepath.IsAbs(u.Path) || len(u.Scheme) == 1:
+		path = ref
+	default:
+		path = filepath.Join(dir, filepath.FromSlash(u.Path))
+	}
+	if path == "" {
+		return nil
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	line := 0
+	if i := bytes.Index(content, []byte(ref)); i >= 0 {
+		line = lineAt(content, i)
+	}
+	return []LintIssue{{Severity: LintWarning, Line: line, Message: "referenced file does not exist: " + ref}}
+}
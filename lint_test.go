@@ -0,0 +1,86 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint(t *testing.T) {
+	t.Setenv("PATH", "") // wkhtmltopdf is not needed
+	pdfg := NewPDFPreparer()
+	page := NewPage("testdata/lint/page.html")
+	page.UserStyleSheet.Set("testdata/lint/style.css")
+	pdfg.AddPage(page)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPage(NewPage("https://example.com/not-loaded.html"))
+
+	css := "testdata/lint/style.css"
+	html := "testdata/lint/page.html"
+	want := []LintIssue{
+		{LintWarning, "page 1 --user-style-sheet", css, 3, "referenced file does not exist: images/missing-bg.png"},
+		{LintError, "page 1 --user-style-sheet", css, 4, "{ is not closed, the rest of the style sheet is ignored"},
+		{LintWarning, "page 1 --user-style-sheet", css, 5, "string is not closed"},
+		{LintError, "page 1 --user-style-sheet", css, 5, "{ is not closed, the rest of the style sheet is ignored"},
+		{LintWarning, "page 1", html, 10, "<b> is not closed before </p> on line 10"},
+		{LintWarning, "page 1", html, 11, "referenced file does not exist: images/missing.png"},
+		{LintWarning, "page 1", html, 15, "<section> is not closed"},
+		{LintWarning, "page 1", html, 17, "</span> has no matching start tag"},
+		{LintWarning, "page 1", html, 18, "comment is not closed"},
+	}
+	assert.Equal(t, want, pdfg.Lint())
+	assert.Equal(t, "page 1 (testdata/lint/page.html:10): warning: <b> is not closed before </p> on line 10", want[4].String())
+}
+
+func TestLintFromMemory(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	content := "<div>\n<img src=\"relative.png\"><img src=\"file:///does/not/exist.png\">\n"
+	reader := NewPageReader(strings.NewReader(content))
+	reader.SetInlineCSS("p { color: red;")
+	pdfg.AddPage(reader)
+	pdfg.AddPage(NewMarkdownPage("testdata/missing.md"))
+
+	issues := pdfg.Lint()
+	require.Len(t, issues, 4)
+	// relative references can't be checked in pages from memory
+	assert.Equal(t, LintIssue{LintWarning, "page 1", "", 1, "<div> is not closed"}, issues[0])
+	assert.Equal(t, LintIssue{LintWarning, "page 1", "", 2, "referenced file does not exist: file:///does/not/exist.png"}, issues[1])
+	assert.Equal(t, LintIssue{LintError, "page 1 inline CSS", "", 1, "{ is not closed, the rest of the style sheet is ignored"}, issues[2])
+	assert.Equal(t, LintError, issues[3].Severity)
+	assert.Equal(t, "page 2", issues[3].Location)
+	assert.Contains(t, issues[3].Message, "failed to read markdown file testdata/missing.md")
+
+	// the page can still be rendered
+	b, err := pdfg.pageHTML(0)
+	require.NoError(t, err)
+	assert.True(t, bytes.Contains(b, []byte("relative.png")))
+}
+
+func TestLintFiles(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.Cover.Input = "testdata/lint/page.html"
+	pdfg.SetUserStyleSheets("testdata/lint/missing.css")
+	page := NewPage("testdata/htmlsimple.html")
+	page.FooterHTML.Set("testdata/footer.html")
+	pdfg.AddPage(page)
+
+	issues := pdfg.Lint()
+	require.NotEmpty(t, issues)
+	assert.Equal(t, LintError, issues[0].Severity)
+	assert.Equal(t, "user style sheets", issues[0].Location)
+	assert.Contains(t, issues[0].Message, "can't read file")
+	for _, issue := range issues[1:] {
+		assert.Equal(t, "cover", issue.Location)
+	}
+}
+
+func TestLintCleanInputs(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPage(NewPageReader(strings.NewReader("<!DOCTYPE html><html><body><p>one<p>two<br></body></html>")))
+	pdfg.AddPage(NewMarkdownPage("testdata/testmd.md"))
+	assert.Nil(t, pdfg.Lint())
+}
@@ -0,0 +1,47 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// localeRegex matches POSIX locale names like de_DE.UTF-8, en_US, sr_RS@latin, C and C.UTF-8
+var localeRegex = regexp.MustCompile(`^(?:C|POSIX|[a-z]{2,3}(?:_[A-Z]{2}|_\d{3})?)(?:\.[A-Za-z0-9-]+)?(?:@[A-Za-z0-9]+)?$`)
+
+// SetLocale sets the locale of the wkhtmltopdf processes, like "de_DE.UTF-8", so dates and numbers formatted by
+// JavaScript (like toLocaleString) and other locale-dependent output don't depend on the locale of the host.
+// It sets the LC_ALL and LANG environment variables of the processes and removes the other LC_* variables and
+// LANGUAGE; the rest of the environment is inherited. The locale must be installed on the host (see locale -a),
+// otherwise the C library falls back to the C locale. Windows doesn't use these variables, so there it has no
+// effect. An empty locale inherits the environment again. An error is returned for names which are not POSIX
+// locale names (language_TERRITORY.codeset@modifier), like en-US, in which case nothing is changed.
+func (pdfg *PDFGenerator) SetLocale(locale string) error {
+	if locale != "" && !localeRegex.MatchString(locale) {
+		return fmt.Errorf("invalid locale %q, use a name like en_US.UTF-8", locale)
+	}
+	pdfg.locale = locale
+	return nil
+}
+
+// applyLocale sets the environment of cmd for SetLocale
+func (pdfg *PDFGenerator) applyLocale(cmd *exec.Cmd) {
+	if pdfg.locale == "" {
+		return
+	}
+	env := cmd.Env
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = make([]string, 0, len(env)+2)
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == "LANG" || name == "LANGUAGE" || strings.HasPrefix(name, "LC_") {
+			continue
+		}
+		cmd.Env = append(cmd.Env, kv)
+	}
+	cmd.Env = append(cmd.Env, "LC_ALL="+pdfg.locale, "LANG="+pdfg.locale)
+}
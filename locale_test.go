@@ -0,0 +1,63 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetLocale(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	for _, locale := range []string{"de_DE.UTF-8", "en_US", "C", "C.UTF-8", "POSIX", "sr_RS@latin", "es_419.UTF-8"} {
+		assert.NoError(t, pdfg.SetLocale(locale), locale)
+	}
+	assert.EqualError(t, pdfg.SetLocale("en-US"), `invalid locale "en-US", use a name like en_US.UTF-8`)
+	assert.EqualError(t, pdfg.SetLocale("de_DE UTF-8"), `invalid locale "de_DE UTF-8", use a name like en_US.UTF-8`)
+	assert.Equal(t, "es_419.UTF-8", pdfg.locale)
+
+	t.Setenv("LC_NUMERIC", "en_US.UTF-8")
+	t.Setenv("LANGUAGE", "en")
+	t.Setenv("GOPDF_TEST", "kept")
+	require.NoError(t, pdfg.SetLocale("de_DE.UTF-8"))
+	cmd := exec.Command("wkhtmltopdf")
+	pdfg.applyLocale(cmd)
+	assert.Contains(t, cmd.Env, "LC_ALL=de_DE.UTF-8")
+	assert.Contains(t, cmd.Env, "LANG=de_DE.UTF-8")
+	assert.Contains(t, cmd.Env, "GOPDF_TEST=kept")
+	assert.NotContains(t, cmd.Env, "LC_NUMERIC=en_US.UTF-8")
+	assert.NotContains(t, cmd.Env, "LANGUAGE=en")
+
+	// without a locale the environment is inherited
+	require.NoError(t, pdfg.SetLocale(""))
+	cmd = exec.Command("wkhtmltopdf")
+	pdfg.applyLocale(cmd)
+	assert.Nil(t, cmd.Env)
+}
+
+func TestSetLocaleCreate(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	// a fake wkhtmltopdf which logs its locale
+	dir := t.TempDir()
+	log := filepath.Join(dir, "locale.log")
+	script := "#!/bin/sh\ncat > /dev/null\necho \"$LC_ALL $LANG\" > " + log + "\nprintf '%%PDF-1.4\\n'\n"
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	require.NoError(t, pdfg.SetLocale("fr_FR.UTF-8"))
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>1 234,5</p>")))
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+	b, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "fr_FR.UTF-8 fr_FR.UTF-8\n", string(b))
+}
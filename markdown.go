@@ -0,0 +1,463 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	gohtml "html"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+	"github.com/gomarkdown/markdown/parser"
+	"gopkg.in/yaml.v3"
+)
+
+// MarkdownFlavor selects the Markdown syntax MarkdownPage understands
+type MarkdownFlavor int
+
+const (
+	// FlavorGFM is the default flavor, with GitHub Flavored Markdown style extensions: gomarkdown's
+	// CommonExtensions (intra-word emphasis disabled, tables, fenced code, autolinks, strikethrough, spaces
+	// required after # in headings, {#id} heading ids, backslash line breaks, definition lists and MathJax)
+	// plus automatic heading ids and blocks which don't need an empty line before them.
+	FlavorGFM MarkdownFlavor = iota
+	// FlavorCommonMark is closer to plain CommonMark: only fenced code, spaces required after # in headings,
+	// backslash line breaks, automatic heading ids and blocks which don't need an empty line before them.
+	// Tables, bare URLs, ~~strikethrough~~, {#id} heading ids, definition lists and MathJax are left as text.
+	FlavorCommonMark
+)
+
+// parserExtensions returns the gomarkdown parser extensions for the flavor
+func (f MarkdownFlavor) parserExtensions() parser.Extensions {
+	if f == FlavorCommonMark {
+		return parser.FencedCode | parser.SpaceHeadings | parser.BackslashLineBreak |
+			parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
+	}
+	return parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
+}
+
+// MarkdownTitleSource selects where MarkdownPage takes the title of the document from, see MarkdownPage.Title
+type MarkdownTitleSource int
+
+const (
+	// TitleAuto uses the title of the front matter, or else the text of the first H1 heading
+	TitleAuto MarkdownTitleSource = iota
+	// TitleFromFrontMatter only uses the title of the front matter, like "title: Handbook"
+	TitleFromFrontMatter
+	// TitleFromH1 only uses the text of the first H1 heading
+	TitleFromH1
+	// TitleNone leaves the title empty
+	TitleNone
+)
+
+// frontMatterTitleRegex matches the title in a YAML front matter block
+var frontMatterTitleRegex = regexp.MustCompile(`(?m)^title:[ \t]*(.*?)[ \t]*\r?$`)
+
+// title returns the title of the Markdown md, parsed to doc, for the source
+func (s MarkdownTitleSource) title(md []byte, doc ast.Node) string {
+	var title string
+	if s == TitleAuto || s == TitleFromFrontMatter {
+		title = frontMatterTitle(md)
+	}
+	if title == "" && (s == TitleAuto || s == TitleFromH1) {
+		title = firstH1Text(doc)
+	}
+	return title
+}
+
+// frontMatterTitle returns the title of the YAML front matter block at the start of md, or "" if there is none
+func frontMatterTitle(md []byte) string {
+	m := frontMatterTitleRegex.FindSubmatch(frontMatterRegex.Find(md))
+	if m == nil {
+		return ""
+	}
+	title := string(m[1])
+	switch {
+	case len(title) >= 2 && title[0] == '"' && title[len(title)-1] == '"':
+		if unquoted, err := strconv.Unquote(title); err == nil {
+			return unquoted
+		}
+		return title[1 : len(title)-1]
+	case len(title) >= 2 && title[0] == '\'' && title[len(title)-1] == '\'':
+		return strings.ReplaceAll(title[1:len(title)-1], "''", "'")
+	}
+	if i := strings.Index(title, " #"); i >= 0 {
+		title = strings.TrimSpace(title[:i]) // a comment
+	}
+	return title
+}
+
+// chapterHeadingStyle hides the heading of a chapter bookmark (see MarkdownPage.Chapter) without taking it out of
+// the layout, wkhtmltopdf leaves headings which are not laid out out of the outline
+const chapterHeadingStyle = "height:0;margin:0;padding:0;border:0;overflow:hidden"
+
+// chapterHeading returns the hidden H1 heading which makes the chapter bookmark with label
+func chapterHeading(label string) []byte {
+	return []byte(`<h1 class="markdown-chapter" style="` + chapterHeadingStyle + `">` + gohtml.EscapeString(label) + "</h1>\n")
+}
+
+// chapterLabel returns the label of the chapter bookmark of the Markdown md with the document title: Title, the
+// title of the front matter, the file name without extension or the title found in the Markdown, like the first
+// H1 heading
+func (mp *MarkdownPage) chapterLabel(md []byte, title string) string {
+	if mp.Title != "" {
+		return mp.Title
+	}
+	if label := frontMatterTitle(md); label != "" {
+		return label
+	}
+	if mp.InputPath != "" {
+		name := filepath.Base(mp.InputPath)
+		return strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return title
+}
+
+// shiftHeadings moves the headings of doc down one level, H6 headings stay H6
+func shiftHeadings(doc ast.Node) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if heading, ok := node.(*ast.Heading); ok && entering && heading.Level < 6 {
+			heading.Level++
+		}
+		return ast.GoToNext
+	})
+}
+
+// firstH1Text returns the text of the first H1 heading in doc, or "" if there is none
+func firstH1Text(doc ast.Node) string {
+	var text strings.Builder
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		heading, ok := node.(*ast.Heading)
+		if !ok || !entering || heading.Level != 1 {
+			return ast.GoToNext
+		}
+		ast.WalkFunc(heading, func(node ast.Node, entering bool) ast.WalkStatus {
+			switch n := node.(type) {
+			case *ast.Text:
+				text.Write(n.Literal)
+			case *ast.Code:
+				text.Write(n.Literal)
+			}
+			return ast.GoToNext
+		})
+		return ast.Terminate
+	})
+	return strings.TrimSpace(text.String())
+}
+
+var (
+	htmlShellStartRegex = regexp.MustCompile(`(?is)^\s*(<!DOCTYPE[^>]*>\s*)?<html[\s>].*?<body[^>]*>`)
+	htmlShellEndRegex   = regexp.MustCompile(`(?is)</body\s*>\s*</html\s*>\s*$`)
+)
+
+// ErrEmptyMarkdown is returned for a MarkdownPage with FailOnEmpty set when the Markdown has no content
+var ErrEmptyMarkdown = errors.New("markdown is empty")
+
+// ErrInvalidFrontmatter is returned for a MarkdownPage with ParseFrontmatter set when the front matter block is
+// not closed or is not a YAML mapping
+var ErrInvalidFrontmatter = errors.New("invalid front matter")
+
+var (
+	frontMatterRegex      = regexp.MustCompile(`(?s)\A(?:\xef\xbb\xbf)?---[ \t]*\r?\n(.*?\r?\n)?(?:---|\.\.\.)[ \t]*(?:\r?\n|\z)`)
+	frontMatterStartRegex = regexp.MustCompile(`\A(?:\xef\xbb\xbf)?---[ \t]*\r?\n`)
+	htmlCommentRegex      = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// parseFrontMatter returns the values of the YAML front matter block at the start of md and the Markdown after the
+// block. If md has no front matter, the values are nil and md is returned unchanged. An error is returned if the
+// block is not closed by a "---" or "..." line or is not a YAML mapping.
+func parseFrontMatter(md []byte) (map[string]any, []byte, error) {
+	m := frontMatterRegex.FindSubmatchIndex(md)
+	if m == nil {
+		if frontMatterStartRegex.Match(md) {
+			return nil, md, errors.New("the front matter has no closing --- line")
+		}
+		return nil, md, nil
+	}
+	values := make(map[string]any)
+	if m[2] >= 0 {
+		if err := yaml.Unmarshal(md[m[2]:m[3]], &values); err != nil {
+			return nil, md, err
+		}
+	}
+	return values, md[m[1]:], nil
+}
+
+// isEmptyMarkdown returns true if md has no content to render, ignoring a YAML front matter block at the start
+// (between two "---" lines), HTML comments and whitespace
+func isEmptyMarkdown(md []byte, flavor MarkdownFlavor) bool {
+	md = frontMatterRegex.ReplaceAll(md, nil)
+	if len(bytes.TrimSpace(md)) == 0 {
+		return true
+	}
+	doc := parser.NewWithExtensions(flavor.parserExtensions()).Parse(md)
+	body := markdown.Render(doc, html.NewRenderer(html.RendererOptions{}))
+	return len(bytes.TrimSpace(htmlCommentRegex.ReplaceAll(body, nil))) == 0
+}
+
+// renderAlignedTableCell renders the start tag of a table cell of an aligned column (like |:---:| in GFM tables)
+// like the html.Renderer, with the alignment as inline text-align style in addition to the align attribute, so it is
+// kept when a style sheet sets text-align for td or th. It is used as RenderNodeHook, other nodes are not handled.
+func renderAlignedTableCell(r *html.Renderer, w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+	cell, ok := node.(*ast.TableCell)
+	if !ok || !entering || cell.Align.String() == "" {
+		return ast.GoToNext, false
+	}
+	tag := "<td"
+	if cell.IsHeader {
+		tag = "<th"
+	}
+	align := cell.Align.String()
+	attrs := []string{`align="` + align + `"`, `style="text-align:` + align + `"`}
+	if cell.ColSpan > 0 {
+		attrs = append(attrs, fmt.Sprintf(`colspan="%d"`, cell.ColSpan))
+	}
+	if ast.GetPrevNode(cell) == nil {
+		r.CR(w)
+	}
+	r.OutTag(w, tag, attrs)
+	return ast.GoToNext, true
+}
+
+// stripHTMLComments removes the HTML comments from the raw HTML blocks and spans of doc, except the comments whose
+// trimmed text is in keep. Nodes which only had comments are removed. Code is not raw HTML, so comments in code blocks
+// and spans are not changed.
+func stripHTMLComments(doc ast.Node, keep []string) {
+	var emptied []ast.Node
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		var leaf *ast.Leaf
+		switch n := node.(type) {
+		case *ast.HTMLBlock:
+			leaf = &n.Leaf
+		case *ast.HTMLSpan:
+			leaf = &n.Leaf
+		default:
+			return ast.GoToNext
+		}
+		stripped := htmlCommentRegex.ReplaceAllFunc(leaf.Literal, func(comment []byte) []byte {
+			if slices.Contains(keep, strings.TrimSpace(string(comment[len("<!--"):len(comment)-len("-->")]))) {
+				return comment
+			}
+			return nil
+		})
+		if len(bytes.TrimSpace(stripped)) == 0 {
+			emptied = append(emptied, node)
+		}
+		leaf.Literal = stripped
+		return ast.GoToNext
+	})
+	for _, node := range emptied {
+		ast.RemoveFromTree(node)
+	}
+}
+
+// rewriteLinks replaces the destination of each link in doc by the result of rewrite
+func rewriteLinks(doc ast.Node, rewrite func(href string) string) {
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if link, ok := node.(*ast.Link); ok && entering {
+			link.Destination = []byte(rewrite(string(link.Destination)))
+		}
+		return ast.GoToNext
+	})
+}
+
+// splitHTMLShell splits Markdown which is wrapped in a raw HTML document into the part up to and including
+// the <body> tag, the Markdown content and the part from the </body> tag.
+// If md is not wrapped in an HTML document, start and end are nil and content is md.
+func splitHTMLShell(md []byte) (start, content, end []byte) {
+	startLoc := htmlShellStartRegex.FindIndex(md)
+	if startLoc == nil {
+		return nil, md, nil
+	}
+	endLoc := htmlShellEndRegex.FindIndex(md[startLoc[1]:])
+	if endLoc == nil {
+		return nil, md, nil
+	}
+	endStart := startLoc[1] + endLoc[0]
+	return md[:startLoc[1]:startLoc[1]], md[startLoc[1]:endStart], md[endStart:]
+}
+
+// SlugifyHeading returns the anchor id MarkdownPage generates for a heading with the given text,
+// so links like [see below](#my-heading) can be written with confidence, also across documents.
+//
+// The rules are those of the AutoHeadingIDs extension of github.com/gomarkdown/markdown:
+//   - letters and numbers (including non-ASCII ones) are kept, letters are lowercased
+//   - every run of other characters (spaces, punctuation, Markdown markup) becomes a single "-"
+//   - leading and trailing runs of other characters are dropped
+//   - a heading without letters or numbers gets the id "empty"
+//
+// The text is the heading as written in the Markdown source, so markup is part of it:
+// "Hello *World*" gives "hello-world". Use HeadingSlugger to also get the suffixes for duplicate headings.
+func SlugifyHeading(text string) string {
+	var slug []rune
+	dash := false
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if dash && len(slug) > 0 {
+				slug = append(slug, '-')
+			}
+			dash = false
+			slug = append(slug, unicode.ToLower(r))
+		} else {
+			dash = true
+		}
+	}
+	if len(slug) == 0 {
+		return "empty"
+	}
+	return string(slug)
+}
+
+// HeadingSlugger generates heading anchor ids like SlugifyHeading, and makes them unique within a document
+// the same way MarkdownPage does: the second heading with the same id gets "-1" appended, the third "-2", etc.
+// Headings must be passed in document order. The zero value is ready to use.
+type HeadingSlugger struct {
+	taken map[string]bool
+}
+
+// Slug returns the unique anchor id for the next heading in the document
+func (hs *HeadingSlugger) Slug(text string) string {
+	if hs.taken == nil {
+		hs.taken = make(map[string]bool)
+	}
+	base := SlugifyHeading(text)
+	id := base
+	for n := 1; hs.taken[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	hs.taken[id] = true
+	return id
+}
+
+// MarkdownTemplate is a template which produces Markdown, like a *text/template.Template or *html/template.Template
+type MarkdownTemplate interface {
+	Execute(w io.Writer, data any) error
+}
+
+// NewMarkdownTemplatePage executes tmpl with data and returns a MarkdownPage for the resulting Markdown, so
+// Markdown with template directives like {{.Name}} can be personalized without writing it to a file first.
+// The Markdown is converted like a Markdown file, so SkipFirstH1H2, NoWrap and the other fields can be set on
+// the returned page. An error is returned if the template can not be executed.
+// Use text/template for Markdown; html/template escapes the data for HTML, which is only correct where the
+// data ends up in raw HTML.
+func NewMarkdownTemplatePage(tmpl MarkdownTemplate, data any) (*MarkdownPage, error) {
+	var md bytes.Buffer
+	if err := tmpl.Execute(&md, data); err != nil {
+		return nil, fmt.Errorf("error executing markdown template: %w", err)
+	}
+	mp := NewMarkdownPage("")
+	mp.source = append([]byte{}, md.Bytes()...) // not nil, also for an empty result
+	return mp, nil
+}
+
+// NewMarkdownPageFromHTML returns a MarkdownPage for Markdown which was already converted to HTML, for example by
+// reading the Reader of another MarkdownPage. html is used as the cached conversion result (the same cache a
+// MarkdownPage fills on its first Reader call), so the Markdown is not read and converted again and fields like
+// SkipFirstH1H2, NoWrap, Flavor and Lang have no effect. inputPath is the path of the original Markdown file and
+// only used as metadata: ToJSON stores it, and NewPDFGeneratorFromJSON converts that file again. If inputPath is
+// empty, NewPDFGeneratorFromJSON uses the HTML stored by ToJSON instead.
+func NewMarkdownPageFromHTML(inputPath string, html []byte) *MarkdownPage {
+	mp := NewMarkdownPage(inputPath)
+	mp.htmlCache = append([]byte{}, html...) // not nil, also for empty HTML
+	return mp
+}
+
+// maxIncludeDepth limits how deeply Markdown includes can be nested
+const maxIncludeDepth = 16
+
+var (
+	includeRegex = regexp.MustCompile(`^\s*(?:!include\s+(.+?)|\{\{\s*include:\s*(.+?)\s*\}\})\s*$`)
+	fenceRegex   = regexp.MustCompile("^\\s*(```|~~~)")
+)
+
+// includeRoot returns the absolute directory dir with symbolic links resolved, includes must be below it
+func includeRoot(dir string) string {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return dir
+}
+
+// insideDir reports whether path is in the directory root, which is absolute and has symbolic links resolved, or
+// in one of its subdirectories. Symbolic links in path are resolved if it exists.
+func insideDir(root, path string) bool {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(root, abs)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// expandIncludes replaces the include lines in md ("!include path.md" or "{{include: path.md}}" on a line of its
+// own, outside of fenced code blocks) with the content of the included files. Relative paths are relative to dir,
+// and only files in root (see includeRoot) can be included. Included files are expanded too, stack holds the
+// files which are being included to detect cycles.
+func expandIncludes(md []byte, dir, root string, stack []string) ([]byte, error) {
+	if !bytes.Contains(md, []byte("include")) {
+		return md, nil
+	}
+	var out bytes.Buffer
+	var fence string
+	for _, line := range bytes.SplitAfter(md, []byte("\n")) {
+		if m := fenceRegex.FindSubmatch(line); m != nil {
+			switch fence {
+			case "":
+				fence = string(m[1])
+			case string(m[1]):
+				fence = ""
+			}
+		}
+		m := includeRegex.FindSubmatch(bytes.TrimRight(line, "\r\n"))
+		if fence != "" || m == nil {
+			out.Write(line)
+			continue
+		}
+
+		name := string(m[1])
+		if name == "" {
+			name = string(m[2])
+		}
+		path := name
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if !insideDir(root, path) {
+			return nil, fmt.Errorf("markdown include %s is outside of the directory %s", name, root)
+		}
+		if slices.Contains(stack, path) {
+			return nil, fmt.Errorf("markdown include cycle: %s", strings.Join(append(stack, path), " -> "))
+		}
+		if len(stack) >= maxIncludeDepth {
+			return nil, fmt.Errorf("markdown includes nested deeper than %d levels at %s", maxIncludeDepth, path)
+		}
+		included, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include markdown file %s: %w", name, err)
+		}
+		included, err = expandIncludes(included, filepath.Dir(path), root, append(stack, path))
+		if err != nil {
+			return nil, err
+		}
+		out.Write(included)
+		if len(included) > 0 && included[len(included)-1] != '\n' {
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,630 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readMarkdownHTML returns the HTML generated by a MarkdownPage
+func readMarkdownHTML(t *testing.T, mp *MarkdownPage) string {
+	b, err := io.ReadAll(mp.Reader())
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestMarkdownPageNoWrap(t *testing.T) {
+	// by default the raw HTML document is wrapped in a second document
+	wrapped := readMarkdownHTML(t, NewMarkdownPage("testdata/fullhtml.md"))
+	assert.True(t, strings.HasPrefix(wrapped, "<!DOCTYPE html><html><head>"))
+	assert.Equal(t, 2, strings.Count(wrapped, "<html>"))
+
+	// with NoWrap only the converted document is used
+	mp := NewMarkdownPage("testdata/fullhtml.md")
+	mp.NoWrap = true
+	html := readMarkdownHTML(t, mp)
+	assert.True(t, strings.HasPrefix(html, "<!DOCTYPE html>\n<html>"))
+	assert.True(t, strings.HasSuffix(html, "</body>\n</html>\n"))
+	assert.Equal(t, 1, strings.Count(html, "<html>"))
+	assert.Equal(t, 1, strings.Count(html, "<head>"))
+	assert.Contains(t, html, `<h1 id="heading-in-raw-document">Heading in raw document</h1>`)
+	assert.Contains(t, html, "<strong>bold</strong>")
+
+	// without a shell NoWrap returns just the converted Markdown
+	mp = NewMarkdownPage("testdata/testmd.md")
+	mp.NoWrap = true
+	html = readMarkdownHTML(t, mp)
+	assert.True(t, strings.HasPrefix(html, "<h1 "))
+	assert.NotContains(t, html, "<body>")
+}
+
+func TestSplitHTMLShell(t *testing.T) {
+	start, content, end := splitHTMLShell([]byte("<!DOCTYPE html>\n<html lang=\"en\"><head><title>T</title></head>\n<BODY class=\"x\">\n# Title\n</body>\n</html>\n"))
+	assert.Equal(t, "<!DOCTYPE html>\n<html lang=\"en\"><head><title>T</title></head>\n<BODY class=\"x\">", string(start))
+	assert.Equal(t, "\n# Title\n", string(content))
+	assert.Equal(t, "</body>\n</html>\n", string(end))
+
+	// no shell, or an incomplete shell
+	for _, md := range []string{"# Title\n", "<html><body>\n# Title\n"} {
+		start, content, end = splitHTMLShell([]byte(md))
+		assert.Nil(t, start)
+		assert.Equal(t, md, string(content))
+		assert.Nil(t, end)
+	}
+}
+
+func TestSlugifyHeading(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Introduction", "introduction"},
+		{"Hello, World!", "hello-world"},
+		{"  What's new in v2.0?  ", "what-s-new-in-v2-0"},
+		{"Hello *World*", "hello-world"},
+		{"C++ & Go", "c-go"},
+		{"Überblick über Größen", "überblick-über-größen"},
+		{"日本語 テキスト", "日本語-テキスト"},
+		{"100%", "100"},
+		{"---", "empty"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, SlugifyHeading(tt.text), tt.text)
+	}
+}
+
+func TestHeadingSlugger(t *testing.T) {
+	var hs HeadingSlugger
+	assert.Equal(t, "intro", hs.Slug("Intro"))
+	assert.Equal(t, "intro-1", hs.Slug("Intro"))
+	assert.Equal(t, "intro-2", hs.Slug("intro!"))
+	assert.Equal(t, "other", hs.Slug("Other"))
+}
+
+func TestHeadingSluggerMatchesMarkdownPage(t *testing.T) {
+	headings := []string{"Hello, World!", "Hello World", "Größe *fett*", "C++ & Go", "Hello World", "100%"}
+	var md strings.Builder
+	for _, h := range headings {
+		md.WriteString("## " + h + "\n\nText\n\n")
+	}
+	path := t.TempDir() + "/headings.md"
+	require.NoError(t, os.WriteFile(path, []byte(md.String()), 0644))
+
+	html := readMarkdownHTML(t, NewMarkdownPage(path))
+	var hs HeadingSlugger
+	for _, h := range headings {
+		assert.Contains(t, html, `<h2 id="`+hs.Slug(h)+`">`)
+	}
+}
+
+func TestMarkdownPageFlavor(t *testing.T) {
+	gfm := readMarkdownHTML(t, NewMarkdownPage("testdata/flavor.md"))
+	assert.Contains(t, gfm, `<a href="https://example.com" target="_blank">https://example.com</a>`)
+	assert.Contains(t, gfm, "<table>")
+	assert.Contains(t, gfm, "<del>removed</del>")
+
+	mp := NewMarkdownPage("testdata/flavor.md")
+	mp.Flavor = FlavorCommonMark
+	commonMark := readMarkdownHTML(t, mp)
+	assert.Contains(t, commonMark, "Visit https://example.com for details.")
+	assert.NotContains(t, commonMark, "<a href")
+	assert.NotContains(t, commonMark, "<table>")
+	assert.Contains(t, commonMark, "~~removed~~")
+
+	// heading ids are generated for both flavors
+	assert.Contains(t, gfm, `<h1 id="markdown-flavors">`)
+	assert.Contains(t, commonMark, `<h1 id="markdown-flavors">`)
+}
+
+func TestNewMarkdownTemplatePage(t *testing.T) {
+	tmpl := template.Must(template.New("letter").Parse("# Letter\n\n## For {{.Name}}\n\nDear {{.Name}},\n"))
+
+	mp, err := NewMarkdownTemplatePage(tmpl, map[string]string{"Name": "Jane"})
+	require.NoError(t, err)
+	html := readMarkdownHTML(t, mp)
+	assert.Contains(t, html, `<h1 id="letter">Letter</h1>`)
+	assert.Contains(t, html, "<p>Dear Jane,</p>")
+
+	mp, err = NewMarkdownTemplatePage(tmpl, map[string]string{"Name": "Jane"})
+	require.NoError(t, err)
+	mp.SkipFirstH1H2 = true
+	html = readMarkdownHTML(t, mp)
+	assert.NotContains(t, html, "<h1")
+	assert.NotContains(t, html, "<h2")
+	assert.Contains(t, html, "<p>Dear Jane,</p>")
+
+	mp, err = NewMarkdownTemplatePage(template.Must(template.New("empty").Parse("")), nil)
+	require.NoError(t, err)
+	assert.NotContains(t, readMarkdownHTML(t, mp), "failed to read")
+
+	_, err = NewMarkdownTemplatePage(template.Must(template.New("bad").Parse("{{.Name.Missing}}")), map[string]string{"Name": "Jane"})
+	assert.ErrorContains(t, err, "error executing markdown template")
+}
+
+func TestNewMarkdownPageFromHTML(t *testing.T) {
+	converted := readMarkdownHTML(t, NewMarkdownPage("testdata/testmd.md"))
+
+	// the HTML is used as it is, the file is not converted again
+	mp := NewMarkdownPageFromHTML("testdata/testmd.md", []byte(converted))
+	mp.SkipFirstH1H2 = true
+	assert.Equal(t, "testdata/testmd.md", mp.InputPath)
+	assert.Equal(t, converted, readMarkdownHTML(t, mp))
+
+	// the path is only metadata and need not exist
+	assert.Equal(t, "<p>x</p>", readMarkdownHTML(t, NewMarkdownPageFromHTML("testdata/missing.md", []byte("<p>x</p>"))))
+
+	// without a path the HTML is stored in and restored from JSON
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewMarkdownPageFromHTML("", []byte(converted)))
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	require.Len(t, restored.pages, 1)
+	assert.Equal(t, converted, readMarkdownHTML(t, restored.pages[0].(*MarkdownPage)))
+}
+
+func TestMarkdownPageHeadExtras(t *testing.T) {
+	mp := NewMarkdownPage("testdata/headextras.md")
+	mp.Lang = "en-GB"
+	mp.HeadExtras = `<meta name="viewport" content="width=device-width"><meta name="description" content="Q&A">`
+	html := readMarkdownHTML(t, mp)
+	assert.True(t, strings.HasPrefix(html, `<!DOCTYPE html><html lang="en-GB"><head><meta charset="utf-8"><title>Head Extras</title>`+
+		`<meta name="viewport" content="width=device-width"><meta name="description" content="Q&A"></head><body>`), html)
+	assert.Contains(t, html, "<h1")
+
+	// the extras are part of the generated wrapper, so NoWrap leaves them out
+	mp = NewMarkdownPage("testdata/headextras.md")
+	mp.HeadExtras = `<meta name="viewport" content="width=device-width">`
+	mp.NoWrap = true
+	assert.NotContains(t, readMarkdownHTML(t, mp), `<meta name="viewport"`)
+}
+
+// wrapLinksInSpan is an AST transformer which wraps every link in <span class="link">
+func wrapLinksInSpan(doc ast.Node) {
+	var links []*ast.Link
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if link, ok := node.(*ast.Link); ok && entering {
+			links = append(links, link)
+		}
+		return ast.GoToNext
+	})
+	for _, link := range links {
+		parent := link.GetParent()
+		var children []ast.Node
+		for _, child := range parent.GetChildren() {
+			if child != ast.Node(link) {
+				children = append(children, child)
+				continue
+			}
+			open := &ast.HTMLSpan{Leaf: ast.Leaf{Literal: []byte(`<span class="link">`), Parent: parent}}
+			closing := &ast.HTMLSpan{Leaf: ast.Leaf{Literal: []byte(`</span>`), Parent: parent}}
+			children = append(children, open, link, closing)
+		}
+		parent.SetChildren(children)
+	}
+}
+
+func TestMarkdownPageASTTransformers(t *testing.T) {
+	mp := NewMarkdownPage("testdata/links.md")
+	var calls []string
+	mp.ASTTransformers = []func(doc ast.Node){
+		wrapLinksInSpan,
+		func(doc ast.Node) {
+			_, ok := doc.(*ast.Document)
+			assert.True(t, ok)
+			calls = append(calls, "second")
+		},
+	}
+	html := readMarkdownHTML(t, mp)
+	assert.Contains(t, html, `<span class="link"><a href="https://github.com/localrivet/gopdf" target="_blank">the project</a></span>`)
+	assert.Contains(t, html, `<span class="link"><a href="docs/api.md" target="_blank">the docs</a></span>`)
+	assert.Equal(t, 2, strings.Count(html, `<span class="link">`))
+	assert.Contains(t, html, "<p>A paragraph without links.</p>")
+
+	// the HTML is cached, so the transformers run once
+	readMarkdownHTML(t, mp)
+	assert.Equal(t, []string{"second"}, calls)
+}
+
+func TestMarkdownIncludes(t *testing.T) {
+	root := includeRoot("testdata/includes")
+	md, err := expandIncludes([]byte("# Manual\n\n!include chapters/install.md\n"), "testdata/includes", root, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "# Manual\n\n## Installation\n\nDownload the release.\n\nNote: shared by several chapters.\n", string(md))
+
+	html := readMarkdownHTML(t, newIncludePage("testdata/includes/manual.md"))
+	assert.Contains(t, html, `<h2 id="installation">Installation</h2>`)
+	assert.Contains(t, html, "<p>Note: shared by several chapters.</p>")
+	assert.Contains(t, html, `<h2 id="usage">Usage</h2>`)
+	// include lines in code blocks are kept
+	assert.Contains(t, html, "!include not-expanded.md")
+
+	_, err = io.ReadAll(newIncludePage("testdata/includes/cycle-a.md").Reader())
+	assert.EqualError(t, err, "markdown include cycle: "+filepath.FromSlash("testdata/includes/cycle-a.md -> testdata/includes/cycle-b.md -> testdata/includes/cycle-a.md"))
+
+	_, err = io.ReadAll(newIncludePage("testdata/includes/missing.md").Reader())
+	assert.ErrorContains(t, err, "failed to include markdown file does-not-exist.md")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMarkdownIncludesDisabled(t *testing.T) {
+	// includes are opt-in
+	html := readMarkdownHTML(t, NewMarkdownPage("testdata/includes/manual.md"))
+	assert.Contains(t, html, "!include chapters/install.md")
+	assert.NotContains(t, html, "Download the release.")
+
+	// not in safe mode
+	pdfg := NewPDFPreparer()
+	pdfg.SetSafeMode(true)
+	page := newIncludePage("testdata/includes/manual.md")
+	pdfg.AddPage(page)
+	assert.Contains(t, readMarkdownHTML(t, page), "!include chapters/install.md")
+
+	// and not for Markdown without a file, which would include from the working directory
+	page, err := NewMarkdownTemplatePage(template.Must(template.New("t").Parse("!include testdata/includes/note.md\n")), nil)
+	require.NoError(t, err)
+	page.ExpandIncludes = true
+	assert.Contains(t, readMarkdownHTML(t, page), "!include testdata/includes/note.md")
+}
+
+func TestMarkdownIncludesOutsideDir(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(t.TempDir(), "secret.md")
+	require.NoError(t, os.WriteFile(secret, []byte("secret\n"), 0644))
+	for _, include := range []string{secret, "../" + filepath.Base(filepath.Dir(secret)) + "/secret.md", "sub/../../x.md"} {
+		path := filepath.Join(dir, "doc.md")
+		require.NoError(t, os.WriteFile(path, []byte("!include "+include+"\n"), 0644))
+		page := newIncludePage(path)
+		_, err := io.ReadAll(page.Reader())
+		assert.ErrorContains(t, err, "is outside of the directory", include)
+	}
+
+	if runtime.GOOS != "windows" {
+		// a symbolic link to a file outside of the directory is not followed
+		require.NoError(t, os.Symlink(secret, filepath.Join(dir, "link.md")))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "doc.md"), []byte("!include link.md\n"), 0644))
+		_, err := io.ReadAll(newIncludePage(filepath.Join(dir, "doc.md")).Reader())
+		assert.ErrorContains(t, err, "markdown include link.md is outside of the directory")
+	}
+}
+
+// newIncludePage returns a MarkdownPage for path with ExpandIncludes set
+func newIncludePage(path string) *MarkdownPage {
+	page := NewMarkdownPage(path)
+	page.ExpandIncludes = true
+	return page
+}
+
+func TestMarkdownIncludeDepth(t *testing.T) {
+	// a file which includes itself under a different name each time is stopped by the depth limit
+	dir := t.TempDir()
+	for i := 0; i <= maxIncludeDepth; i++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.md", i)), []byte(fmt.Sprintf("!include %d.md\n", i+1)), 0644))
+	}
+	_, err := io.ReadAll(newIncludePage(filepath.Join(dir, "0.md")).Reader())
+	assert.ErrorContains(t, err, "markdown includes nested deeper than 16 levels")
+}
+
+func TestMarkdownPageFailOnEmpty(t *testing.T) {
+	for _, path := range []string{"testdata/empty.md", "testdata/frontmatteronly.md"} {
+		// by default an empty file is converted
+		readMarkdownHTML(t, NewMarkdownPage(path))
+
+		mp := NewMarkdownPage(path)
+		mp.FailOnEmpty = true
+		_, err := io.ReadAll(mp.Reader())
+		assert.ErrorIs(t, err, ErrEmptyMarkdown, path)
+		assert.EqualError(t, err, "markdown is empty: "+path+" has no content")
+
+		pdfg, err := NewPDFGenerator()
+		require.NoError(t, err)
+		mp = NewMarkdownPage(path)
+		mp.FailOnEmpty = true
+		pdfg.AddPage(mp)
+		assert.ErrorIs(t, pdfg.Create(), ErrEmptyMarkdown, path)
+	}
+
+	// front matter followed by content is not empty
+	mp, err := NewMarkdownTemplatePage(template.Must(template.New("notes").Parse("---\ntitle: Notes\n---\n\nSome text.\n")), nil)
+	require.NoError(t, err)
+	mp.FailOnEmpty = true
+	assert.Contains(t, readMarkdownHTML(t, mp), "<p>Some text.</p>")
+}
+
+func TestMarkdownPageLinkTargets(t *testing.T) {
+	html := readMarkdownHTML(t, NewMarkdownPage("testdata/anchors.md"))
+	assert.Contains(t, html, `<a href="#installation">Installation</a>`)
+	assert.Contains(t, html, `<a href="#usage">Usage</a>`)
+	assert.Contains(t, html, `<a href="#contents">contents</a>`)
+	assert.Equal(t, 1, strings.Count(html, `target="_blank"`))
+	assert.Contains(t, html, `<a href="https://github.com/localrivet/gopdf/releases" target="_blank">Releases</a>`)
+
+	mp := NewMarkdownPage("testdata/anchors.md")
+	mp.NoTargetBlank = true
+	html = readMarkdownHTML(t, mp)
+	assert.NotContains(t, html, "target=")
+	assert.Contains(t, html, `<a href="https://github.com/localrivet/gopdf/releases">Releases</a>`)
+	assert.Contains(t, html, `<a href="#usage">Usage</a>`)
+}
+
+func TestMarkdownPageTableAlignment(t *testing.T) {
+	html := readMarkdownHTML(t, NewMarkdownPage("testdata/aligned.md"))
+	assert.Contains(t, html, "<tr>\n"+`<th align="left" style="text-align:left">Item</th>`+"\n")
+	assert.Contains(t, html, `<th align="center" style="text-align:center">Qty</th>`)
+	assert.Contains(t, html, `<th align="right" style="text-align:right">Amount</th>`)
+	assert.Contains(t, html, "<th>Note</th>\n</tr>")
+	assert.Contains(t, html, "<tr>\n"+`<td align="left" style="text-align:left">Rent</td>`+"\n")
+	assert.Contains(t, html, `<td align="center" style="text-align:center">12</td>`)
+	assert.Contains(t, html, `<td align="right" style="text-align:right">1,200.00</td>`)
+	assert.Contains(t, html, "<td></td>\n</tr>")
+	assert.Equal(t, 9, strings.Count(html, "text-align:"))
+}
+
+func TestMarkdownPageLinkRewriter(t *testing.T) {
+	var hrefs []string
+	mp := NewMarkdownPage("testdata/crosslinks.md")
+	mp.LinkRewriter = func(href string) string {
+		hrefs = append(hrefs, href)
+		path, fragment, _ := strings.Cut(href, "#")
+		if strings.HasPrefix(href, "#") || !strings.HasSuffix(path, ".md") || strings.Contains(path, "://") {
+			return href
+		}
+		if fragment != "" {
+			return "#" + fragment
+		}
+		return "#" + SlugifyHeading(strings.TrimSuffix(filepath.Base(path), ".md"))
+	}
+	html := readMarkdownHTML(t, mp)
+	assert.Equal(t, []string{"install.md", "usage.md#running", "./faq.md", "#guide", "https://github.com/localrivet/gopdf/blob/main/README.md"}, hrefs)
+	assert.Contains(t, html, `<a href="#install">the installation</a>`)
+	assert.Contains(t, html, `<a href="#running">the usage</a>`)
+	assert.Contains(t, html, `<a href="#faq">the FAQ</a>`)
+	assert.Contains(t, html, `<a href="#guide">the overview</a>`)
+	assert.Contains(t, html, `<a href="https://github.com/localrivet/gopdf/blob/main/README.md" target="_blank">the project</a>`)
+	assert.Contains(t, html, `<h1 id="install">Install</h1>`)
+
+	// without a rewriter the links are kept
+	html = readMarkdownHTML(t, NewMarkdownPage("testdata/crosslinks.md"))
+	assert.Contains(t, html, `<a href="install.md" target="_blank">the installation</a>`)
+}
+
+func TestMarkdownPageStripComments(t *testing.T) {
+	html := readMarkdownHTML(t, NewMarkdownPage("testdata/comments.md"))
+	assert.Contains(t, html, "<!-- TODO: add the migration guide -->")
+
+	mp := NewMarkdownPage("testdata/comments.md")
+	mp.StripComments = true
+	mp.KeepComments = []string{"pagebreak"}
+	html = readMarkdownHTML(t, mp)
+	assert.NotContains(t, html, "TODO")
+	assert.NotContains(t, html, "benchmark")
+	assert.NotContains(t, html, "Draft section")
+	assert.NotContains(t, html, "internal")
+	assert.Contains(t, html, "<p>The new version is faster. It also uses less memory.</p>")
+	assert.Contains(t, html, "<!-- pagebreak -->")
+	assert.Contains(t, html, `<div class="note">Keep the div.</div>`)
+
+	// comments in code are kept
+	assert.Contains(t, html, "<code>&lt;!-- note --&gt;</code>")
+	assert.Contains(t, html, "&lt;!-- this comment is part of the example --&gt;")
+	assert.Contains(t, html, "&lt;!-- indented code keeps its comment too --&gt;")
+	assert.Equal(t, 4, strings.Count(html, "&lt;!--")+strings.Count(html, "<!--"))
+}
+
+func TestMarkdownPageTitle(t *testing.T) {
+	// the front matter title is used by default
+	mp := NewMarkdownPage("testdata/titled.md")
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title>The Handbook: 2nd Edition</title>")
+
+	mp = NewMarkdownPage("testdata/titled.md")
+	mp.TitleSource = TitleFromH1
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title>Getting started</title>")
+
+	mp = NewMarkdownPage("testdata/titled.md")
+	mp.TitleSource = TitleNone
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title></title>")
+
+	// without front matter the first H1 is used, unless only the front matter is selected
+	mp = NewMarkdownPage("testdata/anchors.md")
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title>Contents</title>")
+	mp = NewMarkdownPage("testdata/anchors.md")
+	mp.TitleSource = TitleFromFrontMatter
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title></title>")
+
+	// Title overrides the detection and is escaped
+	mp = NewMarkdownPage("testdata/titled.md")
+	mp.Title = "Q&A <draft>"
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title>Q&amp;A &lt;draft&gt;</title>")
+
+	// wkhtmltopdf replaces [doctitle] in headers and footers with the title of the first page
+	pdfg := NewPDFPreparer()
+	page := NewMarkdownPage("testdata/titled.md")
+	page.FooterCenter.Set("[doctitle]")
+	pdfg.AddPage(page)
+	assert.Contains(t, pdfg.ArgString(), "--footer-center [doctitle]")
+	assert.Contains(t, readMarkdownHTML(t, page), "<title>The Handbook: 2nd Edition</title>")
+}
+
+func TestMarkdownPageTitleSkipFirstH1H2(t *testing.T) {
+	// the H1 moved to the cover with SkipFirstH1H2 is still the title, although the body has no H1 left
+	mp := NewMarkdownPage("testdata/testmd.md")
+	mp.SkipFirstH1H2 = true
+	html := readMarkdownHTML(t, mp)
+	assert.NotContains(t, html, "<h1>Unlock Precision DNA Collection")
+	assert.Contains(t, html, "<title>Unlock Precision DNA Collection with M-Vac™ Technology</title>")
+
+	mp = NewMarkdownPage("testdata/testmd.md")
+	mp.SkipFirstH1H2 = true
+	mp.TitleSource = TitleFromH1
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title>Unlock Precision DNA Collection with M-Vac™ Technology</title>")
+
+	mp = NewMarkdownPage("testdata/testmd.md")
+	mp.SkipFirstH1H2 = true
+	mp.TitleSource = TitleNone
+	assert.Contains(t, readMarkdownHTML(t, mp), "<title></title>")
+}
+
+func TestFrontMatterTitle(t *testing.T) {
+	tests := map[string]string{
+		"---\ntitle: Plain\n---\n":                  "Plain",
+		"---\ntitle: 'It''s quoted'\n---\n":         "It's quoted",
+		"---\ntitle: \"Tab\\there\"\n---\n":         "Tab\there",
+		"---\ntitle: Commented # not part\n---\n":   "Commented",
+		"---\r\ntitle: Windows  \r\n---\r\n":        "Windows",
+		"---\nsubtitle: No\n  title: nested\n---\n": "",
+		"title: Not front matter\n":                 "",
+	}
+	for md, want := range tests {
+		assert.Equal(t, want, frontMatterTitle([]byte(md)), md)
+	}
+}
+
+func TestMarkdownChapter(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetMarkdownChapters(true)
+	pdfg.AddPage(NewMarkdownPage("testdata/mdchapters/intro.md"))
+	pdfg.AddPage(NewMarkdownPage("testdata/mdchapters/setup.md"))
+
+	tests := []struct {
+		chapter  string
+		headings []string
+	}{
+		{"Introduction", []string{`<h3 id="purpose">Purpose</h3>`, `<h3 id="audience">Audience</h3>`}},
+		{"setup", []string{`<h2 id="installing">Installing</h2>`, `<h3 id="requirements">Requirements</h3>`}},
+	}
+	for i, tt := range tests {
+		b, err := io.ReadAll(pdfg.pages[i].Reader())
+		require.NoError(t, err)
+		html := string(b)
+		heading := `<body><h1 class="markdown-chapter" style="` + chapterHeadingStyle + `">` + tt.chapter + "</h1>"
+		assert.Contains(t, html, heading)
+		for _, h := range tt.headings {
+			assert.Contains(t, html, h)
+		}
+	}
+
+	// pages added before the setting, or with it turned off, are not chapters
+	page := NewMarkdownPage("testdata/mdchapters/setup.md")
+	pdfg.SetMarkdownChapters(false)
+	pdfg.AddPage(page)
+	b, err := io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	assert.NotContains(t, string(b), "markdown-chapter")
+	assert.Contains(t, string(b), `<h1 id="installing">Installing</h1>`)
+}
+
+func TestMarkdownChapterLabel(t *testing.T) {
+	page := NewMarkdownPage("testdata/mdchapters/setup.md")
+	page.Title = `Setup & "Install"`
+	page.Chapter = true
+	b, err := io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `">Setup &amp; &#34;Install&#34;</h1>`)
+
+	// without a file, the title found in the Markdown is used
+	page, err = NewMarkdownTemplatePage(template.Must(template.New("notes").Parse("# {{.}}\n\n###### Deep")), "Release notes")
+	require.NoError(t, err)
+	page.Chapter = true
+	b, err = io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `">Release notes</h1>`)
+	assert.Contains(t, string(b), `<h6 id="deep">Deep</h6>`)
+}
+
+func TestMarkdownChapterOutline(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	if err != nil {
+		t.Skip("wkhtmltopdf not found")
+	}
+	pdfg.SetMarkdownChapters(true)
+	pdfg.AddPage(NewMarkdownPage("testdata/mdchapters/intro.md"))
+	pdfg.AddPage(NewMarkdownPage("testdata/mdchapters/setup.md"))
+	require.NoError(t, pdfg.Create())
+
+	var chapters []string
+	for _, line := range testOutline(t, pdfg.Bytes()) {
+		if !strings.HasPrefix(line, " ") {
+			chapters = append(chapters, line)
+		}
+	}
+	if len(chapters) == 0 {
+		t.Skip("this wkhtmltopdf doesn't write an outline")
+	}
+	require.Len(t, chapters, 2)
+	// wkhtmltopdf writes the titles in UTF-16
+	assert.Contains(t, strings.ReplaceAll(chapters[0], "\x00", ""), "Introduction")
+	assert.Contains(t, strings.ReplaceAll(chapters[1], "\x00", ""), "setup")
+}
+
+func TestMarkdownChaptersJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetMarkdownChapters(true)
+	pdfg.AddPage(NewMarkdownPage("testdata/mdchapters/intro.md"))
+	b, err := pdfg.ToJSON()
+	require.NoError(t, err)
+
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	restored.AddPage(NewMarkdownPage("testdata/mdchapters/setup.md"))
+	for _, page := range restored.pages {
+		assert.True(t, page.(*MarkdownPage).Chapter)
+	}
+}
+
+func TestMarkdownParseFrontmatter(t *testing.T) {
+	page := NewMarkdownPage("testdata/frontmatter.md")
+	page.ParseFrontmatter = true
+	b, err := io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	html := string(b)
+	assert.NotContains(t, html, "Docs Team")
+	assert.NotContains(t, html, "<hr")
+	assert.Contains(t, html, "<title>Release Notes</title>")
+	assert.Contains(t, html, `<h1 id="version-2-0">Version 2.0</h1>`)
+	assert.Equal(t, "Release Notes", page.Frontmatter["title"])
+	assert.Equal(t, "Docs Team", page.Frontmatter["author"])
+	assert.Equal(t, []any{"release", "changelog"}, page.Frontmatter["keywords"])
+	assert.Contains(t, page.Frontmatter, "date")
+
+	// without ParseFrontmatter the front matter is converted as Markdown
+	page = NewMarkdownPage("testdata/frontmatter.md")
+	b, err = io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "Docs Team")
+	assert.Nil(t, page.Frontmatter)
+
+	// Markdown without front matter is unchanged
+	page = NewMarkdownPage("testdata/mdchapters/setup.md")
+	page.ParseFrontmatter = true
+	b, err = io.ReadAll(page.Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), `<h1 id="installing">Installing</h1>`)
+	assert.Nil(t, page.Frontmatter)
+}
+
+func TestMarkdownParseFrontmatterErrors(t *testing.T) {
+	page := NewMarkdownPage("testdata/frontmatterunclosed.md")
+	page.ParseFrontmatter = true
+	_, err := io.ReadAll(page.Reader())
+	assert.ErrorIs(t, err, ErrInvalidFrontmatter)
+	assert.EqualError(t, err, "invalid front matter in testdata/frontmatterunclosed.md: the front matter has no closing --- line")
+	assert.Nil(t, page.Frontmatter)
+
+	page, err = NewMarkdownTemplatePage(template.Must(template.New("list").Parse("---\n- a\n- b\n---\n\nText\n")), nil)
+	require.NoError(t, err)
+	page.ParseFrontmatter = true
+	_, err = io.ReadAll(page.Reader())
+	assert.ErrorIs(t, err, ErrInvalidFrontmatter)
+	assert.ErrorContains(t, err, "invalid front matter in template: ")
+}
@@ -0,0 +1,154 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// MarkdownDirOption is an option for RenderMarkdownDir
+type MarkdownDirOption func(*markdownDirConfig)
+
+type markdownDirConfig struct {
+	recursive bool
+	includes  bool
+	excludes  []string
+	configure []func(*PDFGenerator) error
+}
+
+// MarkdownDirRecursive makes RenderMarkdownDir include the Markdown files in subdirectories
+func MarkdownDirRecursive() MarkdownDirOption {
+	return func(c *markdownDirConfig) { c.recursive = true }
+}
+
+// MarkdownDirExpandIncludes makes RenderMarkdownDir expand include lines in the Markdown files, like
+// MarkdownPage.ExpandIncludes. Only files in the directory and its subdirectories can be included.
+func MarkdownDirExpandIncludes() MarkdownDirOption {
+	return func(c *markdownDirConfig) { c.includes = true }
+}
+
+// MarkdownDirExclude makes RenderMarkdownDir skip files matching pattern (see path.Match). The pattern is matched
+// against the file name and against the path relative to the directory with forward slashes, like "drafts/*.md".
+// A directory matching the pattern is skipped completely.
+func MarkdownDirExclude(pattern string) MarkdownDirOption {
+	return func(c *markdownDirConfig) { c.excludes = append(c.excludes, pattern) }
+}
+
+// MarkdownDirConfigure makes RenderMarkdownDir call configure with the generator before rendering, to set options
+// like the page size, a cover or a style sheet.
+func MarkdownDirConfigure(configure func(*PDFGenerator) error) MarkdownDirOption {
+	return func(c *markdownDirConfig) { c.configure = append(c.configure, configure) }
+}
+
+// markdownPageBreak is put between the files RenderMarkdownDir combines, so each file starts on a new page
+const markdownPageBreak = "\n\n<div style=\"page-break-before: always\"></div>\n\n"
+
+// RenderMarkdownDir renders all Markdown files (*.md) in dir into a single PDF at outPath, with a table of contents.
+// The files are ordered by their path relative to dir, compared byte by byte, so a numeric prefix controls the
+// order as long as all prefixes have the same number of digits (01-intro.md, 02-setup.md, ..., 10-faq.md). With
+// MarkdownDirRecursive, files in subdirectories are included and ordered by their path including the directory,
+// so "02-guide/01-start.md" comes after "01-intro.md" and before "03-faq.md".
+// The files are combined into one Markdown document with a page break between files, so heading anchors are
+// unique across all files (duplicates get a suffix like "-1", see HeadingSlugger) and the TOC covers all files.
+// An error is returned if no Markdown file is found.
+func RenderMarkdownDir(dir string, outPath string, opts ...MarkdownDirOption) error {
+	var cfg markdownDirConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	page, err := markdownDirPage(dir, &cfg)
+	if err != nil {
+		return err
+	}
+
+	pdfg, err := NewPDFGenerator()
+	if err != nil {
+		return err
+	}
+	pdfg.TOC.Include = true
+	for _, configure := range cfg.configure {
+		if err := configure(pdfg); err != nil {
+			return err
+		}
+	}
+	pdfg.AddPage(page)
+	if err := pdfg.Create(); err != nil {
+		return err
+	}
+	return pdfg.WriteFile(outPath)
+}
+
+// markdownDirPage returns a MarkdownPage with the combined Markdown files of dir
+func markdownDirPage(dir string, cfg *markdownDirConfig) (*MarkdownPage, error) {
+	files, err := markdownDirFiles(dir, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Markdown files found in %s", dir)
+	}
+
+	var md bytes.Buffer
+	for i, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading markdown file: %w", err)
+		}
+		// includes are relative to each file, the combined page has no directory of its own
+		if cfg.includes {
+			b, err = expandIncludes(b, filepath.Dir(path), includeRoot(dir), []string{path})
+			if err != nil {
+				return nil, err
+			}
+		}
+		if i > 0 {
+			md.WriteString(markdownPageBreak)
+		}
+		md.Write(b)
+	}
+	mp := NewMarkdownPage("")
+	mp.source = md.Bytes()
+	return mp, nil
+}
+
+// markdownDirFiles returns the sorted paths of the Markdown files in dir, relative to dir with forward slashes
+func markdownDirFiles(dir string, cfg *markdownDirConfig) ([]string, error) {
+	excluded := func(rel string) bool {
+		for _, pattern := range cfg.excludes {
+			if ok, _ := path.Match(pattern, rel); ok {
+				return true
+			}
+			if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	var files []string
+	err := fs.WalkDir(os.DirFS(dir), ".", func(rel string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if rel != "." && (!cfg.recursive || excluded(rel)) {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if path.Ext(rel) == ".md" && !excluded(rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing markdown files: %w", err)
+	}
+	slices.Sort(files)
+	return files, nil
+}
@@ -0,0 +1,92 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownDirFiles(t *testing.T) {
+	files, err := markdownDirFiles("testdata/mddir", &markdownDirConfig{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01-intro.md", "03-faq.md", "draft-notes.md"}, files)
+
+	cfg := &markdownDirConfig{}
+	MarkdownDirRecursive()(cfg)
+	MarkdownDirExclude("draft-*")(cfg)
+	files, err = markdownDirFiles("testdata/mddir", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01-intro.md", "02-guide/01-install.md", "03-faq.md"}, files)
+
+	// excluding a directory skips all files in it
+	MarkdownDirExclude("02-guide")(cfg)
+	files, err = markdownDirFiles("testdata/mddir", cfg)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"01-intro.md", "03-faq.md"}, files)
+}
+
+func TestMarkdownDirPage(t *testing.T) {
+	cfg := &markdownDirConfig{}
+	MarkdownDirRecursive()(cfg)
+	MarkdownDirExclude("draft-*")(cfg)
+	mp, err := markdownDirPage("testdata/mddir", cfg)
+	require.NoError(t, err)
+	html := readMarkdownHTML(t, mp)
+
+	// the files are in order, with a page break between them
+	intro := strings.Index(html, `id="introduction"`)
+	install := strings.Index(html, `id="installation"`)
+	faq := strings.Index(html, `id="faq"`)
+	assert.True(t, intro >= 0 && intro < install && install < faq, html)
+	assert.Equal(t, 2, strings.Count(html, "page-break-before: always"))
+
+	// the same heading in different files gets unique anchors
+	assert.Contains(t, html, `id="overview"`)
+	assert.Contains(t, html, `id="overview-1"`)
+	assert.Contains(t, html, `id="overview-2"`)
+
+	_, err = markdownDirPage(t.TempDir(), &markdownDirConfig{})
+	assert.ErrorContains(t, err, "no Markdown files found in ")
+}
+
+func TestRenderMarkdownDir(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "docs.pdf")
+	var pdfg *PDFGenerator
+	err := RenderMarkdownDir("testdata/mddir", out, MarkdownDirConfigure(func(g *PDFGenerator) error {
+		pdfg = g
+		g.PageSize.Set(PageSizeA4)
+		return nil
+	}))
+	require.NoError(t, err)
+	assert.Contains(t, pdfg.ArgString(), "--page-size A4 toc page -")
+
+	b, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(b, []byte("%PDF-")))
+}
+
+func TestMarkdownDirPageIncludes(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "01-doc.md"), []byte("# Doc\n\n!include parts/part.md\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "parts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "parts", "part.md"), []byte("Included part.\n"), 0644))
+
+	mp, err := markdownDirPage(dir, &markdownDirConfig{})
+	require.NoError(t, err)
+	assert.Contains(t, readMarkdownHTML(t, mp), "!include parts/part.md")
+
+	cfg := &markdownDirConfig{}
+	MarkdownDirExpandIncludes()(cfg)
+	mp, err = markdownDirPage(dir, cfg)
+	require.NoError(t, err)
+	assert.Contains(t, readMarkdownHTML(t, mp), "<p>Included part.</p>")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "02-escape.md"), []byte("!include ../other.md\n"), 0644))
+	_, err = markdownDirPage(dir, cfg)
+	assert.ErrorContains(t, err, "markdown include ../other.md is outside of the directory")
+}
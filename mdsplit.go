@@ -0,0 +1,101 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// SplitMarkdownOption is an option for SplitMarkdownByHeading
+type SplitMarkdownOption func(*splitMarkdownConfig)
+
+type splitMarkdownConfig struct {
+	repeatFrontMatter bool
+}
+
+// SplitMarkdownRepeatFrontMatter makes SplitMarkdownByHeading put the front matter at the start of each chunk,
+// instead of only the first one, so each chapter keeps metadata like the title of the document
+func SplitMarkdownRepeatFrontMatter() SplitMarkdownOption {
+	return func(c *splitMarkdownConfig) { c.repeatFrontMatter = true }
+}
+
+var (
+	atxHeadingRegex    = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]|$)`)
+	setextHeadingRegex = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+	paragraphEndRegex  = regexp.MustCompile(`^ {0,3}(?:[-+*>]|\d+[.)])(?:[ \t]|$)|^(?: {4}|\t)`)
+)
+
+// SplitMarkdownByHeading splits Markdown into chunks which each start with a heading of the given level or a
+// higher one, like the chapters of a long document for level 1, to render each chunk as its own PDF (for example
+// by writing each chunk to a file for NewMarkdownPage, with a BatchJob per chunk). Both # headings and headings underlined with = or - are
+// recognized; lines in fenced code blocks are not. Content before the first heading, like an introduction, is a
+// chunk of its own, unless it is only whitespace. A YAML front matter block (between two "---" lines at the
+// start) is put at the start of the first chunk, or of each chunk with SplitMarkdownRepeatFrontMatter. The level
+// is limited to 1 to 6. Markdown without such headings is returned as one chunk, and nil is returned if there is
+// nothing but whitespace after the front matter. The chunks are copies, they can be changed without changing src.
+func SplitMarkdownByHeading(src []byte, level int, opts ...SplitMarkdownOption) [][]byte {
+	var cfg splitMarkdownConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	level = min(max(level, 1), 6)
+	frontMatter := frontMatterRegex.Find(src)
+	body := src[len(frontMatter):]
+
+	// find the offsets in body where the chunks start
+	starts := []int{0}
+	var fence string
+	offset, paraStart := 0, -1
+	for _, line := range bytes.SplitAfter(body, []byte("\n")) {
+		text := bytes.TrimRight(line, "\r\n")
+		lineStart := offset
+		offset += len(line)
+		if m := fenceRegex.FindSubmatch(text); m != nil {
+			switch fence {
+			case "":
+				fence = string(m[1])
+			case string(m[1]):
+				fence = ""
+			}
+			paraStart = -1
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		if m := atxHeadingRegex.FindSubmatch(text); m != nil {
+			if len(m[1]) <= level {
+				starts = append(starts, lineStart)
+			}
+			paraStart = -1
+			continue
+		}
+		if m := setextHeadingRegex.FindSubmatch(text); m != nil && paraStart >= 0 {
+			if m[1][0] == '=' || level >= 2 {
+				starts = append(starts, paraStart)
+			}
+			paraStart = -1
+			continue
+		}
+		switch {
+		case len(bytes.TrimSpace(text)) == 0 || paragraphEndRegex.Match(text):
+			paraStart = -1
+		case paraStart < 0:
+			paraStart = lineStart
+		}
+	}
+
+	var chunks [][]byte
+	starts = append(starts, len(body))
+	for i := range len(starts) - 1 {
+		content := body[starts[i]:starts[i+1]]
+		if len(bytes.TrimSpace(content)) == 0 {
+			continue
+		}
+		var chunk []byte
+		if len(chunks) == 0 || cfg.repeatFrontMatter {
+			chunk = append(chunk, frontMatter...)
+		}
+		chunks = append(chunks, append(chunk, content...))
+	}
+	return chunks
+}
@@ -0,0 +1,76 @@
+package wkhtmltopdf
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMarkdownByHeading(t *testing.T) {
+	src, err := os.ReadFile("testdata/chapters.md")
+	require.NoError(t, err)
+	frontMatter := "---\ntitle: The Handbook\nauthor: Docs Team\n---\n"
+
+	chunks := SplitMarkdownByHeading(src, 1)
+	require.Len(t, chunks, 4)
+	assert.Equal(t, frontMatter+"\nThis handbook has three chapters.\n\n", string(chunks[0]))
+	assert.True(t, strings.HasPrefix(string(chunks[1]), "# Getting Started\n"))
+	assert.Contains(t, string(chunks[1]), "## Requirements")
+	assert.Contains(t, string(chunks[1]), "# this is a shell comment, not a heading")
+	assert.True(t, strings.HasPrefix(string(chunks[2]), "# Configuration\n"))
+	assert.Contains(t, string(chunks[2]), "Options\n-------\n")
+	assert.Equal(t, "Troubleshooting\n===============\n\nRun with `-v` for details.\n", string(chunks[3]))
+	assert.Equal(t, string(src), strings.Join(bytesToStrings(chunks), ""))
+
+	// level 2 also splits at the H2 headings
+	chunks = SplitMarkdownByHeading(src, 2)
+	require.Len(t, chunks, 6)
+	assert.True(t, strings.HasPrefix(string(chunks[2]), "## Requirements\n"))
+	assert.True(t, strings.HasPrefix(string(chunks[4]), "Options\n-------\n"))
+
+	// the front matter can be repeated
+	chunks = SplitMarkdownByHeading(src, 1, SplitMarkdownRepeatFrontMatter())
+	require.Len(t, chunks, 4)
+	for _, chunk := range chunks {
+		assert.True(t, strings.HasPrefix(string(chunk), frontMatter))
+	}
+	assert.Equal(t, frontMatter+"# Configuration\n", string(chunks[2][:len(frontMatter)+len("# Configuration\n")]))
+
+	// the chunks are copies
+	chunks = SplitMarkdownByHeading(src, 1)
+	chunks[1][0] = '!'
+	assert.Equal(t, byte('#'), src[strings.Index(string(src), "# Getting Started")])
+}
+
+func TestSplitMarkdownByHeadingEdgeCases(t *testing.T) {
+	assert.Nil(t, SplitMarkdownByHeading([]byte(" \n\n"), 1))
+	assert.Nil(t, SplitMarkdownByHeading([]byte("---\ntitle: x\n---\n\n"), 1))
+
+	// without headings the content is one chunk, the front matter is kept
+	chunks := SplitMarkdownByHeading([]byte("---\ntitle: x\n---\nJust text.\n"), 1)
+	assert.Equal(t, []string{"---\ntitle: x\n---\nJust text.\n"}, bytesToStrings(chunks))
+
+	// the front matter goes to the first heading when there is nothing before it
+	chunks = SplitMarkdownByHeading([]byte("---\ntitle: x\n---\n# One\n\n# Two\n"), 1)
+	assert.Equal(t, []string{"---\ntitle: x\n---\n# One\n\n", "# Two\n"}, bytesToStrings(chunks))
+
+	// a line of dashes after a blank line is a horizontal rule, #hashtags are not headings
+	chunks = SplitMarkdownByHeading([]byte("# One\n\n---\n\n#hashtag\n- item\n---\n"), 2)
+	assert.Len(t, chunks, 1)
+
+	// the level is limited to 1 to 6
+	assert.Len(t, SplitMarkdownByHeading([]byte("# One\n## Two\n"), 0), 1)
+	assert.Len(t, SplitMarkdownByHeading([]byte("# One\n###### Six\n"), 9), 2)
+}
+
+// bytesToStrings converts chunks to strings for comparisons
+func bytesToStrings(chunks [][]byte) []string {
+	s := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		s[i] = string(chunk)
+	}
+	return s
+}
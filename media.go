@@ -0,0 +1,26 @@
+package wkhtmltopdf
+
+// MediaTargetCSS is the CSS which SetPrintMediaType injects in every page. Elements with a data-pdf-only
+// attribute are only shown in the PDF, elements with a data-screen-only attribute are only shown on screen.
+// Add the same rules to the style sheet of the website which shares the HTML, so pdf-only content is hidden there.
+const MediaTargetCSS = `@media screen { [data-pdf-only] { display: none !important; } }
+@media print { [data-screen-only] { display: none !important; } }
+`
+
+// SetPrintMediaType renders pages added after this call with the print media type (--print-media-type), so
+// @media print rules apply instead of @media screen rules, and injects MediaTargetCSS in these pages.
+// This way one HTML source can contain content for the PDF only (data-pdf-only) and for screen only
+// (data-screen-only). The cover and the TOC are not changed.
+func (pdfg *PDFGenerator) SetPrintMediaType(printMediaType bool) {
+	pdfg.printMediaType = printMediaType
+}
+
+// injectedCSS returns the CSS which is injected in the page, see SetInlineCSS, SetPrintMediaType and
+// SetFontFallback. The font stack comes first, so the inline CSS can override it.
+func (po *PageOptions) injectedCSS() string {
+	css := fontFallbackCSS(po.fontFallback)
+	if po.mediaTargetCSS {
+		css += MediaTargetCSS
+	}
+	return css + po.inlineCSS
+}
@@ -0,0 +1,49 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPrintMediaType(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.SetPrintMediaType(true)
+	page := NewMarkdownPage("testdata/mediatarget.md")
+	page.SetInlineCSS("body { color: red; }")
+	pdfg.AddPage(page)
+
+	// only the page added after SetPrintMediaType uses the print media type
+	assert.Equal(t, "page testdata/htmlsimple.html page - --print-media-type -", pdfg.ArgString())
+
+	r, err := stdinReader(page)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	html := string(b)
+	assert.Contains(t, html, "<style>"+MediaTargetCSS+"body { color: red; }</style></head>")
+	assert.Contains(t, html, "<div data-screen-only>")
+	assert.Contains(t, html, "<div data-pdf-only>")
+}
+
+func TestSetPrintMediaTypePage(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetPrintMediaType(true)
+	page := NewPage("testdata/htmlsimple.html")
+	pdfg.AddPage(page)
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	defer cleanup()
+
+	// pages read from a file get the CSS as user style sheet
+	css, err := os.ReadFile(page.UserStyleSheet.value)
+	require.NoError(t, err)
+	assert.Equal(t, MediaTargetCSS, string(css))
+	assert.Contains(t, pdfg.ArgString(), "--print-media-type")
+}
@@ -0,0 +1,86 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+)
+
+// RenderMetrics is the summary of one run which is written to the writer set with SetMetricsWriter,
+// as one JSON object per line
+type RenderMetrics struct {
+	Time       time.Time `json:"time"`            // When the run started
+	Pages      int       `json:"pages"`           // Number of input pages, without the cover and the TOC
+	BytesOut   int64     `json:"bytes_out"`       // Size of the PDF, after post-processing
+	DurationMS int64     `json:"duration_ms"`     // Duration of the whole run in milliseconds, including preparation and post-processing
+	ExitCode   int       `json:"exit_code"`       // Exit code of wkhtmltopdf, -1 if it did not run or did not exit normally
+	Warnings   int       `json:"warnings"`        // Number of warnings wkhtmltopdf printed on stderr
+	Version    string    `json:"version"`         // Version of wkhtmltopdf like "0.12.6 (with patched qt)", empty if unknown
+	Error      string    `json:"error,omitempty"` // The error returned by the run, if it failed
+}
+
+// SetMetricsWriter sets a writer which receives a RenderMetrics record as one line of JSON after every run of
+// Create, CreateContext, CreateWithResult and CreateAndTee, also if the run fails. It complements the events of
+// SetStderrHandler with a single summary per run for log aggregation. The version of wkhtmltopdf is detected
+// once per executable (see DetectVersion). Errors writing the record are ignored, so they don't fail the run.
+// Use nil to stop writing records.
+func (pdfg *PDFGenerator) SetMetricsWriter(w io.Writer) {
+	pdfg.metricsWriter = w
+}
+
+// renderWithMetrics renders the PDF and writes its RenderMetrics to the metrics writer
+func (pdfg *PDFGenerator) renderWithMetrics(ctx context.Context) (*RenderResult, error) {
+	start := time.Now()
+	var counter *countingWriter
+	if pdfg.outWriter != nil {
+		outWriter := pdfg.outWriter
+		counter = &countingWriter{w: outWriter}
+		pdfg.outWriter = counter
+		defer func() { pdfg.outWriter = outWriter }()
+	}
+
+	result, err := pdfg.render(ctx)
+
+	metrics := RenderMetrics{
+		Time:       start,
+		Pages:      len(pdfg.pages),
+		DurationMS: time.Since(start).Milliseconds(),
+		ExitCode:   -1,
+	}
+	switch {
+	case counter != nil:
+		metrics.BytesOut = counter.n
+	case pdfg.OutputFile != "":
+		if info, statErr := os.Stat(pdfg.OutputFile); statErr == nil && err == nil {
+			metrics.BytesOut = info.Size()
+		}
+	default:
+		metrics.BytesOut = int64(pdfg.outbuf.Len())
+	}
+	if result != nil {
+		metrics.ExitCode = result.ExitCode
+		metrics.Warnings = len(result.Warnings)
+	}
+	if v, versionErr := pdfg.DetectVersion(); versionErr == nil {
+		metrics.Version = v.String()
+	}
+	if err != nil {
+		metrics.Error = err.Error()
+	}
+	_ = json.NewEncoder(pdfg.metricsWriter).Encode(metrics)
+	return result, err
+}
+
+// countingWriter counts the bytes written to w
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,65 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsWriter(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	var metrics bytes.Buffer
+	pdfg.SetMetricsWriter(&metrics)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>Second</p>")))
+	require.NoError(t, pdfg.Create())
+
+	var m RenderMetrics
+	require.NoError(t, json.Unmarshal(metrics.Bytes(), &m))
+	assert.Equal(t, 2, m.Pages)
+	assert.Equal(t, int64(pdfg.Buffer().Len()), m.BytesOut)
+	assert.Equal(t, 0, m.ExitCode)
+	assert.NotEmpty(t, m.Version)
+	assert.Empty(t, m.Error)
+	assert.False(t, m.Time.IsZero())
+
+	// one line per run, for output to a writer and to a file
+	var out bytes.Buffer
+	pdfg.SetOutput(&out)
+	require.NoError(t, pdfg.Create())
+	pdfg.SetOutput(nil)
+	pdfg.OutputFile = filepath.Join(t.TempDir(), "out.pdf")
+	require.NoError(t, pdfg.Create())
+	lines := strings.Split(strings.TrimSpace(metrics.String()), "\n")
+	require.Len(t, lines, 3)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &m))
+	assert.Equal(t, int64(out.Len()), m.BytesOut)
+	info, err := os.Stat(pdfg.OutputFile)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &m))
+	assert.Equal(t, info.Size(), m.BytesOut)
+}
+
+func TestMetricsWriterError(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	var metrics bytes.Buffer
+	pdfg.SetMetricsWriter(&metrics)
+	pdfg.AddPage(NewPage("testdata/missing.html"))
+	require.Error(t, pdfg.Create())
+
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(metrics.Bytes(), &m))
+	assert.Equal(t, float64(-1), m["exit_code"])
+	assert.Contains(t, m["error"], "missing.html")
+	for _, key := range []string{"time", "pages", "bytes_out", "duration_ms", "warnings", "version"} {
+		assert.Contains(t, m, key)
+	}
+}
@@ -0,0 +1,96 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// SetPageNumberOffset sets the number added to the page numbers shown in headers and footers ([page] and
+// [topage] in text headers and footers, and the page and topage parameters of HTML headers and footers).
+// Unlike the PageOffset page option, the offset can be negative. It is passed to wkhtmltopdf as --page-offset,
+// so don't combine it with PageOffset on the pages.
+func (pdfg *PDFGenerator) SetPageNumberOffset(offset int) {
+	pdfg.pageNumberOffset = offset
+}
+
+// SetExcludeCoverFromNumbering makes the page numbers in headers and footers start after the cover page(s), so
+// the first page after the cover is page 1 (plus the offset set with SetPageNumberOffset).
+// wkhtmltopdf numbers all pages of the document including the cover, and there is no way to renumber the
+// pages afterwards because the numbers are rendered as text. Instead, the cover is rendered separately
+// before the document to count its pages, which are then subtracted with --page-offset. This costs an
+// extra wkhtmltopdf run when a cover is set.
+// The length of the TOC depends on the rest of the document and can't be excluded this way; if the length of
+// the TOC is known, SetPageNumberOffset can be used to subtract it.
+func (pdfg *PDFGenerator) SetExcludeCoverFromNumbering(exclude bool) {
+	pdfg.excludeCover = exclude
+}
+
+// pageNumberArgs returns the --page-offset argument for SetPageNumberOffset and SetExcludeCoverFromNumbering
+func (pdfg *PDFGenerator) pageNumberArgs() []string {
+	offset := pdfg.pageNumberOffset - pdfg.coverPages
+	if offset == 0 {
+		return nil
+	}
+	return []string{opt + "page-offset", strconv.Itoa(offset)}
+}
+
+// countCoverPages renders the cover by itself to set the number of cover pages for SetExcludeCoverFromNumbering.
+// The returned function resets the count, it must always be called.
+func (pdfg *PDFGenerator) countCoverPages(ctx context.Context) (func(), error) {
+	reset := func() { pdfg.coverPages = 0 }
+	if !pdfg.excludeCover || pdfg.Cover.Input == "" {
+		return reset, nil
+	}
+
+	args := pdfg.countArgs()
+	args = append(args, "cover", pdfg.Cover.Input)
+	args = append(args, pdfg.Cover.pageOptions.Args()...)
+	args = append(args, "-")
+
+	pages, err := pdfg.renderPageCount(ctx, args, nil)
+	if err != nil {
+		return reset, fmt.Errorf("error counting cover pages: %w", err)
+	}
+	pdfg.coverPages = pages
+	return reset, nil
+}
+
+// countArgs returns the global arguments for the wkhtmltopdf runs which count pages, including the arguments added
+// with AddRawArg, so the pages are counted as they are rendered
+func (pdfg *PDFGenerator) countArgs() []string {
+	return append(append([]string{}, pdfg.globalOptions.Args()...), pdfg.rawArgs...)
+}
+
+// renderPageCount runs wkhtmltopdf with args and stdin as input and returns the number of pages of the PDF
+func (pdfg *PDFGenerator) renderPageCount(ctx context.Context, args []string, stdin []byte) (int, error) {
+	cmd := exec.CommandContext(ctx, pdfg.binPath, args...)
+	cmdConfig(cmd)
+	pdfg.applyLocale(cmd)
+	var out, errBuf bytes.Buffer
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = &out
+	cmd.Stderr = &errBuf
+	release, err := acquireRenderSlot(ctx)
+	if err != nil {
+		return 0, err
+	}
+	err = runCmd(cmd, pdfg.processPriority)
+	release()
+	if policyErr, applied := pdfg.applyExitCodePolicy(cmd, err, errBuf.String(), out.Bytes()); applied {
+		err = policyErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%w\n%s", err, errBuf.String())
+	}
+	doc, err := parsePDF(out.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	pages, err := doc.pages()
+	return len(pages), err
+}
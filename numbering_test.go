@@ -0,0 +1,93 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPageNumberOffset(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.NotContains(t, pdfg.ArgString(), "--page-offset")
+
+	pdfg.SetPageNumberOffset(-2)
+	assert.Contains(t, pdfg.ArgString(), "--page-offset -2 ")
+
+	pdfg.coverPages = 1
+	assert.Contains(t, pdfg.ArgString(), "--page-offset -3 ")
+
+	pdfg.SetPageNumberOffset(1)
+	assert.NotContains(t, pdfg.ArgString(), "--page-offset")
+}
+
+func TestSetExcludeCoverFromNumbering(t *testing.T) {
+	t.Setenv("FAKE_PAGES", "2")
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+
+	// without a cover there is nothing to count
+	pdfg.SetExcludeCoverFromNumbering(true)
+	reset, err := pdfg.countCoverPages(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, pdfg.coverPages)
+	reset()
+
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.SetPageNumberOffset(5)
+	reset, err = pdfg.countCoverPages(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, pdfg.coverPages)
+	assert.Contains(t, pdfg.ArgString(), "--page-offset 3 ")
+	reset()
+	assert.Equal(t, 0, pdfg.coverPages)
+
+	require.NoError(t, pdfg.Create())
+	assert.Equal(t, 0, pdfg.coverPages)
+}
+
+func TestPageNumberingJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.SetPageNumberOffset(-2)
+	pdfg.SetExcludeCoverFromNumbering(true)
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.Equal(t, -2, restored.pageNumberOffset)
+	assert.True(t, restored.excludeCover)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+}
+
+func TestCountArgsRawArgs(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "1.pdf"), newTestPDF(1), 0644))
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	script := "#!/bin/sh\necho \"$@\" > " + dir + "/args\ncat " + dir + "/1.pdf\n"
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.AddRawArg("--log-level", "warn")
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.SetExcludeCoverFromNumbering(true)
+	reset, err := pdfg.countCoverPages(context.Background())
+	require.NoError(t, err)
+	defer reset()
+	assert.Equal(t, 1, pdfg.coverPages)
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	require.NoError(t, err)
+	assert.Equal(t, "--log-level warn cover testdata/htmlsimple.html -\n", string(args))
+}
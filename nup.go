@@ -0,0 +1,262 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// NUpLayout is how NUp places the pages on the sheets
+type NUpLayout struct {
+	// Orientation is the orientation of the sheets, OrientationPortrait or OrientationLandscape. Empty picks the
+	// orientation in which the pages are scaled down the least, like landscape for 2 portrait pages per sheet.
+	Orientation string
+	// Gutter is the space between the pages on a sheet, a length like "5mm" (see ParseLength). Empty is no space.
+	Gutter string
+}
+
+// nUpConfig is the layout set with NUp
+type nUpConfig struct {
+	cols, rows int
+	layout     NUpLayout
+}
+
+// NUp places cols x rows pages of the PDF on each sheet, like 2 x 1 or 2 x 2 for handouts, see NUpPages.
+// It runs after the other built-in post-processing which changes pages (odd start and trimming blank pages), so
+// page labels and the viewer preferences apply to the sheets. NUp(1, 1, NUpLayout{}) turns it off.
+// An error is returned for less than 1 column or row, an unknown orientation or an invalid gutter, in which case
+// nothing is changed.
+func (pdfg *PDFGenerator) NUp(cols, rows int, layout NUpLayout) error {
+	if cols < 1 || rows < 1 {
+		return fmt.Errorf("invalid n-up layout %dx%d, there must be at least 1 column and row", cols, rows)
+	}
+	if _, err := layout.gutter(); err != nil {
+		return err
+	}
+	switch layout.Orientation {
+	case "", OrientationPortrait, OrientationLandscape:
+	default:
+		return fmt.Errorf("invalid n-up orientation %q, use %s or %s", layout.Orientation, OrientationPortrait, OrientationLandscape)
+	}
+	if cols == 1 && rows == 1 && layout == (NUpLayout{}) {
+		pdfg.nUp = nil
+		return nil
+	}
+	pdfg.nUp = &nUpConfig{cols: cols, rows: rows, layout: layout}
+	return nil
+}
+
+// gutter returns the gutter in points
+func (l NUpLayout) gutter() (float64, error) {
+	if l.Gutter == "" {
+		return 0, nil
+	}
+	gutter, err := ParseLength(l.Gutter)
+	if err != nil {
+		return 0, fmt.Errorf("invalid n-up gutter: %w", err)
+	}
+	return gutter.To(Point).Value, nil
+}
+
+// NUpPages returns a post-processor which places cols x rows pages on each sheet, from left to right and top to
+// bottom. The sheets have the size of the first page in the orientation of the layout. Each page is scaled to fit
+// its cell, keeping its aspect ratio, and centered in it; the gutter is the space between the cells. The last
+// sheet can have empty cells. Links, form fields and other annotations of the pages are removed, and the outline and
+// other destinations point to the sheet with the page.
+func NUpPages(cols, rows int, layout NUpLayout) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		if cols < 1 || rows < 1 {
+			return nil, fmt.Errorf("invalid n-up layout %dx%d, there must be at least 1 column and row", cols, rows)
+		}
+		gutter, err := layout.gutter()
+		if err != nil {
+			return nil, err
+		}
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			if len(pages) == 0 {
+				return nil
+			}
+			boxes := make([][4]float64, len(pages))
+			for i, ref := range pages {
+				if boxes[i], err = doc.pageBox(ref); err != nil {
+					return fmt.Errorf("page %d: %w", i+1, err)
+				}
+			}
+
+			// the sheet has the size of the first page, turned if needed
+			width, height := boxes[0][2]-boxes[0][0], boxes[0][3]-boxes[0][1]
+			var turn bool
+			switch layout.Orientation {
+			case OrientationLandscape:
+				turn = height > width
+			case OrientationPortrait:
+				turn = width > height
+			default:
+				turn = nUpScale(boxes[0], height, width, cols, rows, gutter) > nUpScale(boxes[0], width, height, cols, rows, gutter)
+			}
+			if turn {
+				width, height = height, width
+			}
+			cellWidth := (width - float64(cols-1)*gutter) / float64(cols)
+			cellHeight := (height - float64(rows-1)*gutter) / float64(rows)
+			if cellWidth <= 0 || cellHeight <= 0 {
+				return fmt.Errorf("n-up gutter %s is too large for %dx%d pages on a sheet", layout.Gutter, cols, rows)
+			}
+
+			perSheet := cols * rows
+			var sheets []pdfRef
+			sheetOf := make(map[int]pdfRef, len(pages))
+			for first := 0; first < len(pages); first += perSheet {
+				xObjects := newPDFDict()
+				var content strings.Builder
+				sheet := newPDFDict()
+				sheetRef := doc.add(sheet)
+				for i := first; i < len(pages) && i < first+perSheet; i++ {
+					form, err := doc.pageForm(pages[i], boxes[i])
+					if err != nil {
+						return fmt.Errorf("page %d: %w", i+1, err)
+					}
+					name := pdfName(fmt.Sprintf("P%d", i-first+1))
+					xObjects.Set(name, form)
+
+					box := boxes[i]
+					col, row := (i-first)%cols, (i-first)/cols
+					scale := min(cellWidth/(box[2]-box[0]), cellHeight/(box[3]-box[1]))
+					x := float64(col)*(cellWidth+gutter) + (cellWidth-(box[2]-box[0])*scale)/2 - box[0]*scale
+					y := height - float64(row+1)*cellHeight - float64(row)*gutter + (cellHeight-(box[3]-box[1])*scale)/2 - box[1]*scale
+					fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm /%s Do Q\n", pdfFloat(scale), pdfFloat(scale), pdfFloat(x), pdfFloat(y), name)
+					sheetOf[pages[i].num] = sheetRef
+				}
+				resources := newPDFDict()
+				resources.Set("XObject", xObjects)
+				sheet.Set("Type", pdfName("Page"))
+				sheet.Set("MediaBox", pdfArray{pdfInt(0), pdfInt(0), pdfFloat(width), pdfFloat(height)})
+				sheet.Set("Resources", resources)
+				sheet.Set("Contents", doc.add(newFlateStream(nil, []byte(content.String()))))
+				sheets = append(sheets, sheetRef)
+			}
+
+			// the destinations of the outline and links point to the pages, which are removed
+			for num, obj := range doc.objects {
+				doc.objects[num] = retargetDestinations(obj, sheetOf)
+			}
+			// the pages are removed with their contents and annotations, which are not written as nothing else uses
+			// them; the fields of a form are annotations too, so the form is removed as well
+			for _, ref := range pages {
+				delete(doc.objects, ref.num)
+			}
+			if cat, err := doc.catalog(); err == nil {
+				cat.Del("AcroForm")
+			}
+			return doc.setPages(sheets)
+		})
+	}
+}
+
+// nUpScale returns the factor a page with box is scaled by on a sheet of width x height
+func nUpScale(box [4]float64, width, height float64, cols, rows int, gutter float64) float64 {
+	cellWidth := (width - float64(cols-1)*gutter) / float64(cols)
+	cellHeight := (height - float64(rows-1)*gutter) / float64(rows)
+	return min(cellWidth/(box[2]-box[0]), cellHeight/(box[3]-box[1]))
+}
+
+// pageBox returns the visible area of a page, its CropBox or MediaBox, as x0, y0, x1, y1
+func (doc *pdfDocument) pageBox(ref pdfRef) ([4]float64, error) {
+	page := doc.dict(ref)
+	if page == nil {
+		return [4]float64{}, fmt.Errorf("PDF page %d not found", ref.num)
+	}
+	if rotate, _ := doc.intValue(doc.inheritedValue(page, "Rotate")); rotate%360 != 0 {
+		return [4]float64{}, errors.New("rotated pages are not supported")
+	}
+	obj := doc.inheritedValue(page, "CropBox")
+	if obj == nil {
+		obj = doc.inheritedValue(page, "MediaBox")
+	}
+	arr, _ := doc.resolve(obj).(pdfArray)
+	if len(arr) != 4 {
+		return [4]float64{}, errors.New("page has no valid MediaBox")
+	}
+	var box [4]float64
+	for i, v := range arr {
+		f, ok := doc.floatValue(v)
+		if !ok {
+			return [4]float64{}, errors.New("page has no valid MediaBox")
+		}
+		box[i] = f
+	}
+	box[0], box[2] = min(box[0], box[2]), max(box[0], box[2])
+	box[1], box[3] = min(box[1], box[3]), max(box[1], box[3])
+	if box[2] == box[0] || box[3] == box[1] {
+		return [4]float64{}, errors.New("page has an empty MediaBox")
+	}
+	return box, nil
+}
+
+// pageForm adds a form XObject with the content and resources of a page, clipped to box
+func (doc *pdfDocument) pageForm(ref pdfRef, box [4]float64) (pdfRef, error) {
+	page := doc.dict(ref)
+	var streams []pdfObject
+	switch contents := doc.resolve(page.Get("Contents")).(type) {
+	case nil:
+	case *pdfStream:
+		streams = append(streams, contents)
+	case pdfArray:
+		streams = contents
+	default:
+		return pdfRef{}, errors.New("invalid page contents")
+	}
+	var content []byte
+	for _, obj := range streams {
+		s, ok := doc.resolve(obj).(*pdfStream)
+		if !ok {
+			return pdfRef{}, errors.New("invalid page contents")
+		}
+		data, err := doc.decodeStream(s)
+		if err != nil {
+			return pdfRef{}, fmt.Errorf("error reading page contents: %w", err)
+		}
+		// the streams of a page are concatenated, separated by whitespace
+		content = append(append(content, data...), '\n')
+	}
+
+	form := newPDFDict()
+	form.Set("Type", pdfName("XObject"))
+	form.Set("Subtype", pdfName("Form"))
+	form.Set("BBox", pdfArray{pdfFloat(box[0]), pdfFloat(box[1]), pdfFloat(box[2]), pdfFloat(box[3])})
+	resources := doc.inheritedValue(page, "Resources")
+	if resources == nil {
+		resources = newPDFDict()
+	}
+	form.Set("Resources", resources)
+	return doc.add(newFlateStream(form, content)), nil
+}
+
+// retargetDestinations replaces destinations which point to a page in sheetOf, like [12 0 R /XYZ 0 800 0], by a
+// destination showing the whole sheet with the page
+func retargetDestinations(obj pdfObject, sheetOf map[int]pdfRef) pdfObject {
+	switch v := obj.(type) {
+	case pdfArray:
+		if len(v) >= 2 {
+			ref, isRef := v[0].(pdfRef)
+			_, isName := v[1].(pdfName)
+			if sheet, ok := sheetOf[ref.num]; isRef && isName && ok {
+				return pdfArray{sheet, pdfName("Fit")}
+			}
+		}
+		for i := range v {
+			v[i] = retargetDestinations(v[i], sheetOf)
+		}
+	case *pdfDict:
+		for _, key := range v.keys {
+			v.values[key] = retargetDestinations(v.values[key], sheetOf)
+		}
+	case *pdfStream:
+		retargetDestinations(v.dict, sheetOf)
+	}
+	return obj
+}
@@ -0,0 +1,167 @@
+package wkhtmltopdf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var nUpPlacementRegex = regexp.MustCompile(`q (\S+) 0 0 (\S+) (\S+) (\S+) cm /(P\d+) Do Q`)
+
+// nUpSheet is a sheet of an n-up PDF: its size, the scale and position of the pages on it and their contents
+type nUpSheet struct {
+	width, height float64
+	scale         []float64
+	x, y          []float64
+	pages         []string
+}
+
+// readNUpSheets returns the sheets of a PDF written by NUpPages
+func readNUpSheets(t *testing.T, pdf []byte) []nUpSheet {
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	refs, err := doc.pages()
+	require.NoError(t, err)
+	num := func(s string) float64 {
+		f, err := strconv.ParseFloat(s, 64)
+		require.NoError(t, err)
+		return f
+	}
+	var sheets []nUpSheet
+	for i, content := range testPDFPageTexts(t, pdf) {
+		page := doc.dict(refs[i])
+		box, err := doc.pageBox(refs[i])
+		require.NoError(t, err)
+		sheet := nUpSheet{width: box[2], height: box[3]}
+		xObjects := doc.dict(doc.dict(page.Get("Resources")).Get("XObject"))
+		for _, m := range nUpPlacementRegex.FindAllStringSubmatch(content, -1) {
+			assert.Equal(t, m[1], m[2])
+			sheet.scale = append(sheet.scale, num(m[1]))
+			sheet.x = append(sheet.x, num(m[3]))
+			sheet.y = append(sheet.y, num(m[4]))
+			form, ok := doc.resolve(xObjects.Get(pdfName(m[5]))).(*pdfStream)
+			require.True(t, ok)
+			assert.Equal(t, pdfName("Form"), form.dict.Get("Subtype"))
+			data, err := doc.decodeStream(form)
+			require.NoError(t, err)
+			sheet.pages = append(sheet.pages, string(data))
+		}
+		sheets = append(sheets, sheet)
+	}
+	return sheets
+}
+
+func TestNUpPages(t *testing.T) {
+	// 2 portrait pages fit best on a landscape sheet
+	pdf, err := NUpPages(2, 1, NUpLayout{})(newTestPDF(5))
+	require.NoError(t, err)
+	sheets := readNUpSheets(t, pdf)
+	require.Len(t, sheets, 3)
+	assert.Equal(t, 842.0, sheets[0].width)
+	assert.Equal(t, 595.0, sheets[0].height)
+	assert.Equal(t, []string{testPageContent(1) + "\n", testPageContent(2) + "\n"}, sheets[0].pages)
+	assert.Equal(t, []string{testPageContent(5) + "\n"}, sheets[2].pages)
+	scale := sheets[0].scale[0]
+	assert.InDelta(t, 595.0/842, scale, 1e-9)
+	assert.InDelta(t, (421-595*scale)/2, sheets[0].x[0], 1e-9)
+	assert.InDelta(t, 421+(421-595*scale)/2, sheets[0].x[1], 1e-9)
+	assert.InDelta(t, 0, sheets[0].y[0], 1e-9)
+
+	// 4 pages on a portrait sheet, the first row is at the top, with a gutter of 20pt between the cells
+	pdf, err = NUpPages(2, 2, NUpLayout{Gutter: "20pt"})(newTestPDF(5))
+	require.NoError(t, err)
+	sheets = readNUpSheets(t, pdf)
+	require.Len(t, sheets, 2)
+	assert.Equal(t, 595.0, sheets[0].width)
+	require.Len(t, sheets[0].pages, 4)
+	assert.Equal(t, testPageContent(3)+"\n", sheets[0].pages[2])
+	scale = sheets[0].scale[0]
+	assert.InDelta(t, (595.0-20)/2/595, scale, 1e-9)
+	assert.InDelta(t, 0, sheets[0].x[0], 1e-9)
+	assert.InDelta(t, (595.0-20)/2+20, sheets[0].x[1], 1e-9)
+	assert.InDelta(t, (842.0-20)/2+20, sheets[0].y[0]-sheets[0].y[2], 1e-9)
+	assert.Less(t, sheets[0].y[2]+842*scale, sheets[0].y[0])
+
+	// the orientation can be set
+	pdf, err = NUpPages(2, 1, NUpLayout{Orientation: OrientationPortrait})(newTestPDF(2))
+	require.NoError(t, err)
+	sheets = readNUpSheets(t, pdf)
+	require.Len(t, sheets, 1)
+	assert.Equal(t, 595.0, sheets[0].width)
+	assert.InDelta(t, 0.5, sheets[0].scale[0], 1e-9)
+
+	_, err = NUpPages(4, 4, NUpLayout{Gutter: "10cm"})(newTestPDF(2))
+	assert.EqualError(t, err, "n-up gutter 10cm is too large for 4x4 pages on a sheet")
+}
+
+func TestNUpDestinations(t *testing.T) {
+	pdf, err := modifyPDF(newTestPDF(3), func(doc *pdfDocument) error {
+		pages, err := doc.pages()
+		if err != nil {
+			return err
+		}
+		item := newPDFDict()
+		item.Set("Title", pdfString("Page 3"))
+		item.Set("Dest", pdfArray{pages[2], pdfName("XYZ"), pdfInt(0), pdfInt(800), pdfInt(0)})
+		cat, err := doc.catalog()
+		cat.Set("Outlines", doc.add(item))
+		link := newPDFDict()
+		link.Set("Subtype", pdfName("Link"))
+		link.Set("Dest", pdfArray{pages[2], pdfName("Fit")})
+		field := newPDFDict()
+		field.Set("Subtype", pdfName("Widget"))
+		field.Set("P", pages[0])
+		fieldRef := doc.add(field)
+		doc.dict(pages[0]).Set("Annots", pdfArray{doc.add(link), fieldRef})
+		form := newPDFDict()
+		form.Set("Fields", pdfArray{fieldRef})
+		cat.Set("AcroForm", doc.add(form))
+		return err
+	})
+	require.NoError(t, err)
+	pdf, err = NUpPages(2, 1, NUpLayout{})(pdf)
+	require.NoError(t, err)
+
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	sheets, err := doc.pages()
+	require.NoError(t, err)
+	require.Len(t, sheets, 2)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	assert.Equal(t, pdfArray{sheets[1], pdfName("Fit")}, doc.dict(cat.Get("Outlines")).Get("Dest"))
+
+	// the pages are not in the PDF anymore, nor their contents and annotations; the content is only in the form
+	// of the sheet
+	assert.Nil(t, cat.Get("AcroForm"))
+	for i := 1; i <= 3; i++ {
+		assert.Equal(t, 1, strings.Count(string(pdf), testPageContent(i)), "page %d", i)
+	}
+	for num, obj := range doc.objects {
+		if d, ok := obj.(*pdfDict); ok {
+			assert.NotContains(t, []pdfObject{pdfName("Link"), pdfName("Widget")}, d.Get("Subtype"), "object %d", num)
+			assert.Nil(t, d.Get("Annots"), "object %d", num)
+		}
+	}
+}
+
+func TestNUp(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.NUp(0, 2, NUpLayout{}), "invalid n-up layout 0x2, there must be at least 1 column and row")
+	assert.EqualError(t, pdfg.NUp(2, 1, NUpLayout{Orientation: "sideways"}), `invalid n-up orientation "sideways", use Portrait or Landscape`)
+	assert.EqualError(t, pdfg.NUp(2, 1, NUpLayout{Gutter: "5"}), `invalid n-up gutter: invalid length "5", use a number and a unit like 25mm`)
+	assert.Empty(t, pdfg.postProcessors())
+
+	require.NoError(t, pdfg.NUp(2, 1, NUpLayout{Gutter: "5mm"}))
+	require.Len(t, pdfg.postProcessors(), 1)
+	pdf, err := pdfg.postProcessors()[0](newTestPDF(4))
+	require.NoError(t, err)
+	assert.Len(t, readNUpSheets(t, pdf), 2)
+
+	require.NoError(t, pdfg.NUp(1, 1, NUpLayout{}))
+	assert.Empty(t, pdfg.postProcessors())
+}
@@ -0,0 +1,125 @@
+package wkhtmltopdf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tableColumnWidth is the width assumed for each column of a table by EstimateContentWidth
+var tableColumnWidth = Length{Value: 80, Unit: Pixel}
+
+// portraitPageWidths are the widths of the page sizes in portrait orientation, in millimeters
+var portraitPageWidths = map[string]float64{
+	"a3":      297,
+	"a4":      210,
+	"a5":      148,
+	"b4":      250,
+	"b5":      176,
+	"letter":  215.9,
+	"legal":   215.9,
+	"tabloid": 279.4,
+}
+
+var (
+	widthAttrRegex  = regexp.MustCompile(`(?i)<[a-z][^>]*\swidth\s*=\s*["']?(\d+(?:\.\d+)?)(?:px)?["'\s/>]`)
+	widthStyleRegex = regexp.MustCompile(`(?i)(?:^|[^-\w])(?:min-)?width\s*:\s*(\d+(?:\.\d+)?)(px|pt|mm|cm|in)\b`)
+	tableRowRegex   = regexp.MustCompile(`(?is)<tr[\s>].*?(?:</tr\s*>|<tr[\s>]|</table\s*>|$)`)
+	tableCellRegex  = regexp.MustCompile(`(?i)<t[dh](?:\s[^>]*)?>`)
+	colspanRegex    = regexp.MustCompile(`(?i)colspan\s*=\s*["']?(\d+)`)
+)
+
+// SetAutoOrientation switches to landscape orientation at Create when the content of a page is estimated to be
+// wider than the printable width of the page in portrait orientation (see EstimateContentWidth), like tables with
+// many columns. It has no effect if Orientation is set. This is a best-effort heuristic: wkhtmltopdf supports only
+// one orientation for the whole document, so one wide page makes all pages landscape. Only local files and pages
+// read from memory are measured, not URLs, and the HTML is not rendered, so widths set by scripts or by CSS in
+// external style sheets are not found. Content which is only slightly too wide may also fit without it, as
+// wkhtmltopdf shrinks content to the page width (see DisableSmartShrinking).
+func (pdfg *PDFGenerator) SetAutoOrientation(auto bool) {
+	pdfg.autoOrientation = auto
+}
+
+// EstimateContentWidth estimates the width of the content of a HTML document without rendering it, in pixels.
+// It is the largest of the widths set in pixels, points or metric units with width attributes and (min-)width
+// CSS properties, and the width of the table with the most columns, assuming 80px per column.
+// Percentages and relative units like em are ignored.
+func EstimateContentWidth(html []byte) Length {
+	var widest float64
+	for _, m := range widthAttrRegex.FindAllSubmatch(html, -1) {
+		if v, err := strconv.ParseFloat(string(m[1]), 64); err == nil && v > widest {
+			widest = v
+		}
+	}
+	for _, m := range widthStyleRegex.FindAllSubmatch(html, -1) {
+		l, err := ParseLength(string(m[1]) + string(m[2]))
+		if err != nil {
+			continue
+		}
+		if v := l.To(Pixel).Value; v > widest {
+			widest = v
+		}
+	}
+	for _, row := range tableRowRegex.FindAll(html, -1) {
+		columns := 0
+		for _, cell := range tableCellRegex.FindAll(row, -1) {
+			span := 1
+			if m := colspanRegex.FindSubmatch(cell); m != nil {
+				if n, err := strconv.Atoi(string(m[1])); err == nil && n > 1 {
+					span = n
+				}
+			}
+			columns += span
+		}
+		if v := float64(columns) * tableColumnWidth.Value; v > widest {
+			widest = v
+		}
+	}
+	return Length{Value: widest, Unit: Pixel}
+}
+
+// portraitPrintableWidth returns the width of the page in portrait orientation without the left and right margin
+func (pdfg *PDFGenerator) portraitPrintableWidth() Length {
+	width := portraitPageWidths["a4"]
+	if l, err := ParseLength(pdfg.PageWidthUnit.value); err == nil {
+		width = l.Millimeters()
+	} else if pdfg.PageWidth.isSet {
+		width = float64(pdfg.PageWidth.value)
+	} else if w, ok := portraitPageWidths[strings.ToLower(pdfg.PageSize.value)]; ok {
+		width = w
+	}
+	width -= marginMillimeters(pdfg.MarginLeftUnit, pdfg.MarginLeft) + marginMillimeters(pdfg.MarginRightUnit, pdfg.MarginRight)
+	return Length{Value: width, Unit: Millimeter}
+}
+
+// marginMillimeters returns a margin in mm, set with a unit or in mm, or the default of wkhtmltopdf of 10mm
+func marginMillimeters(withUnit stringOption, mm uintOption) float64 {
+	if l, err := ParseLength(withUnit.value); err == nil {
+		return l.Millimeters()
+	}
+	if mm.isSet {
+		return float64(mm.value)
+	}
+	return 10
+}
+
+// applyAutoOrientation sets the orientation to landscape if the content of a page is too wide for portrait,
+// see SetAutoOrientation. It returns a function to restore the orientation.
+func (pdfg *PDFGenerator) applyAutoOrientation() (func(), error) {
+	restore := func() {}
+	if !pdfg.autoOrientation || pdfg.Orientation.value != "" {
+		return restore, nil
+	}
+	available := pdfg.portraitPrintableWidth().To(Pixel).Value
+	for i := range pdfg.pages {
+		html, err := pdfg.pageHTML(i)
+		if err != nil {
+			return restore, err
+		}
+		if EstimateContentWidth(html).Value > available {
+			pdfg.Orientation.Set(OrientationLandscape)
+			return func() { pdfg.Orientation.Unset() }, nil
+		}
+	}
+	return restore, nil
+}
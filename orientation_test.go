@@ -0,0 +1,95 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateContentWidth(t *testing.T) {
+	tests := []struct {
+		html string
+		want float64
+	}{
+		{"<p>Hello</p>", 0},
+		{`<img src="a.png" width="1200">`, 1200},
+		{`<img src="a.png" width="50%">`, 0},
+		{`<div style="min-width: 300px; max-width: 2000px">x</div>`, 300},
+		{`<style>.wide { width: 10in }</style>`, 960},
+		{"<table><tr><td>1</td><td>2</td></tr><tr><th>1</th><th>2</th><th>3</th></tr></table>", 240},
+		{`<table><tr><td colspan="5">1</td><td>2</td></tr></table>`, 480},
+	}
+	for _, tt := range tests {
+		assert.InDelta(t, tt.want, EstimateContentWidth([]byte(tt.html)).Value, 0.001, tt.html)
+	}
+}
+
+func TestPortraitPrintableWidth(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.InDelta(t, 190, pdfg.portraitPrintableWidth().Value, 0.001)
+
+	pdfg.PageSize.Set(PageSizeA3)
+	pdfg.MarginLeft.Set(20)
+	require.NoError(t, pdfg.SetMargins("10mm", "1in", "10mm", "20mm"))
+	assert.InDelta(t, 297-25.4-20, pdfg.portraitPrintableWidth().Value, 0.001)
+
+	require.NoError(t, pdfg.SetCustomPageSize("100mm", "200mm"))
+	assert.InDelta(t, 100-25.4-20, pdfg.portraitPrintableWidth().Value, 0.001)
+}
+
+func TestAutoOrientation(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetAutoOrientation(true)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, pdfg.Args(), "--orientation")
+	cleanup()
+
+	// the wide table switches the document to landscape for the run
+	pdfg.AddPage(NewPage("testdata/widetable.html"))
+	cleanup, err = pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(pdfg.Args(), " "), "--orientation Landscape")
+	cleanup()
+	assert.NotContains(t, pdfg.Args(), "--orientation")
+
+	// an orientation which is set is kept
+	pdfg.Orientation.Set(OrientationPortrait)
+	cleanup, err = pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.Contains(t, strings.Join(pdfg.Args(), " "), "--orientation Portrait")
+	cleanup()
+
+	// a wider page fits the table
+	pdfg.Orientation.Unset()
+	pdfg.PageSize.Set(PageSizeA3)
+	cleanup, err = pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.NotContains(t, pdfg.Args(), "--orientation")
+	cleanup()
+}
+
+func TestAutoOrientationPageReader(t *testing.T) {
+	html, err := os.ReadFile("testdata/widetable.html")
+	require.NoError(t, err)
+	pdfg := NewPDFPreparer()
+	pdfg.SetAutoOrientation(true)
+	pdfg.AddPage(NewPageReader(strings.NewReader(string(html))))
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Contains(t, strings.Join(pdfg.Args(), " "), "--orientation Landscape")
+
+	// the content is still passed to wkhtmltopdf
+	r, err := stdinReader(pdfg.pages[0])
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, html, b)
+}
@@ -0,0 +1,92 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ErrOutputNotWritable is returned when the output file can't be written, like in a read-only directory
+var ErrOutputNotWritable = errors.New("output file is not writable")
+
+// SetOutputFallbackToBuffer keeps the PDF in the internal buffer (see Bytes) instead of failing when OutputFile
+// can't be written, like on a read-only filesystem in a container. The fallback is reported as a warning: it is the
+// first of the Warnings of the RenderResult and is passed to the handler set with SetStderrHandler as a
+// SeverityWarning event. OutputFile is not changed.
+func (pdfg *PDFGenerator) SetOutputFallbackToBuffer(fallback bool) {
+	pdfg.outputFallback = fallback
+}
+
+// checkWritable returns an error wrapping ErrOutputNotWritable if the file at path can't be created or
+// overwritten. It creates and removes a temporary file in the directory of path.
+func checkWritable(path string) error {
+	dir := filepath.Dir(path)
+	info, err := os.Stat(dir)
+	if err != nil {
+		return outputError(path, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%w: %s: %s is not a directory", ErrOutputNotWritable, path, dir)
+	}
+	if info, err := os.Stat(path); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%w: %s is a directory", ErrOutputNotWritable, path)
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return outputError(path, err)
+		}
+		f.Close()
+		return nil
+	}
+	f, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return outputError(path, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
+
+// outputError returns an error wrapping ErrOutputNotWritable and err, which says what to fix
+func outputError(path string, err error) error {
+	dir := filepath.Dir(path)
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Errorf("%w: %s: no permission to write in %s, make the directory writable or choose another "+
+			"output path: %w", ErrOutputNotWritable, path, dir, err)
+	case errors.Is(err, fs.ErrNotExist):
+		return fmt.Errorf("%w: %s: the directory %s does not exist: %w", ErrOutputNotWritable, path, dir, err)
+	}
+	return fmt.Errorf("%w: %s: %w", ErrOutputNotWritable, path, err)
+}
+
+// checkOutputFile returns an error if OutputFile is set and can't be written, unless the PDF falls back to the
+// buffer then, see SetOutputFallbackToBuffer
+func (pdfg *PDFGenerator) checkOutputFile() error {
+	if pdfg.OutputFile == "" || pdfg.outputFallback {
+		return nil
+	}
+	return checkWritable(pdfg.OutputFile)
+}
+
+// applyOutputFallback clears OutputFile for this run if it can't be written and SetOutputFallbackToBuffer is set.
+// It returns a function to restore OutputFile and the warning to report, or an empty string.
+func (pdfg *PDFGenerator) applyOutputFallback() (func(), string) {
+	if !pdfg.outputFallback || pdfg.OutputFile == "" {
+		return func() {}, ""
+	}
+	err := checkWritable(pdfg.OutputFile)
+	if err == nil {
+		return func() {}, ""
+	}
+	outputFile := pdfg.OutputFile
+	pdfg.OutputFile = ""
+	warning := fmt.Sprintf("%v, the PDF is kept in the buffer", err)
+	if pdfg.stderrHandler != nil {
+		pdfg.stderrHandler(StderrEvent{Severity: SeverityWarning, Message: warning, Line: warning})
+	}
+	return func() { pdfg.OutputFile = outputFile }, warning
+}
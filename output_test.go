@@ -0,0 +1,90 @@
+package wkhtmltopdf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readOnlyDir returns a temporary directory without write permission, the test is skipped if it is writable anyway
+func readOnlyDir(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o555))
+	t.Cleanup(func() { os.Chmod(dir, 0o755) })
+	if f, err := os.CreateTemp(dir, "probe-*"); err == nil {
+		f.Close()
+		os.Remove(f.Name())
+		t.Skip("the directory is writable anyway, like when running as root")
+	}
+	return dir
+}
+
+func TestCheckWritable(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, checkWritable(filepath.Join(dir, "out.pdf")))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the check must not leave files behind")
+
+	err = checkWritable(filepath.Join(dir, "missing", "out.pdf"))
+	assert.ErrorIs(t, err, ErrOutputNotWritable)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Contains(t, err.Error(), "does not exist")
+
+	assert.ErrorIs(t, checkWritable(dir), ErrOutputNotWritable)
+}
+
+func TestCheckWritableReadOnlyDir(t *testing.T) {
+	path := filepath.Join(readOnlyDir(t), "out.pdf")
+	err := checkWritable(path)
+	assert.ErrorIs(t, err, ErrOutputNotWritable)
+	assert.ErrorIs(t, err, os.ErrPermission)
+	assert.Contains(t, err.Error(), path+": no permission to write in")
+
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.OutputFile = path
+	assert.ErrorIs(t, pdfg.Create(), ErrOutputNotWritable)
+	assert.ErrorIs(t, pdfg.WriteFile(path), ErrOutputNotWritable)
+}
+
+func TestCreateOutputFile(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.OutputFile = filepath.Join(t.TempDir(), "missing", "out.pdf")
+	assert.ErrorIs(t, pdfg.Create(), ErrOutputNotWritable)
+	assert.NoError(t, pdfg.Validate(), "Validate doesn't probe the output file")
+
+	// with the fallback to the buffer the output file is not a problem
+	pdfg.SetOutputFallbackToBuffer(true)
+	assert.NoError(t, pdfg.checkOutputFile())
+
+	assert.ErrorIs(t, pdfg.WriteFile(pdfg.OutputFile), ErrOutputNotWritable)
+}
+
+func TestOutputFallbackToBuffer(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.OutputFile = filepath.Join(t.TempDir(), "missing", "out.pdf")
+	pdfg.SetOutputFallbackToBuffer(true)
+	var events []StderrEvent
+	pdfg.SetStderrHandler(func(e StderrEvent) {
+		if e.Severity == SeverityWarning {
+			events = append(events, e)
+		}
+	})
+
+	result, err := pdfg.CreateWithResult(t.Context())
+	require.NoError(t, err)
+	assert.NotEmpty(t, pdfg.Bytes())
+	assert.Equal(t, pdfg.Bytes(), result.Bytes)
+	require.NotEmpty(t, result.Warnings)
+	assert.Contains(t, result.Warnings[0], "the PDF is kept in the buffer")
+	require.Len(t, events, 1)
+	assert.Equal(t, result.Warnings[0], events[0].Message)
+	assert.Equal(t, filepath.Join(filepath.Dir(pdfg.OutputFile), "out.pdf"), pdfg.OutputFile, "OutputFile is not changed")
+}
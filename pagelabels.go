@@ -0,0 +1,88 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+)
+
+// PageLabelStyle is the numbering style of a PageLabelRange
+type PageLabelStyle string
+
+const (
+	PageLabelDecimal    PageLabelStyle = "D" // 1, 2, 3
+	PageLabelRomanUpper PageLabelStyle = "R" // I, II, III
+	PageLabelRomanLower PageLabelStyle = "r" // i, ii, iii
+	PageLabelAlphaUpper PageLabelStyle = "A" // A, B, C
+	PageLabelAlphaLower PageLabelStyle = "a" // a, b, c
+	PageLabelNone       PageLabelStyle = ""  // no number, only the prefix
+)
+
+// PageLabelRange labels the pages from StartPage up to the start of the next range, or the end of the document.
+type PageLabelRange struct {
+	StartPage   int            // First page of the range in the PDF, counting from 1
+	Style       PageLabelStyle // Numbering style
+	Prefix      string         // Text in front of the number, like "A-" for A-1, A-2
+	FirstNumber int            // Number of the first page of the range, 0 is the same as 1
+}
+
+// SetPageLabels sets the page labels viewers like Acrobat show instead of the page numbers, like i, ii, iii for
+// the front matter and 1, 2, 3 for the body. wkhtmltopdf doesn't write page labels, so they are added to the
+// catalog (/PageLabels) by post-processing the PDF, after the pages added by SetForceOddStart.
+// The ranges must be sorted, the first one must start at page 1 so the whole document is labeled, and each one
+// must start after the previous one. Create returns an error if a range starts after the last page.
+// Calling it without ranges removes the page labels.
+func (pdfg *PDFGenerator) SetPageLabels(ranges []PageLabelRange) error {
+	for i, r := range ranges {
+		switch {
+		case i == 0 && r.StartPage != 1:
+			return fmt.Errorf("the first page label range must start at page 1, not %d", r.StartPage)
+		case i > 0 && r.StartPage <= ranges[i-1].StartPage:
+			return fmt.Errorf("page label range %d starts at page %d, which is not after the start of the previous range (page %d)", i+1, r.StartPage, ranges[i-1].StartPage)
+		case r.FirstNumber < 0:
+			return fmt.Errorf("page label range %d has a negative first number %d", i+1, r.FirstNumber)
+		}
+		switch r.Style {
+		case PageLabelDecimal, PageLabelRomanUpper, PageLabelRomanLower, PageLabelAlphaUpper, PageLabelAlphaLower, PageLabelNone:
+		default:
+			return fmt.Errorf("page label range %d has an unknown style %q", i+1, r.Style)
+		}
+	}
+	pdfg.pageLabels = append([]PageLabelRange{}, ranges...)
+	return nil
+}
+
+// setPageLabels returns a post-processor which writes ranges as /PageLabels number tree in the catalog
+func setPageLabels(ranges []PageLabelRange) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			nums := make(pdfArray, 0, 2*len(ranges))
+			for _, r := range ranges {
+				if r.StartPage > len(pages) {
+					return fmt.Errorf("page label range starts at page %d, the PDF has %d pages", r.StartPage, len(pages))
+				}
+				label := newPDFDict()
+				if r.Style != PageLabelNone {
+					label.Set("S", pdfName(r.Style))
+				}
+				if r.Prefix != "" {
+					label.Set("P", pdfTextString(r.Prefix))
+				}
+				if r.FirstNumber > 1 {
+					label.Set("St", pdfInt(r.FirstNumber))
+				}
+				nums = append(nums, pdfInt(r.StartPage-1), label)
+			}
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			labels := newPDFDict()
+			labels.Set("Nums", nums)
+			cat.Set("PageLabels", labels)
+			return nil
+		})
+	}
+}
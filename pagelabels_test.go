@@ -0,0 +1,67 @@
+package wkhtmltopdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPageLabels(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 2}}),
+		"the first page label range must start at page 1, not 2")
+	assert.EqualError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 1}, {StartPage: 4}, {StartPage: 4}}),
+		"page label range 3 starts at page 4, which is not after the start of the previous range (page 4)")
+	assert.EqualError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 1, Style: "x"}}),
+		`page label range 1 has an unknown style "x"`)
+	assert.EqualError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 1, FirstNumber: -1}}),
+		"page label range 1 has a negative first number -1")
+	assert.Empty(t, pdfg.postProcessors())
+
+	require.NoError(t, pdfg.SetPageLabels([]PageLabelRange{
+		{StartPage: 1, Style: PageLabelRomanLower},
+		{StartPage: 3, Style: PageLabelDecimal},
+		{StartPage: 5, Style: PageLabelDecimal, Prefix: "A-", FirstNumber: 10},
+	}))
+	processors := pdfg.postProcessors()
+	require.Len(t, processors, 1)
+
+	pdf, err := processors[0](newTestPDF(6))
+	require.NoError(t, err)
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	labels := doc.dict(cat.Get("PageLabels"))
+	require.NotNil(t, labels)
+	nums, ok := labels.Get("Nums").(pdfArray)
+	require.True(t, ok)
+	require.Len(t, nums, 6)
+	assert.Equal(t, []pdfObject{pdfNumber("0"), pdfNumber("2"), pdfNumber("4")}, []pdfObject{nums[0], nums[2], nums[4]})
+	assert.Equal(t, pdfName("r"), doc.dict(nums[1]).Get("S"))
+	assert.Equal(t, pdfName("D"), doc.dict(nums[3]).Get("S"))
+	assert.Nil(t, doc.dict(nums[3]).Get("P"))
+	assert.Equal(t, pdfString("A-"), doc.dict(nums[5]).Get("P"))
+	assert.Equal(t, pdfNumber("10"), doc.dict(nums[5]).Get("St"))
+
+	// the ranges must cover the document
+	_, err = processors[0](newTestPDF(4))
+	assert.EqualError(t, err, "page label range starts at page 5, the PDF has 4 pages")
+
+	// a prefix which is not ASCII is written in UTF-16
+	require.NoError(t, pdfg.SetPageLabels([]PageLabelRange{{StartPage: 1, Style: PageLabelDecimal, Prefix: "Anhang-Ä-"}}))
+	pdf, err = pdfg.postProcessors()[0](newTestPDF(1))
+	require.NoError(t, err)
+	doc, err = parsePDF(pdf)
+	require.NoError(t, err)
+	cat, err = doc.catalog()
+	require.NoError(t, err)
+	nums, ok = doc.dict(cat.Get("PageLabels")).Get("Nums").(pdfArray)
+	require.True(t, ok)
+	require.Len(t, nums, 2)
+	assert.Equal(t, pdfTextString("Anhang-Ä-"), doc.dict(nums[1]).Get("P"))
+
+	require.NoError(t, pdfg.SetPageLabels(nil))
+	assert.Empty(t, pdfg.postProcessors())
+}
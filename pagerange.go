@@ -0,0 +1,250 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pageRange is a range of pages from up to and including to, counting from 1
+type pageRange struct {
+	from, to int
+}
+
+// IncludePages keeps only the given pages of the PDF pages this page renders to, like "1-3,5" for the first three
+// and the fifth, when one HTML page renders to many PDF pages and only some of them are needed. The ranges are
+// page numbers (counting from 1 in the output of this page) and ranges like 1-3, separated by commas; the pages
+// keep their order, whatever the order of the ranges. An empty string keeps all pages. wkhtmltopdf always renders
+// the whole page, so the other pages are removed by post-processing the PDF. To find the pages of each page in
+// the PDF, all pages are rendered once more one by one to count their pages, which costs an extra wkhtmltopdf run
+// per page. Create returns an error if a range is beyond the last page. The page numbers in headers and footers
+// and the TOC are rendered before the pages are removed, so they still count and list the removed pages;
+// bookmarks of removed pages are removed from the outline, their nested bookmarks take their place. The removed
+// pages are not in the PDF file.
+// An error is returned if the syntax of ranges is not valid, ranges set before are kept then.
+func (po *PageOptions) IncludePages(ranges string) error {
+	parsed, err := parsePageRanges(ranges)
+	if err != nil {
+		return err
+	}
+	po.includePages = parsed
+	return nil
+}
+
+// parsePageRanges parses page ranges like "1-3,5", nil is returned for an empty string
+func parsePageRanges(s string) ([]pageRange, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var ranges []pageRange
+	for _, part := range strings.Split(s, ",") {
+		from, to, isRange := strings.Cut(strings.TrimSpace(part), "-")
+		r := pageRange{}
+		var err error
+		if r.from, err = strconv.Atoi(strings.TrimSpace(from)); err == nil {
+			r.to = r.from
+			if isRange {
+				r.to, err = strconv.Atoi(strings.TrimSpace(to))
+			}
+		}
+		if err != nil || r.from < 1 || r.to < r.from {
+			return nil, fmt.Errorf("invalid page range %q in %q, use page numbers from 1 and ranges like 1-3", strings.TrimSpace(part), s)
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges, nil
+}
+
+// formatPageRanges returns ranges in the syntax of parsePageRanges, like "1-3,5"
+func formatPageRanges(ranges []pageRange) string {
+	parts := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r.from == r.to {
+			parts = append(parts, strconv.Itoa(r.from))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.from, r.to))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// includesPages reports whether page is in one of the ranges, counting from 1
+func includesPages(ranges []pageRange, page int) bool {
+	for _, r := range ranges {
+		if page >= r.from && page <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+// countPageGroups renders each page by itself to count its PDF pages when a page uses IncludePages or a PDF is
+// inserted between the pages with AppendPDFAt, stdin is the content of the page read from stdin. The ranges are
+// checked against the counts, and the body pages counted for SetForceOddStart are reduced by the pages which are
+// removed. The returned function resets the counts, it must always be called.
+func (pdfg *PDFGenerator) countPageGroups(ctx context.Context, stdin []byte) (func(), error) {
+	reset := func() { pdfg.pageGroupPages = nil }
+	if !pdfg.hasIncludePages() && !pdfg.needsPageCounts() {
+		return reset, nil
+	}
+
+	counts := make([]int, len(pdfg.pages))
+	removed := 0
+	for i, page := range pdfg.pages {
+		input := pdfg.pageInput(i)
+		args := pdfg.countArgs()
+		args = append(args, "page", input)
+		args = append(args, page.Args()...)
+		args = append(args, "-")
+		var pageStdin []byte
+		if input == "-" {
+			pageStdin = stdin
+		}
+		pages, err := pdfg.renderPageCount(ctx, args, pageStdin)
+		if err != nil {
+			return reset, fmt.Errorf("error counting the pages of page %d: %w", i+1, err)
+		}
+		counts[i] = pages
+
+		ranges := page.Options().includePages
+		if len(ranges) == 0 {
+			continue
+		}
+		for _, r := range ranges {
+			if r.to > pages {
+				return reset, fmt.Errorf("page range %d-%d of page %d is out of range, it has %d pages", r.from, r.to, i+1, pages)
+			}
+		}
+		for p := 1; p <= pages; p++ {
+			if !includesPages(ranges, p) {
+				removed++
+			}
+		}
+	}
+	pdfg.pageGroupPages = counts
+	if pdfg.bodyPages > 0 {
+		pdfg.bodyPages -= removed
+	}
+	return reset, nil
+}
+
+// hasIncludePages reports whether a page uses IncludePages
+func (pdfg *PDFGenerator) hasIncludePages() bool {
+	for _, page := range pdfg.pages {
+		if len(page.Options().includePages) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// selectPageRanges returns a post-processor which keeps only the pages selected with IncludePages. counts are
+// the number of PDF pages of each page, the pages before them are the cover and the TOC, which are kept.
+func selectPageRanges(counts []int, ranges [][]pageRange) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			body := 0
+			for _, n := range counts {
+				body += n
+			}
+			start := len(pages) - body
+			if start < 0 {
+				return fmt.Errorf("the PDF has %d pages, fewer than the %d pages counted for IncludePages", len(pages), body)
+			}
+
+			kept := append([]pdfRef{}, pages[:start]...)
+			for i, n := range counts {
+				for p := 1; p <= n; p++ {
+					if len(ranges[i]) == 0 || includesPages(ranges[i], p) {
+						kept = append(kept, pages[start+p-1])
+					}
+				}
+				start += n
+			}
+			if err := doc.removeOutlineTargets(pages, kept); err != nil {
+				return err
+			}
+			return doc.setPages(kept)
+		})
+	}
+}
+
+// removeOutlineTargets removes the outline items which point to one of pages which is not in kept, before the
+// pages are replaced by kept with setPages. The children of a removed item take its place.
+func (doc *pdfDocument) removeOutlineTargets(pages, kept []pdfRef) error {
+	removed := make(map[int]bool)
+	for _, ref := range pages {
+		removed[ref.num] = true
+	}
+	for _, ref := range kept {
+		delete(removed, ref.num)
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	cat, err := doc.catalog()
+	if err != nil {
+		return err
+	}
+	rootRef, ok := cat.Get("Outlines").(pdfRef)
+	root := doc.dict(rootRef)
+	if !ok || root == nil {
+		return nil
+	}
+
+	// targetRemoved reports whether the outline item points to a removed page, also through a named destination
+	targetRemoved := func(item *pdfDict) bool {
+		dest := item.Get("Dest")
+		if action := doc.dict(item.Get("A")); dest == nil && action != nil && action.Get("S") == pdfName("GoTo") {
+			dest = action.Get("D")
+		}
+		if arr, ok := doc.namedDestination(cat, dest).(pdfArray); ok && len(arr) > 0 {
+			if ref, ok := arr[0].(pdfRef); ok {
+				return removed[ref.num]
+			}
+		}
+		return false
+	}
+	seen := make(map[int]bool)
+	var prune func(parent *pdfDict, depth int) []pdfRef
+	prune = func(parent *pdfDict, depth int) []pdfRef {
+		var items []pdfRef
+		for ref, ok := parent.Get("First").(pdfRef); ok && !seen[ref.num] && doc.dict(ref) != nil; ref, ok = doc.dict(ref).Get("Next").(pdfRef) {
+			seen[ref.num] = true
+			item := doc.dict(ref)
+			var children []pdfRef
+			if depth < 32 {
+				children = prune(item, depth+1)
+			}
+			if targetRemoved(item) {
+				items = append(items, children...)
+				continue
+			}
+			closed := false
+			if n, ok := doc.intValue(item.Get("Count")); ok && n < 0 {
+				closed = true
+			}
+			for _, key := range []pdfName{"First", "Last", "Count"} {
+				item.Del(key)
+			}
+			linkOutlineItems(doc, ref, item, children)
+			if n, ok := doc.intValue(item.Get("Count")); ok && closed {
+				item.Set("Count", pdfInt(-n))
+			}
+			items = append(items, ref)
+		}
+		return items
+	}
+	items := prune(root, 0)
+	if len(items) == 0 {
+		cat.Del("Outlines")
+		return nil
+	}
+	linkOutlineItems(doc, rootRef, root, items)
+	return nil
+}
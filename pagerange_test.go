@@ -0,0 +1,161 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePageRanges(t *testing.T) {
+	ranges, err := parsePageRanges(" 1-3, 5,4-4 ")
+	require.NoError(t, err)
+	assert.Equal(t, []pageRange{{1, 3}, {5, 5}, {4, 4}}, ranges)
+
+	ranges, err = parsePageRanges("")
+	require.NoError(t, err)
+	assert.Nil(t, ranges)
+
+	for _, s := range []string{"0", "3-1", "1-", "-2", "a", "1,,2", "1-2-3", "1;2"} {
+		_, err := parsePageRanges(s)
+		assert.Error(t, err, s)
+	}
+	_, err = parsePageRanges("1,3-1")
+	assert.EqualError(t, err, `invalid page range "3-1" in "1,3-1", use page numbers from 1 and ranges like 1-3`)
+
+	po := NewPageOptions()
+	require.NoError(t, po.IncludePages("2"))
+	assert.Error(t, po.IncludePages("x"))
+	assert.Equal(t, []pageRange{{2, 2}}, po.includePages)
+}
+
+// newPageCountBinary returns a fake wkhtmltopdf which writes 5 pages for testdata/multipage.html and 1 page for
+// other HTML inputs (including the cover), with text "Page N" on page N of the PDF
+func newPageCountBinary(t *testing.T) string {
+	dir := t.TempDir()
+	for n := 1; n <= 8; n++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.pdf", n)), newTestPDF(n), 0644))
+	}
+	script := "#!/bin/sh\nn=0\nfor a in \"$@\"; do\n\tcase \"$a\" in\n\t*multipage.html) n=$((n+5)) ;;\n" +
+		"\t*.html) n=$((n+1)) ;;\n\tesac\ndone\ncat > /dev/null\ncat " + dir + "/$n.pdf\n"
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+	return bin
+}
+
+func TestIncludePages(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = newPageCountBinary(t)
+	pdfg.Cover.Input = "testdata/htmlsimple.html"
+	page := NewPage("testdata/multipage.html")
+	require.NoError(t, page.IncludePages("4,1-2"))
+	pdfg.AddPage(page)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+
+	// the cover is page 1, the report pages 2 to 6 and the last page is page 7
+	assert.Equal(t, []string{
+		"BT /F1 12 Tf 72 720 Td (Page 1) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 2) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 3) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 5) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 7) Tj ET",
+	}, testPDFPageTexts(t, pdfg.Bytes()))
+	assert.Nil(t, pdfg.pageGroupPages)
+
+	// the body starts on page 3 after padding the cover, the removed pages are not counted
+	pdfg.SetForceOddStart(true)
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+	texts := testPDFPageTexts(t, pdfg.Bytes())
+	require.Len(t, texts, 6)
+	assert.Equal(t, "BT /F1 12 Tf 72 720 Td (Page 2) Tj ET", texts[2])
+}
+
+func TestIncludePagesOutOfRange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = newPageCountBinary(t)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	page := NewPage("testdata/multipage.html")
+	require.NoError(t, page.IncludePages("2-6"))
+	pdfg.AddPage(page)
+	err := pdfg.CreateContext(context.Background())
+	assert.EqualError(t, err, "page range 2-6 of page 2 is out of range, it has 5 pages")
+}
+
+func TestIncludePagesJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	page := NewPage("testdata/multipage.html")
+	require.NoError(t, page.IncludePages("4,1-2"))
+	page.SetInlineCSS("body { color: red; }")
+	pdfg.AddPage(page)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	require.Len(t, restored.pages, 2)
+	assert.Equal(t, []pageRange{{4, 4}, {1, 2}}, restored.pages[0].Options().includePages)
+	assert.Equal(t, "body { color: red; }", restored.pages[0].Options().inlineCSS)
+	assert.Nil(t, restored.pages[1].Options().includePages)
+	assert.Empty(t, restored.pages[1].Options().inlineCSS)
+
+	_, err = NewPDFGeneratorFromJSON(strings.NewReader(`{"Pages":[{"Type":"page","InputFile":"a.html","IncludePages":"3-1"}]}`))
+	assert.ErrorContains(t, err, "invalid IncludePages on page 0")
+}
+
+func TestSelectPageRangesOutline(t *testing.T) {
+	// "Three" is nested below "Two", the bookmark of "One" uses a named destination
+	pdf, err := modifyPDF(newTestOutlinePDF(t, "Page", 4, "One", "Two", "Three", "Four"), func(doc *pdfDocument) error {
+		cat, _ := doc.catalog()
+		rootRef := cat.Get("Outlines").(pdfRef)
+		root := doc.dict(rootRef)
+		var items []pdfRef
+		for ref, ok := root.Get("First").(pdfRef); ok; ref, ok = doc.dict(ref).Get("Next").(pdfRef) {
+			items = append(items, ref)
+		}
+		linkOutlineItems(doc, items[1], doc.dict(items[1]), items[2:3])
+		linkOutlineItems(doc, rootRef, root, []pdfRef{items[0], items[1], items[3]})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Two -> 1", "  Three -> 2", "Four -> 3"}, testOutline(t, pdf)[1:])
+
+	out, err := selectPageRanges([]int{4}, [][]pageRange{{{1, 1}, {3, 4}}})(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"One -> -1", "Three -> 1", "Four -> 2"}, testOutline(t, out))
+	// the content of the removed page is not in the file
+	doc, err := parsePDF(out)
+	require.NoError(t, err)
+	var contents []string
+	for _, obj := range doc.objects {
+		if s, ok := obj.(*pdfStream); ok {
+			data, err := doc.decodeStream(s)
+			require.NoError(t, err)
+			contents = append(contents, string(data))
+		}
+	}
+	assert.Contains(t, contents, "BT /F1 12 Tf 72 720 Td (Page 3) Tj ET")
+	assert.NotContains(t, contents, "BT /F1 12 Tf 72 720 Td (Page 2) Tj ET")
+
+	out, err = selectPageRanges([]int{4}, [][]pageRange{{{2, 4}}})(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Two -> 0", "  Three -> 1", "Four -> 2"}, testOutline(t, out))
+
+	out, err = selectPageRanges([]int{4}, [][]pageRange{{{3, 3}}})(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Three -> 0"}, testOutline(t, out))
+}
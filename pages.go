@@ -0,0 +1,90 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrTooManyPages is returned by AddPagesFrom when more pages are received than allowed by SetMaxPages
+var ErrTooManyPages = errors.New("too many pages")
+
+// SetMaxPages limits the number of pages AddPagesFrom accepts in total, to bound the memory used when pages
+// are produced by another part of a program. 0 (the default) means no limit.
+func (pdfg *PDFGenerator) SetMaxPages(n int) {
+	pdfg.maxPages = n
+}
+
+// AddPagesFrom adds all pages received from ch with AddPage, until ch is closed.
+// This allows pages to be assembled while they are being produced elsewhere; wkhtmltopdf needs all pages
+// up front, so call Create after AddPagesFrom returns.
+// If ctx is done before ch is closed, ctx.Err() is returned; use context.WithTimeout to limit the waiting time.
+// If the total number of pages would exceed the limit set by SetMaxPages, ErrTooManyPages is returned.
+// Pages received before an error are kept.
+func (pdfg *PDFGenerator) AddPagesFrom(ctx context.Context, ch <-chan PageProvider) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case p, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			if pdfg.maxPages > 0 && len(pdfg.pages) >= pdfg.maxPages {
+				return fmt.Errorf("%w: the maximum is %d", ErrTooManyPages, pdfg.maxPages)
+			}
+			pdfg.AddPage(p)
+		}
+	}
+}
+
+var errNoPDF = errors.New("no PDF in the internal buffer, call Create first")
+
+// PageCount returns the number of pages of the PDF in the internal buffer, after Create has been called
+func (pdfg *PDFGenerator) PageCount() (int, error) {
+	if pdfg.outbuf.Len() == 0 {
+		return 0, errNoPDF
+	}
+	doc, err := parsePDF(pdfg.outbuf.Bytes())
+	if err != nil {
+		return 0, err
+	}
+	pages, err := doc.pages()
+	return len(pages), err
+}
+
+// ExtractPages reduces the PDF in the internal buffer to the pages from up to and including to, counting from 1.
+// It must be called after Create, and returns an error if the range is not within the pages of the PDF.
+// This is useful to create a preview of the first pages of a large document.
+// The removed pages and the content only they use are not written to the PDF, so they can't be recovered from it.
+func (pdfg *PDFGenerator) ExtractPages(from, to int) error {
+	if pdfg.outbuf.Len() == 0 {
+		return errNoPDF
+	}
+	pdf, err := extractPages(from, to)(pdfg.outbuf.Bytes())
+	if err != nil {
+		return err
+	}
+	pdfg.outbuf.Reset()
+	pdfg.outbuf.Write(pdf)
+	return nil
+}
+
+// extractPages returns a post-processor which keeps only pages from to to (inclusive, counting from 1)
+func extractPages(from, to int) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			if from < 1 || to < from || to > len(pages) {
+				return fmt.Errorf("invalid page range %d-%d for a document with %d pages", from, to, len(pages))
+			}
+			if err := doc.removeOutlineTargets(pages, pages[from-1:to]); err != nil {
+				return err
+			}
+			return doc.setPages(pages[from-1 : to])
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPages(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	_, err := pdfg.PageCount()
+	assert.Equal(t, errNoPDF, err)
+	assert.Equal(t, errNoPDF, pdfg.ExtractPages(1, 1))
+
+	pdfg.outbuf.Write(newTestPDF(5))
+	count, err := pdfg.PageCount()
+	require.NoError(t, err)
+	assert.Equal(t, 5, count)
+
+	require.NoError(t, pdfg.ExtractPages(2, 4))
+	count, err = pdfg.PageCount()
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, []string{
+		"BT /F1 12 Tf 72 720 Td (Page 2) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 3) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 4) Tj ET",
+	}, testPDFPageTexts(t, pdfg.Bytes()))
+	// the removed pages are not in the file
+	assert.NotContains(t, string(pdfg.Bytes()), "(Page 1)")
+	assert.NotContains(t, string(pdfg.Bytes()), "(Page 5)")
+}
+
+func TestExtractPagesOutOfRange(t *testing.T) {
+	for _, r := range [][2]int{{0, 1}, {2, 1}, {1, 4}} {
+		_, err := extractPages(r[0], r[1])(newTestPDF(3))
+		assert.Error(t, err, "range %d-%d", r[0], r[1])
+	}
+	_, err := extractPages(1, 4)(newTestPDF(3))
+	assert.EqualError(t, err, "invalid page range 1-4 for a document with 3 pages")
+}
+
+func TestAddPagesFrom(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetFooterHTML("testdata/footer.html")
+
+	ch := make(chan PageProvider)
+	go func() {
+		for _, url := range []string{"https://www.google.com", "https://www.github.com"} {
+			ch <- NewPage(url)
+		}
+		close(ch)
+	}()
+
+	err := pdfg.AddPagesFrom(context.Background(), ch)
+	require.NoError(t, err)
+	require.Len(t, pdfg.pages, 2)
+
+	// global settings are applied as with AddPage
+	assert.Equal(t, "testdata/footer.html", pdfg.pages[1].Options().FooterHTML.value)
+}
+
+func TestAddPagesFromCancel(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	ch := make(chan PageProvider, 1)
+	ch <- NewPage("https://www.google.com")
+
+	// the channel is never closed
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := pdfg.AddPagesFrom(ctx, ch)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, pdfg.pages, 1)
+}
+
+func TestAddPagesFromMaxPages(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetMaxPages(2)
+	ch := make(chan PageProvider, 3)
+	for i := 0; i < 3; i++ {
+		ch <- NewPage("https://www.google.com")
+	}
+	close(ch)
+
+	err := pdfg.AddPagesFrom(context.Background(), ch)
+	assert.True(t, errors.Is(err, ErrTooManyPages))
+	assert.EqualError(t, err, "too many pages: the maximum is 2")
+	assert.Len(t, pdfg.pages, 2)
+}
@@ -0,0 +1,894 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+)
+
+// This file contains a minimal PDF reader and writer which is used to post-process the output of wkhtmltopdf.
+// It reads all indirect objects of a file (including objects in object streams) into memory and writes them back
+// as a new file with a classic cross-reference table. It does not try to be a complete PDF implementation,
+// it only needs to handle the files written by wkhtmltopdf and other common PDF producers.
+
+// pdfObject is any PDF object: bool, pdfNull, pdfNumber, pdfString, pdfHexString, pdfName,
+// pdfArray, *pdfDict, *pdfStream or pdfRef
+type pdfObject interface{}
+
+type pdfNull struct{}
+
+// pdfNumber holds the number as written in the file, so values are not changed by reading and writing them
+type pdfNumber string
+
+type pdfString []byte
+
+type pdfHexString []byte
+
+type pdfName string
+
+type pdfArray []pdfObject
+
+type pdfRef struct {
+	num int
+	gen int
+}
+
+// pdfDict is a dictionary which keeps the order of its keys
+type pdfDict struct {
+	keys   []pdfName
+	values map[pdfName]pdfObject
+}
+
+type pdfStream struct {
+	dict *pdfDict
+	data []byte // the raw (encoded) stream data
+}
+
+func newPDFDict() *pdfDict {
+	return &pdfDict{values: make(map[pdfName]pdfObject)}
+}
+
+// Get returns the value of key, or nil if key is not present
+func (d *pdfDict) Get(key pdfName) pdfObject {
+	return d.values[key]
+}
+
+// Set sets the value of key, keeping the position of key if it is already present
+func (d *pdfDict) Set(key pdfName, value pdfObject) {
+	if _, ok := d.values[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+	d.values[key] = value
+}
+
+// Del removes key from the dictionary
+func (d *pdfDict) Del(key pdfName) {
+	if _, ok := d.values[key]; !ok {
+		return
+	}
+	delete(d.values, key)
+	for i, k := range d.keys {
+		if k == key {
+			d.keys = append(d.keys[:i], d.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func pdfInt(i int) pdfNumber {
+	return pdfNumber(strconv.Itoa(i))
+}
+
+func pdfFloat(f float64) pdfNumber {
+	return pdfNumber(strconv.FormatFloat(f, 'f', -1, 64))
+}
+
+// pdfTextString returns s as PDF text string, in UTF-16BE with a byte order mark if it is not ASCII
+func pdfTextString(s string) pdfString {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return pdfString(s)
+	}
+	b := []byte{0xfe, 0xff}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return pdfString(b)
+}
+
+// pdfDocument is a PDF file read into memory
+type pdfDocument struct {
+	version string
+	objects map[int]pdfObject
+	trailer *pdfDict
+}
+
+var errNotPDF = errors.New("not a PDF document")
+
+var pdfHeaderRegex = regexp.MustCompile(`^%PDF-(\d\.\d)`)
+
+var pdfObjRegex = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj`)
+
+// parsePDF reads all objects of a PDF file.
+// Objects are found by scanning the file instead of using the cross-reference table, which makes
+// reading files with a damaged table possible. When an object is defined multiple times (which happens
+// in files with incremental updates), the last definition is used.
+func parsePDF(data []byte) (*pdfDocument, error) {
+	m := pdfHeaderRegex.FindSubmatch(data)
+	if m == nil {
+		return nil, errNotPDF
+	}
+	doc := &pdfDocument{
+		version: string(m[1]),
+		objects: make(map[int]pdfObject),
+	}
+
+	var objStms []*pdfStream
+	pos := 0
+	for {
+		loc := pdfObjRegex.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		start := pos + loc[0]
+		// the object number must not be part of a larger token
+		if start > 0 && !isPDFWhitespace(data[start-1]) && !isPDFDelimiter(data[start-1]) {
+			pos += loc[1]
+			continue
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		p := &pdfParser{data: data, pos: pos + loc[1]}
+		obj, err := p.parseIndirect()
+		if err != nil {
+			return nil, fmt.Errorf("error reading PDF object %d: %w", num, err)
+		}
+		doc.objects[num] = obj
+		if s, ok := obj.(*pdfStream); ok {
+			switch s.dict.Get("Type") {
+			case pdfName("ObjStm"):
+				objStms = append(objStms, s)
+			case pdfName("XRef"):
+				doc.trailer = s.dict
+				delete(doc.objects, num)
+			}
+		}
+		pos = p.pos
+	}
+
+	// objects in object streams are added, unless they are defined outside an object stream as well
+	for _, s := range objStms {
+		if err := doc.readObjectStream(s); err != nil {
+			return nil, err
+		}
+	}
+	for num, obj := range doc.objects {
+		if s, ok := obj.(*pdfStream); ok && s.dict.Get("Type") == pdfName("ObjStm") {
+			delete(doc.objects, num)
+		}
+	}
+
+	// the last trailer in the file is the current one, it is always written after the last object
+	if i := bytes.LastIndex(data[pos:], []byte("trailer")); i >= 0 {
+		p := &pdfParser{data: data, pos: pos + i + len("trailer")}
+		obj, err := p.parseObject()
+		if err != nil {
+			return nil, fmt.Errorf("error reading PDF trailer: %w", err)
+		}
+		if d, ok := obj.(*pdfDict); ok {
+			doc.trailer = d
+		}
+	}
+	if doc.trailer == nil {
+		return nil, errors.New("PDF trailer not found")
+	}
+	if _, ok := doc.trailer.Get("Root").(pdfRef); !ok {
+		return nil, errors.New("PDF trailer has no Root")
+	}
+	return doc, nil
+}
+
+// readObjectStream adds all objects in a /Type /ObjStm stream to the document
+func (doc *pdfDocument) readObjectStream(s *pdfStream) error {
+	data, err := doc.decodeStream(s)
+	if err != nil {
+		return fmt.Errorf("error decoding object stream: %w", err)
+	}
+	n, _ := doc.intValue(s.dict.Get("N"))
+	first, _ := doc.intValue(s.dict.Get("First"))
+	if first > len(data) {
+		return errors.New("invalid object stream")
+	}
+	header := &pdfParser{data: data[:first]}
+	for i := 0; i < n; i++ {
+		numObj, err := header.parseObject()
+		if err != nil {
+			return err
+		}
+		offObj, err := header.parseObject()
+		if err != nil {
+			return err
+		}
+		num, _ := doc.intValue(numObj)
+		off, _ := doc.intValue(offObj)
+		if _, ok := doc.objects[num]; ok {
+			continue
+		}
+		p := &pdfParser{data: data, pos: first + off}
+		obj, err := p.parseObject()
+		if err != nil {
+			return fmt.Errorf("error reading PDF object %d from object stream: %w", num, err)
+		}
+		doc.objects[num] = obj
+	}
+	return nil
+}
+
+// decodeStream returns the decoded data of a stream, only FlateDecode (without predictors) is supported
+func (doc *pdfDocument) decodeStream(s *pdfStream) ([]byte, error) {
+	filter := doc.resolve(s.dict.Get("Filter"))
+	if arr, ok := filter.(pdfArray); ok && len(arr) == 1 {
+		filter = doc.resolve(arr[0])
+	}
+	switch filter {
+	case nil:
+		return s.data, nil
+	case pdfName("FlateDecode"):
+		if params, ok := doc.resolve(s.dict.Get("DecodeParms")).(*pdfDict); ok {
+			if p, _ := doc.intValue(params.Get("Predictor")); p > 1 {
+				return nil, errors.New("stream predictors are not supported")
+			}
+		}
+		zr, err := zlib.NewReader(bytes.NewReader(s.data))
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		return io.ReadAll(zr)
+	}
+	return nil, fmt.Errorf("unsupported stream filter %v", filter)
+}
+
+// newFlateStream returns a new stream with data compressed using FlateDecode
+func newFlateStream(dict *pdfDict, data []byte) *pdfStream {
+	if dict == nil {
+		dict = newPDFDict()
+	}
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(data)
+	zw.Close()
+	dict.Set("Filter", pdfName("FlateDecode"))
+	return &pdfStream{dict: dict, data: buf.Bytes()}
+}
+
+// resolve follows references until a direct object is found, it returns nil for missing objects
+func (doc *pdfDocument) resolve(obj pdfObject) pdfObject {
+	for i := 0; i < 32; i++ {
+		ref, ok := obj.(pdfRef)
+		if !ok {
+			return obj
+		}
+		obj = doc.objects[ref.num]
+	}
+	return nil
+}
+
+// intValue returns the (resolved) object as an int
+func (doc *pdfDocument) intValue(obj pdfObject) (int, bool) {
+	n, ok := doc.resolve(obj).(pdfNumber)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// floatValue returns the (resolved) object as a float64
+func (doc *pdfDocument) floatValue(obj pdfObject) (float64, bool) {
+	n, ok := doc.resolve(obj).(pdfNumber)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(string(n), 64)
+	return f, err == nil
+}
+
+// dict returns the (resolved) object as a dictionary, the dictionary of a stream is returned for streams
+func (doc *pdfDocument) dict(obj pdfObject) *pdfDict {
+	switch v := doc.resolve(obj).(type) {
+	case *pdfDict:
+		return v
+	case *pdfStream:
+		return v.dict
+	}
+	return nil
+}
+
+// add adds a new indirect object to the document and returns the reference to it
+func (doc *pdfDocument) add(obj pdfObject) pdfRef {
+	num := 1
+	for n := range doc.objects {
+		if n >= num {
+			num = n + 1
+		}
+	}
+	doc.objects[num] = obj
+	return pdfRef{num: num}
+}
+
+// catalog returns the document catalog
+func (doc *pdfDocument) catalog() (*pdfDict, error) {
+	cat := doc.dict(doc.trailer.Get("Root"))
+	if cat == nil {
+		return nil, errors.New("PDF catalog not found")
+	}
+	return cat, nil
+}
+
+// info returns the document information dictionary, it is created if the document does not have one
+func (doc *pdfDocument) info() *pdfDict {
+	if info := doc.dict(doc.trailer.Get("Info")); info != nil {
+		return info
+	}
+	info := newPDFDict()
+	doc.trailer.Set("Info", doc.add(info))
+	return info
+}
+
+// pages returns the references to all pages of the document in order
+func (doc *pdfDocument) pages() ([]pdfRef, error) {
+	cat, err := doc.catalog()
+	if err != nil {
+		return nil, err
+	}
+	root, ok := cat.Get("Pages").(pdfRef)
+	if !ok {
+		return nil, errors.New("PDF catalog has no page tree")
+	}
+	var pages []pdfRef
+	visited := make(map[int]bool)
+	var walk func(ref pdfRef) error
+	walk = func(ref pdfRef) error {
+		if visited[ref.num] {
+			return errors.New("PDF page tree contains a loop")
+		}
+		visited[ref.num] = true
+		node := doc.dict(ref)
+		if node == nil {
+			return fmt.Errorf("PDF page tree node %d not found", ref.num)
+		}
+		if node.Get("Type") == pdfName("Page") {
+			pages = append(pages, ref)
+			return nil
+		}
+		kids, _ := doc.resolve(node.Get("Kids")).(pdfArray)
+		for _, kid := range kids {
+			kidRef, ok := kid.(pdfRef)
+			if !ok {
+				return errors.New("PDF page tree contains a direct object")
+			}
+			if err := walk(kidRef); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return pages, walk(root)
+}
+
+// inheritedValue returns the value of key for a page, looking in the parent nodes if the page does not have it.
+// Only Resources, MediaBox, CropBox and Rotate can be inherited.
+func (doc *pdfDocument) inheritedValue(page *pdfDict, key pdfName) pdfObject {
+	for i := 0; page != nil && i < 64; i++ {
+		if v := page.Get(key); v != nil {
+			return v
+		}
+		page = doc.dict(page.Get("Parent"))
+	}
+	return nil
+}
+
+// setPages replaces the page tree of the document with a single node containing pages
+func (doc *pdfDocument) setPages(pages []pdfRef) error {
+	cat, err := doc.catalog()
+	if err != nil {
+		return err
+	}
+	rootRef, ok := cat.Get("Pages").(pdfRef)
+	if !ok {
+		return errors.New("PDF catalog has no page tree")
+	}
+	root := doc.dict(rootRef)
+
+	// pages lose their inherited attributes when their parent changes, so copy them to the page first
+	kids := make(pdfArray, 0, len(pages))
+	for _, ref := range pages {
+		page := doc.dict(ref)
+		if page == nil {
+			return fmt.Errorf("PDF page %d not found", ref.num)
+		}
+		for _, key := range []pdfName{"Resources", "MediaBox", "CropBox", "Rotate"} {
+			if page.Get(key) == nil {
+				if v := doc.inheritedValue(page, key); v != nil {
+					page.Set(key, v)
+				}
+			}
+		}
+		page.Set("Parent", rootRef)
+		kids = append(kids, ref)
+	}
+	root.Set("Kids", kids)
+	root.Set("Count", pdfInt(len(pages)))
+	root.Del("Parent")
+	return nil
+}
+
+// newBlankPage adds an empty page with the same size as the page like, it still has to be added with setPages
+func (doc *pdfDocument) newBlankPage(like pdfRef) pdfRef {
+	page := newPDFDict()
+	page.Set("Type", pdfName("Page"))
+	if mediaBox := doc.inheritedValue(doc.dict(like), "MediaBox"); mediaBox != nil {
+		page.Set("MediaBox", mediaBox)
+	}
+	page.Set("Resources", newPDFDict())
+	return doc.add(page)
+}
+
+// reachable returns the numbers of the objects which can be reached from the trailer. Pages which are not in the
+// page tree are left out, also when other objects (like a link to the page) still refer to them, so removed pages
+// and their content are not written.
+func (doc *pdfDocument) reachable() map[int]bool {
+	// without a valid page tree all pages are kept
+	var inTree map[int]bool
+	if pages, err := doc.pages(); err == nil {
+		inTree = make(map[int]bool, len(pages))
+		for _, ref := range pages {
+			inTree[ref.num] = true
+		}
+	}
+	seen := make(map[int]bool)
+	var stack []pdfObject
+	for _, key := range []pdfName{"Root", "Info", "ID", "Encrypt"} {
+		stack = append(stack, doc.trailer.Get(key))
+	}
+	for len(stack) > 0 {
+		obj := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if ref, ok := obj.(pdfRef); ok {
+			target, exists := doc.objects[ref.num]
+			if seen[ref.num] || !exists {
+				continue
+			}
+			if d := doc.dict(target); d != nil && d.Get("Type") == pdfName("Page") && inTree != nil && !inTree[ref.num] {
+				continue
+			}
+			seen[ref.num] = true
+			obj = target
+		}
+		switch v := obj.(type) {
+		case pdfArray:
+			stack = append(stack, v...)
+		case *pdfDict:
+			for _, key := range v.keys {
+				stack = append(stack, v.Get(key))
+			}
+		case *pdfStream:
+			// the length is written as a direct number by bytes
+			for _, key := range v.dict.keys {
+				if key != "Length" {
+					stack = append(stack, v.dict.Get(key))
+				}
+			}
+		}
+	}
+	return seen
+}
+
+// bytes writes the document as a new PDF file. Only the objects which are reachable from the trailer are written,
+// see reachable; references to the others are read as null by PDF readers.
+func (doc *pdfDocument) bytes() []byte {
+	reachable := doc.reachable()
+	nums := make([]int, 0, len(reachable))
+	size := 1
+	for num := range reachable {
+		nums = append(nums, num)
+		if num >= size {
+			size = num + 1
+		}
+	}
+	sort.Ints(nums)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%%PDF-%s\n%%\xe2\xe3\xcf\xd3\n", doc.version)
+	offsets := make(map[int]int, len(nums))
+	for _, num := range nums {
+		offsets[num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", num)
+		writePDFObject(&buf, doc.objects[num])
+		buf.WriteString("\nendobj\n")
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", size)
+	for num := 1; num < size; num++ {
+		if off, ok := offsets[num]; ok {
+			fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+		} else {
+			buf.WriteString("0000000000 00000 f \n")
+		}
+	}
+
+	trailer := newPDFDict()
+	trailer.Set("Size", pdfInt(size))
+	for _, key := range []pdfName{"Root", "Info", "ID", "Encrypt"} {
+		if v := doc.trailer.Get(key); v != nil {
+			trailer.Set(key, v)
+		}
+	}
+	buf.WriteString("trailer\n")
+	writePDFObject(&buf, trailer)
+	fmt.Fprintf(&buf, "\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.Bytes()
+}
+
+func writePDFObject(w *bytes.Buffer, obj pdfObject) {
+	switch v := obj.(type) {
+	case nil, pdfNull:
+		w.WriteString("null")
+	case bool:
+		if v {
+			w.WriteString("true")
+		} else {
+			w.WriteString("false")
+		}
+	case pdfNumber:
+		w.WriteString(string(v))
+	case pdfString:
+		w.WriteByte('(')
+		for _, c := range v {
+			switch c {
+			case '(', ')', '\\':
+				w.WriteByte('\\')
+				w.WriteByte(c)
+			case '\r':
+				w.WriteString(`\r`)
+			default:
+				w.WriteByte(c)
+			}
+		}
+		w.WriteByte(')')
+	case pdfHexString:
+		fmt.Fprintf(w, "<%X>", []byte(v))
+	case pdfName:
+		w.WriteByte('/')
+		for _, c := range []byte(v) {
+			if c < 0x21 || c > 0x7e || c == '#' || isPDFDelimiter(c) {
+				fmt.Fprintf(w, "#%02X", c)
+			} else {
+				w.WriteByte(c)
+			}
+		}
+	case pdfArray:
+		w.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				w.WriteByte(' ')
+			}
+			writePDFObject(w, item)
+		}
+		w.WriteByte(']')
+	case *pdfDict:
+		w.WriteString("<<")
+		for _, key := range v.keys {
+			writePDFObject(w, key)
+			w.WriteByte(' ')
+			writePDFObject(w, v.values[key])
+		}
+		w.WriteString(">>")
+	case *pdfStream:
+		v.dict.Set("Length", pdfInt(len(v.data)))
+		writePDFObject(w, v.dict)
+		w.WriteString("\nstream\n")
+		w.Write(v.data)
+		w.WriteString("\nendstream")
+	case pdfRef:
+		// objects are written with generation 0 by bytes, so references to them are as well
+		fmt.Fprintf(w, "%d 0 R", v.num)
+	default:
+		panic(fmt.Sprintf("unknown PDF object type %T", obj))
+	}
+}
+
+func isPDFWhitespace(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// pdfParser parses PDF objects from data, starting at pos
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+var errPDFSyntax = errors.New("PDF syntax error")
+
+// skip skips whitespace and comments
+func (p *pdfParser) skip() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isPDFWhitespace(c) {
+			p.pos++
+		} else if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+		} else {
+			return
+		}
+	}
+}
+
+// keyword reads a regular token (a keyword or number) without moving the position
+func (p *pdfParser) keyword() string {
+	end := p.pos
+	for end < len(p.data) && !isPDFWhitespace(p.data[end]) && !isPDFDelimiter(p.data[end]) {
+		end++
+	}
+	return string(p.data[p.pos:end])
+}
+
+// parseIndirect parses the object after "N G obj" and checks it is followed by endobj.
+// It parses the stream data if the object is a stream.
+func (p *pdfParser) parseIndirect() (pdfObject, error) {
+	obj, err := p.parseObject()
+	if err != nil {
+		return nil, err
+	}
+	p.skip()
+	if dict, ok := obj.(*pdfDict); ok && p.keyword() == "stream" {
+		p.pos += len("stream")
+		if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+			p.pos++
+		}
+		if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+			p.pos++
+		}
+		start := p.pos
+		end := -1
+
+		// use a direct Length if it points to endstream, else search for endstream
+		if n, ok := dict.Get("Length").(pdfNumber); ok {
+			if l, err := strconv.Atoi(string(n)); err == nil && l >= 0 && start+l <= len(p.data) {
+				q := &pdfParser{data: p.data, pos: start + l}
+				q.skip()
+				if q.keyword() == "endstream" {
+					end = start + l
+					p.pos = q.pos
+				}
+			}
+		}
+		if end < 0 {
+			i := bytes.Index(p.data[start:], []byte("endstream"))
+			if i < 0 {
+				return nil, errors.New("stream without endstream")
+			}
+			p.pos = start + i
+			end = p.pos
+			if end > start && p.data[end-1] == '\n' {
+				end--
+			}
+			if end > start && p.data[end-1] == '\r' {
+				end--
+			}
+		}
+		p.pos += len("endstream")
+		obj = &pdfStream{dict: dict, data: p.data[start:end]}
+		p.skip()
+	}
+	if p.keyword() != "endobj" {
+		return nil, errors.New("missing endobj")
+	}
+	p.pos += len("endobj")
+	return obj, nil
+}
+
+// parseObject parses a single direct object or reference
+func (p *pdfParser) parseObject() (pdfObject, error) {
+	p.skip()
+	if p.pos >= len(p.data) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	switch c := p.data[p.pos]; {
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		p.pos += 2
+		dict := newPDFDict()
+		for {
+			p.skip()
+			if p.pos+1 < len(p.data) && p.data[p.pos] == '>' && p.data[p.pos+1] == '>' {
+				p.pos += 2
+				return dict, nil
+			}
+			key, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(pdfName)
+			if !ok {
+				return nil, errPDFSyntax
+			}
+			value, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			dict.Set(name, value)
+		}
+	case c == '<':
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return nil, errPDFSyntax
+		}
+		var hex []byte
+		for _, h := range p.data[p.pos+1 : p.pos+end] {
+			if !isPDFWhitespace(h) {
+				hex = append(hex, h)
+			}
+		}
+		if len(hex)%2 == 1 {
+			hex = append(hex, '0')
+		}
+		s := make([]byte, len(hex)/2)
+		for i := range s {
+			v, err := strconv.ParseUint(string(hex[2*i:2*i+2]), 16, 8)
+			if err != nil {
+				return nil, errPDFSyntax
+			}
+			s[i] = byte(v)
+		}
+		p.pos += end + 1
+		return pdfHexString(s), nil
+	case c == '(':
+		return p.parseString()
+	case c == '[':
+		p.pos++
+		arr := pdfArray{}
+		for {
+			p.skip()
+			if p.pos < len(p.data) && p.data[p.pos] == ']' {
+				p.pos++
+				return arr, nil
+			}
+			item, err := p.parseObject()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, item)
+		}
+	case c == '/':
+		p.pos++
+		raw := p.keyword()
+		p.pos += len(raw)
+		var name []byte
+		for i := 0; i < len(raw); i++ {
+			if raw[i] == '#' && i+2 < len(raw) {
+				if v, err := strconv.ParseUint(raw[i+1:i+3], 16, 8); err == nil {
+					name = append(name, byte(v))
+					i += 2
+					continue
+				}
+			}
+			name = append(name, raw[i])
+		}
+		return pdfName(name), nil
+	}
+
+	kw := p.keyword()
+	switch kw {
+	case "":
+		return nil, errPDFSyntax
+	case "true", "false":
+		p.pos += len(kw)
+		return kw == "true", nil
+	case "null":
+		p.pos += len(kw)
+		return pdfNull{}, nil
+	}
+	if _, err := strconv.ParseFloat(kw, 64); err != nil {
+		return nil, fmt.Errorf("unexpected PDF token %q", kw)
+	}
+	p.pos += len(kw)
+
+	// check for a reference "num gen R"
+	if num, err := strconv.Atoi(kw); err == nil {
+		q := &pdfParser{data: p.data, pos: p.pos}
+		q.skip()
+		genStr := q.keyword()
+		if gen, err := strconv.Atoi(genStr); err == nil {
+			q.pos += len(genStr)
+			q.skip()
+			if q.keyword() == "R" {
+				p.pos = q.pos + 1
+				return pdfRef{num: num, gen: gen}, nil
+			}
+		}
+	}
+	return pdfNumber(kw), nil
+}
+
+func (p *pdfParser) parseString() (pdfObject, error) {
+	p.pos++ // (
+	var s []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(s), nil
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return nil, errPDFSyntax
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(v)
+				} else {
+					c = e
+				}
+			}
+		}
+		s = append(s, c)
+	}
+	return nil, errPDFSyntax
+}
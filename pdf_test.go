@@ -0,0 +1,244 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPDF returns a PDF with the given number of pages, in the same layout as the files written by wkhtmltopdf:
+// stream lengths are indirect objects and the page tree is a single node.
+func newTestPDF(pages int) []byte {
+	var objs []string
+	kids := ""
+	for i := 0; i < pages; i++ {
+		kids += fmt.Sprintf("%d 0 R ", 4+3*i)
+	}
+	objs = append(objs, "<< /Type /Catalog /Pages 2 0 R >>")
+	objs = append(objs, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 595 842] >>", kids, pages))
+	objs = append(objs, "<< /Creator (wkhtmltopdf 0.12.6) /Producer (Qt 4.8.7) /CreationDate (D:20240101120000+01'00') >>")
+	for i := 0; i < pages; i++ {
+		content := testPageContent(i + 1)
+		objs = append(objs, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R /Resources << >> >>", 5+3*i))
+		objs = append(objs, fmt.Sprintf("<< /Length %d 0 R >>\nstream\n%s\nendstream", 6+3*i, content))
+		objs = append(objs, fmt.Sprintf("%d", len(content)))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Info 3 0 R /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return buf.Bytes()
+}
+
+// testPageContent returns the content stream of page n (counting from 1) of newTestPDF
+func testPageContent(n int) string {
+	return fmt.Sprintf("BT /F1 12 Tf 72 720 Td (Page %d) Tj ET", n)
+}
+
+// testPDFPageTexts returns the decoded content streams of all pages
+func testPDFPageTexts(t *testing.T, pdf []byte) []string {
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	var texts []string
+	for _, ref := range pages {
+		page := doc.dict(ref)
+		s, ok := doc.resolve(page.Get("Contents")).(*pdfStream)
+		if !ok {
+			texts = append(texts, "")
+			continue
+		}
+		data, err := doc.decodeStream(s)
+		require.NoError(t, err)
+		texts = append(texts, string(data))
+	}
+	return texts
+}
+
+func TestParsePDF(t *testing.T) {
+	doc, err := parsePDF(newTestPDF(3))
+	require.NoError(t, err)
+
+	assert.Equal(t, "1.4", doc.version)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	assert.Equal(t, pdfName("Catalog"), cat.Get("Type"))
+	assert.Equal(t, pdfString("wkhtmltopdf 0.12.6"), doc.info().Get("Creator"))
+
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	assert.Len(t, pages, 3)
+
+	// the stream length is an indirect object, so the data is found by searching for endstream
+	s, ok := doc.resolve(doc.dict(pages[1]).Get("Contents")).(*pdfStream)
+	require.True(t, ok)
+	assert.Equal(t, "BT /F1 12 Tf 72 720 Td (Page 2) Tj ET", string(s.data))
+}
+
+func TestParsePDFErrors(t *testing.T) {
+	_, err := parsePDF([]byte("<html></html>"))
+	assert.Equal(t, errNotPDF, err)
+
+	_, err = parsePDF([]byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog >>\nendobj\n"))
+	assert.EqualError(t, err, "PDF trailer not found")
+
+	_, err = parsePDF([]byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog \nendobj\n"))
+	assert.Error(t, err)
+}
+
+func TestPDFRoundTrip(t *testing.T) {
+	doc, err := parsePDF(newTestPDF(2))
+	require.NoError(t, err)
+
+	// write and read again, the result must be the same
+	out := doc.bytes()
+	doc2, err := parsePDF(out)
+	require.NoError(t, err)
+	assert.Equal(t, out, doc2.bytes())
+	assert.Equal(t, []string{
+		"BT /F1 12 Tf 72 720 Td (Page 1) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 2) Tj ET",
+	}, testPDFPageTexts(t, out))
+}
+
+func TestPDFObjects(t *testing.T) {
+	tests := []struct {
+		in   string
+		want pdfObject
+		out  string
+	}{
+		{"123", pdfNumber("123"), "123"},
+		{"-1.50", pdfNumber("-1.50"), "-1.50"},
+		{"true", true, "true"},
+		{"null", pdfNull{}, "null"},
+		{"/Name#20With#23Space", pdfName("Name With#Space"), "/Name#20With#23Space"},
+		{`(a \(nested (string)\) \\ \101\n)`, pdfString("a (nested (string)) \\ A\n"), `(a \(nested \(string\)\) \\ A` + "\n)"},
+		{"<48656C6C6F>", pdfHexString("Hello"), "<48656C6C6F>"},
+		{"[1 0 R 2 /A]", pdfArray{pdfRef{1, 0}, pdfNumber("2"), pdfName("A")}, "[1 0 R 2 /A]"},
+	}
+	for _, tt := range tests {
+		p := &pdfParser{data: []byte(tt.in)}
+		obj, err := p.parseObject()
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.want, obj, tt.in)
+		var buf bytes.Buffer
+		writePDFObject(&buf, obj)
+		assert.Equal(t, tt.out, buf.String(), tt.in)
+	}
+
+	p := &pdfParser{data: []byte("<< /B 1 /A << /C [ ] >> >>")}
+	obj, err := p.parseObject()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	writePDFObject(&buf, obj)
+	assert.Equal(t, "<</B 1/A <</C []>>>>", buf.String())
+}
+
+func TestParsePDFObjectStream(t *testing.T) {
+	// a file using a compressed object stream and a cross-reference stream, like many modern PDF producers write
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 100 100] >>",
+	}
+	header, body := "", ""
+	for i, obj := range objs {
+		header += fmt.Sprintf("%d %d ", i+1, len(body))
+		body += obj + " "
+	}
+	objStm := header + body
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	zw.Write([]byte(objStm))
+	zw.Close()
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	fmt.Fprintf(&buf, "4 0 obj\n<< /Type /ObjStm /N 3 /First %d /Filter /FlateDecode /Length %d >>\nstream\n", len(header), compressed.Len())
+	buf.Write(compressed.Bytes())
+	buf.WriteString("\nendstream\nendobj\n")
+	buf.WriteString("5 0 obj\n<< /Type /XRef /Size 6 /Root 1 0 R /W [1 2 1] /Length 0 >>\nstream\n\nendstream\nendobj\n")
+	buf.WriteString("startxref\n0\n%%EOF\n")
+
+	doc, err := parsePDF(buf.Bytes())
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	assert.Equal(t, []pdfRef{{num: 3}}, pages)
+
+	// written back without object streams
+	out := doc.bytes()
+	assert.NotContains(t, string(out), "ObjStm")
+	assert.NotContains(t, string(out), "XRef")
+	_, err = parsePDF(out)
+	assert.NoError(t, err)
+}
+
+func TestPDFSetPages(t *testing.T) {
+	doc, err := parsePDF(newTestPDF(3))
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+
+	require.NoError(t, doc.setPages([]pdfRef{pages[2], pages[0]}))
+
+	// inherited attributes are copied to the pages
+	assert.Equal(t, pdfArray{pdfNumber("0"), pdfNumber("0"), pdfNumber("595"), pdfNumber("842")}, doc.dict(pages[2]).Get("MediaBox"))
+	assert.Equal(t, []string{
+		"BT /F1 12 Tf 72 720 Td (Page 3) Tj ET",
+		"BT /F1 12 Tf 72 720 Td (Page 1) Tj ET",
+	}, testPDFPageTexts(t, doc.bytes()))
+}
+
+func TestPDFGenerations(t *testing.T) {
+	// an object with generation 1, like from a file with incremental updates which reused the object number
+	data := bytes.ReplaceAll(newTestPDF(1), []byte("3 0 obj"), []byte("3 1 obj"))
+	data = bytes.ReplaceAll(data, []byte("3 0 R"), []byte("3 1 R"))
+	require.Contains(t, string(data), "3 1 R")
+	doc, err := parsePDF(data)
+	require.NoError(t, err)
+
+	out := doc.bytes()
+	assert.Contains(t, string(out), "3 0 obj")
+	assert.NotContains(t, string(out), "3 1 R")
+	assert.Equal(t, []string{"BT /F1 12 Tf 72 720 Td (Page 1) Tj ET"}, testPDFPageTexts(t, out))
+}
+
+func TestPDFBytesUnreachable(t *testing.T) {
+	doc, err := parsePDF(newTestPDF(3))
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	// a link on page 1 to page 3 doesn't keep page 3 in the file when it is removed
+	link := newPDFDict()
+	link.Set("Subtype", pdfName("Link"))
+	link.Set("Dest", pdfArray{pages[2], pdfName("Fit")})
+	doc.dict(pages[0]).Set("Annots", pdfArray{doc.add(link)})
+	orphan := doc.add(pdfString("orphan"))
+	require.NoError(t, doc.setPages(pages[:2]))
+
+	out := doc.bytes()
+	assert.Contains(t, string(out), "(Page 2)")
+	assert.NotContains(t, string(out), "(Page 3)")
+	assert.NotContains(t, string(out), "(orphan)")
+	assert.Contains(t, string(out), fmt.Sprintf("/Dest [%d 0 R /Fit]", pages[2].num), "references to removed objects are read as null")
+	doc, err = parsePDF(out)
+	require.NoError(t, err)
+	assert.NotContains(t, doc.objects, pages[2].num)
+	assert.NotContains(t, doc.objects, orphan.num)
+}
@@ -0,0 +1,85 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// pdfVersions are the versions SetPDFVersion accepts, from old to new
+var pdfVersions = []string{"1.4", "1.5", "1.6", "1.7", "2.0"}
+
+// SetPDFVersion sets the version of the generated PDF, like "1.4" for older viewers and tools or "2.0".
+// wkhtmltopdf always writes PDF 1.4 and has no option for it, so the version in the header (%PDF-1.4) is rewritten
+// by post-processing the PDF. This runs after all other post-processing, including the post-processors added with
+// AddPostProcessor, and removes a /Version from the catalog, so the header is the only version in the file.
+// Only the version number is changed, the content is not converted: the post-processed PDF is written with a
+// classic cross-reference table and without object streams, which all versions can read, and wkhtmltopdf and the
+// built-in post-processing only use PDF 1.4 features. An older version is rejected when Create finds a feature it
+// doesn't have, like AES encryption (1.6) or AES-256 encryption (2.0) added by a post-processor, and newer
+// versions don't remove features deprecated by PDF 2.0, like the document information dictionary.
+// The supported versions are 1.4, 1.5, 1.6, 1.7 and 2.0; an empty version keeps the version of the PDF.
+func (pdfg *PDFGenerator) SetPDFVersion(version string) error {
+	if version != "" && !slices.Contains(pdfVersions, version) {
+		return fmt.Errorf("unsupported PDF version %q, use one of %s", version, strings.Join(pdfVersions, ", "))
+	}
+	pdfg.pdfVersion = version
+	return nil
+}
+
+// setPDFVersion returns a post-processor which writes version in the header of the PDF
+func setPDFVersion(version string) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			if required, feature := doc.requiredVersion(); required > version {
+				return fmt.Errorf("can't write PDF %s, the PDF uses %s, which requires PDF %s", version, feature, required)
+			}
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			cat.Del("Version")
+			doc.version = version
+			return nil
+		})
+	}
+}
+
+// requiredVersion returns the lowest version which supports the features of the document SetPDFVersion checks,
+// and the newest of these features
+func (doc *pdfDocument) requiredVersion() (version, feature string) {
+	version = "1.4"
+	needs := func(v, f string) {
+		if v > version {
+			version, feature = v, f
+		}
+	}
+
+	if cat, err := doc.catalog(); err == nil && cat.Get("OCProperties") != nil {
+		needs("1.5", "optional content (layers)")
+	}
+	encrypt, ok := doc.resolve(doc.trailer.Get("Encrypt")).(*pdfDict)
+	if !ok {
+		return version, feature
+	}
+	number := func(key pdfName) int {
+		n, _ := doc.resolve(encrypt.Get(key)).(pdfNumber)
+		i, _ := strconv.Atoi(string(n))
+		return i
+	}
+	switch v := number("V"); {
+	case v >= 5:
+		needs("2.0", "AES-256 encryption")
+	case v == 4:
+		needs("1.5", "crypt filters")
+		if filters, ok := doc.resolve(encrypt.Get("CF")).(*pdfDict); ok {
+			for _, key := range filters.keys {
+				if cf, ok := doc.resolve(filters.Get(key)).(*pdfDict); ok && cf.Get("CFM") == pdfName("AESV2") {
+					needs("1.6", "AES encryption")
+				}
+			}
+		}
+	}
+	return version, feature
+}
@@ -0,0 +1,111 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetPDFVersion(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.SetPDFVersion("1.3"), `unsupported PDF version "1.3", use one of 1.4, 1.5, 1.6, 1.7, 2.0`)
+	assert.EqualError(t, pdfg.SetPDFVersion("2"), `unsupported PDF version "2", use one of 1.4, 1.5, 1.6, 1.7, 2.0`)
+	assert.Empty(t, pdfg.postProcessors())
+
+	// the version is set after the post-processors added with AddPostProcessor
+	pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) {
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+		return pdf, nil
+	})
+	require.NoError(t, pdfg.SetPDFVersion("1.7"))
+	processors := pdfg.postProcessors()
+	require.Len(t, processors, 2)
+
+	pdf := newTestPDF(2)
+	for _, process := range processors {
+		var err error
+		pdf, err = process(pdf)
+		require.NoError(t, err)
+	}
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.7\n")))
+	assert.Equal(t, []string{testPageContent(1), testPageContent(2)}, testPDFPageTexts(t, pdf))
+
+	// an empty version keeps the version
+	require.NoError(t, pdfg.SetPDFVersion(""))
+	assert.Len(t, pdfg.postProcessors(), 1)
+}
+
+func TestSetPDFVersionHeader(t *testing.T) {
+	for _, version := range pdfVersions {
+		pdf, err := setPDFVersion(version)(newTestPDF(1))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-"+version+"\n")), version)
+		doc, err := parsePDF(pdf)
+		require.NoError(t, err)
+		assert.Equal(t, version, doc.version)
+	}
+
+	// a downgrade removes the /Version of the catalog, which would override the header
+	pdf, err := modifyPDF(newTestPDF(1), func(doc *pdfDocument) error {
+		cat, err := doc.catalog()
+		cat.Set("Version", pdfName("1.7"))
+		doc.version = "1.7"
+		return err
+	})
+	require.NoError(t, err)
+	pdf, err = setPDFVersion("1.4")(pdf)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	assert.Nil(t, cat.Get("Version"))
+}
+
+// encryptTestPDF adds an /Encrypt dictionary with the version v and crypt filter method cfm to a test PDF
+func encryptTestPDF(t *testing.T, v int, cfm string) []byte {
+	pdf, err := modifyPDF(newTestPDF(1), func(doc *pdfDocument) error {
+		encrypt := newPDFDict()
+		encrypt.Set("Filter", pdfName("Standard"))
+		encrypt.Set("V", pdfInt(v))
+		if cfm != "" {
+			cf := newPDFDict()
+			cf.Set("CFM", pdfName(cfm))
+			filters := newPDFDict()
+			filters.Set("StdCF", cf)
+			encrypt.Set("CF", filters)
+		}
+		doc.trailer.Set("Encrypt", doc.add(encrypt))
+		return nil
+	})
+	require.NoError(t, err)
+	return pdf
+}
+
+func TestSetPDFVersionIncompatible(t *testing.T) {
+	tests := []struct {
+		pdf      []byte
+		version  string
+		required string
+		feature  string
+	}{
+		{encryptTestPDF(t, 2, ""), "1.4", "", ""},
+		{encryptTestPDF(t, 4, "V2"), "1.4", "1.5", "crypt filters"},
+		{encryptTestPDF(t, 4, "AESV2"), "1.5", "1.6", "AES encryption"},
+		{encryptTestPDF(t, 4, "AESV2"), "1.6", "", ""},
+		{encryptTestPDF(t, 5, "AESV3"), "1.7", "2.0", "AES-256 encryption"},
+		{encryptTestPDF(t, 5, "AESV3"), "2.0", "", ""},
+	}
+	for _, tt := range tests {
+		pdf, err := setPDFVersion(tt.version)(tt.pdf)
+		if tt.required == "" {
+			require.NoError(t, err, tt.version)
+			assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-"+tt.version+"\n")))
+			continue
+		}
+		assert.EqualError(t, err, "can't write PDF "+tt.version+", the PDF uses "+tt.feature+", which requires PDF "+tt.required)
+	}
+}
@@ -0,0 +1,142 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// PostProcessor modifies the PDF generated by wkhtmltopdf, it receives the PDF and returns the modified PDF
+type PostProcessor func(pdf []byte) ([]byte, error)
+
+// AddPostProcessor adds a function which is called with the generated PDF after wkhtmltopdf has run successfully.
+// The PDF it returns replaces the generated PDF, before it is stored in the internal buffer (used by Bytes and
+// WriteFile), written to the writer set by SetOutput or to OutputFile.
+// Post-processors run in the order they were added, after the built-in post-processing (like SetLang),
+// except SetDeterministic and SetPDFVersion, which run last.
+// If a post-processor returns an error, the remaining ones are not called and Create returns the error.
+func (pdfg *PDFGenerator) AddPostProcessor(p PostProcessor) {
+	pdfg.postProcessFuncs = append(pdfg.postProcessFuncs, p)
+}
+
+// insertBuiltIn inserts p in processors returned by postProcessors, after the other built-in post-processing and
+// before the post-processors added by AddPostProcessor
+func (pdfg *PDFGenerator) insertBuiltIn(processors []PostProcessor, p PostProcessor) []PostProcessor {
+	i := len(processors) - len(pdfg.postProcessFuncs)
+	if pdfg.deterministic {
+		i-- // SetDeterministic runs after the post-processors added by AddPostProcessor
+	}
+	if pdfg.pdfVersion != "" {
+		i-- // SetPDFVersion runs last
+	}
+	return slices.Insert(processors, i, p)
+}
+
+// postProcessors returns the post-processing steps needed for the current settings, in the order they are applied
+func (pdfg *PDFGenerator) postProcessors() []PostProcessor {
+	var processors []PostProcessor
+	if pdfg.pageGroupPages != nil && pdfg.hasIncludePages() {
+		ranges := make([][]pageRange, len(pdfg.pages))
+		for i, page := range pdfg.pages {
+			ranges[i] = page.Options().includePages
+		}
+		processors = append(processors, selectPageRanges(pdfg.pageGroupPages, ranges))
+	}
+	if pdfg.lang != "" {
+		processors = append(processors, setCatalogLang(pdfg.lang))
+	}
+	if pdfg.outputIntent != nil {
+		processors = append(processors, setOutputIntent(pdfg.outputIntent))
+	}
+	if pdfg.bodyPages > 0 {
+		processors = append(processors, padBodyToOdd(pdfg.bodyPages))
+	}
+	if len(pdfg.appendedPDFs) > 0 {
+		processors = append(processors, insertPDFs(pdfg.keptPageCounts(), pdfg.appendedPDFs))
+	}
+	if pdfg.trimBlankPages {
+		processors = append(processors, TrimTrailingBlankPages())
+	}
+	if n := pdfg.nUp; n != nil {
+		processors = append(processors, NUpPages(n.cols, n.rows, n.layout))
+	}
+	if len(pdfg.pageLabels) > 0 {
+		processors = append(processors, setPageLabels(pdfg.pageLabels))
+	}
+	if pdfg.viewerPrefs != nil {
+		processors = append(processors, setViewerPreferences(*pdfg.viewerPrefs))
+	}
+	processors = append(processors, pdfg.postProcessFuncs...)
+	if pdfg.deterministic {
+		processors = append(processors, normalizeForReproducibility(deterministicTime()))
+	}
+	if pdfg.pdfVersion != "" {
+		processors = append(processors, setPDFVersion(pdfg.pdfVersion))
+	}
+	return processors
+}
+
+// postProcess applies processors to the generated PDF and writes the result back to where the output should go.
+// buf contains the PDF if the output is sent to a custom writer.
+func (pdfg *PDFGenerator) postProcess(processors []PostProcessor, buf *bytes.Buffer) error {
+	var pdf []byte
+	switch {
+	case pdfg.OutputFile != "":
+		b, err := os.ReadFile(pdfg.OutputFile)
+		if err != nil {
+			return fmt.Errorf("error reading output file for post-processing: %w", err)
+		}
+		pdf = b
+	case buf != nil:
+		pdf = buf.Bytes()
+	default:
+		pdf = append([]byte{}, pdfg.outbuf.Bytes()...)
+	}
+
+	for _, process := range processors {
+		var err error
+		pdf, err = process(pdf)
+		if err != nil {
+			return fmt.Errorf("error post-processing PDF: %w", err)
+		}
+	}
+
+	switch {
+	case pdfg.OutputFile != "":
+		return os.WriteFile(pdfg.OutputFile, pdf, 0666)
+	case buf != nil:
+		_, err := pdfg.outWriter.Write(pdf)
+		return err
+	default:
+		pdfg.outbuf.Reset()
+		pdfg.outbuf.Write(pdf)
+	}
+	return nil
+}
+
+// modifyPDF parses pdf, calls modify with the parsed document and returns the modified PDF
+func modifyPDF(pdf []byte, modify func(doc *pdfDocument) error) ([]byte, error) {
+	doc, err := parsePDF(pdf)
+	if err != nil {
+		return nil, err
+	}
+	if err := modify(doc); err != nil {
+		return nil, err
+	}
+	return doc.bytes(), nil
+}
+
+// setCatalogLang sets /Lang in the document catalog
+func setCatalogLang(lang string) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			cat.Set("Lang", pdfString(lang))
+			return nil
+		})
+	}
+}
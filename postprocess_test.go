@@ -0,0 +1,156 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetCatalogLang(t *testing.T) {
+	out, err := setCatalogLang("en-US")(newTestPDF(1))
+	require.NoError(t, err)
+
+	doc, err := parsePDF(out)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	assert.Equal(t, pdfString("en-US"), cat.Get("Lang"))
+
+	_, err = setCatalogLang("en-US")([]byte("not a pdf"))
+	assert.Error(t, err)
+}
+
+func TestSetLang(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.SetLang("nl-NL")
+
+	mdPage := NewMarkdownPage("testdata/testmd.md")
+	pdfg.AddPage(mdPage)
+	assert.Equal(t, "nl-NL", mdPage.Lang)
+
+	html, err := io.ReadAll(mdPage.Reader())
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(html, []byte(`<!DOCTYPE html><html lang="nl-NL"><head>`)))
+
+	err = pdfg.Create()
+	require.NoError(t, err)
+
+	doc, err := parsePDF(pdfg.Bytes())
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	assert.Equal(t, pdfString("nl-NL"), cat.Get("Lang"))
+}
+
+func TestAddPostProcessor(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPageReader(bytes.NewReader([]byte("<html><body>Hello</body></html>"))))
+
+	var called []string
+	pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) {
+		called = append(called, "noop")
+		return pdf, nil
+	})
+	pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) {
+		called = append(called, "append")
+		return append(pdf, "% appended\n"...), nil
+	})
+
+	err = pdfg.Create()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"noop", "append"}, called)
+	assert.True(t, bytes.HasPrefix(pdfg.Bytes(), []byte("%PDF-")))
+	assert.True(t, bytes.HasSuffix(pdfg.Bytes(), []byte("% appended\n")))
+}
+
+func TestPostProcessOutput(t *testing.T) {
+	appendMarker := func(pdf []byte) ([]byte, error) {
+		return append(pdf, "% appended\n"...), nil
+	}
+
+	// internal buffer
+	pdfg := NewPDFPreparer()
+	pdfg.outbuf.WriteString("%PDF-1.4\n")
+	require.NoError(t, pdfg.postProcess([]PostProcessor{appendMarker}, nil))
+	assert.Equal(t, "%PDF-1.4\n% appended\n", pdfg.Buffer().String())
+
+	// custom writer
+	out := new(bytes.Buffer)
+	pdfg = NewPDFPreparer()
+	pdfg.SetOutput(out)
+	require.NoError(t, pdfg.postProcess([]PostProcessor{appendMarker}, bytes.NewBufferString("%PDF-1.4\n")))
+	assert.Equal(t, "%PDF-1.4\n% appended\n", out.String())
+
+	// output file
+	pdfg = NewPDFPreparer()
+	pdfg.OutputFile = filepath.Join(t.TempDir(), "out.pdf")
+	require.NoError(t, os.WriteFile(pdfg.OutputFile, []byte("%PDF-1.4\n"), 0666))
+	require.NoError(t, pdfg.postProcess([]PostProcessor{appendMarker}, nil))
+	b, err := os.ReadFile(pdfg.OutputFile)
+	require.NoError(t, err)
+	assert.Equal(t, "%PDF-1.4\n% appended\n", string(b))
+}
+
+func TestPostProcessError(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.outbuf.WriteString("%PDF-1.4\n")
+
+	secondCalled := false
+	err := pdfg.postProcess([]PostProcessor{
+		func(pdf []byte) ([]byte, error) { return nil, errors.New("failed") },
+		func(pdf []byte) ([]byte, error) { secondCalled = true; return pdf, nil },
+	}, nil)
+	assert.EqualError(t, err, "error post-processing PDF: failed")
+	assert.False(t, secondCalled)
+	assert.Equal(t, "%PDF-1.4\n", pdfg.Buffer().String())
+}
+
+func TestInsertBuiltIn(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	var order []string
+	step := func(name string) PostProcessor {
+		return func(pdf []byte) ([]byte, error) {
+			order = append(order, name)
+			return pdf, nil
+		}
+	}
+	pdfg.SetLang("en")
+	pdfg.AddPostProcessor(step("user"))
+	require.NoError(t, pdfg.SetPDFVersion("1.7"))
+
+	// built-in steps added at render time run before the user's post-processors, and the version is still set last
+	processors := pdfg.insertBuiltIn(pdfg.postProcessors(), step("built-in"))
+	require.Len(t, processors, 4)
+	pdf := newTestPDF(1)
+	for _, process := range processors {
+		var err error
+		pdf, err = process(pdf)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"built-in", "user"}, order)
+	assert.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.7\n")))
+
+	// deterministic output is normalized after the user's post-processors
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	order = nil
+	pdfg.SetDeterministic(true)
+	processors = pdfg.insertBuiltIn(pdfg.postProcessors(), step("built-in"))
+	require.Len(t, processors, 5)
+	pdf, err := processors[2](newTestPDF(1))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user"}, order)
+	pdf, err = processors[3](pdf)
+	require.NoError(t, err)
+	assert.Contains(t, string(pdf), "/CreationDate (D:19700101000000Z)")
+
+	pdfg = NewPDFPreparer()
+	assert.Len(t, pdfg.insertBuiltIn(pdfg.postProcessors(), step("built-in")), 1)
+}
@@ -0,0 +1,347 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// prepare makes all changes needed just before wkhtmltopdf is called, like writing temporary files.
+// The returned function undoes these changes and removes the temporary files, it must always be called.
+// Style sheets are fetched from URLs with ctx.
+func (pdfg *PDFGenerator) prepare(ctx context.Context) (func(), error) {
+	var restore []func()
+	cleanup := func() {
+		for i := len(restore) - 1; i >= 0; i-- {
+			restore[i]()
+		}
+		pdfg.removeTempFiles()
+	}
+
+	restoreReplace, err := pdfg.expandReplaceEnv()
+	if err != nil {
+		return nil, err
+	}
+	restore = append(restore, restoreReplace)
+
+	// the style sheet for the page size is used instead of the default style sheet, see SetUserStyleSheetFor
+	if styleSheet := pdfg.sizeStyleSheet(); styleSheet != "" {
+		for _, page := range pdfg.pages {
+			opts := page.Options()
+			original := opts.UserStyleSheet.value
+			if original != "" && original != pdfg.userStyleSheetPath {
+				continue
+			}
+			opts.UserStyleSheet.Set(styleSheet)
+			restore = append(restore, func() {
+				if original == "" {
+					opts.UserStyleSheet.Unset()
+				} else {
+					opts.UserStyleSheet.Set(original)
+				}
+			})
+		}
+	}
+
+	// --user-style-sheet must be a local file, so style sheets from http(s) URLs are fetched first
+	fetcher := &styleSheetFetcher{pdfg: pdfg, paths: make(map[string]string)}
+	for _, page := range pdfg.pages {
+		opts := page.Options()
+		if original := opts.UserStyleSheet.value; isHTTPURL(original) {
+			path, err := fetcher.localPath(ctx, original)
+			if err != nil {
+				cleanup()
+				return nil, err
+			}
+			opts.UserStyleSheet.Set(path)
+			restore = append(restore, func() { opts.UserStyleSheet.Set(original) })
+		}
+	}
+
+	// concatenate the style sheets set with SetUserStyleSheets and use them for pages without a style sheet
+	if len(pdfg.userStyleSheets) > 0 {
+		var css []byte
+		for _, styleSheet := range pdfg.userStyleSheets {
+			path, err := fetcher.localPath(ctx, styleSheet)
+			if err != nil {
+				cleanup()
+				return nil, err
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				cleanup()
+				return nil, fmt.Errorf("error reading user style sheet: %w", err)
+			}
+			css = append(append(css, b...), '\n')
+		}
+		path, err := pdfg.createTempFile("stylesheet-*.css", css)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		for _, page := range pdfg.pages {
+			opts := page.Options()
+			if opts.UserStyleSheet.value == "" {
+				opts.UserStyleSheet.Set(path)
+				restore = append(restore, func() { opts.UserStyleSheet.Unset() })
+			}
+		}
+	}
+
+	// the header logo is written to a header HTML file for pages without a header
+	if pdfg.headerLogo != nil {
+		header, err := pdfg.headerLogo.html()
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		path, err := pdfg.createTempFile("header-*.html", header)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		for _, page := range pdfg.pages {
+			opts := page.Options()
+			if opts.HeaderHTML.value == "" {
+				opts.HeaderHTML.Set(path)
+				restore = append(restore, func() { opts.HeaderHTML.Unset() })
+			}
+		}
+	}
+
+	// the TOC configured with ConfigureTOC is styled by a generated XSL style sheet
+	if pdfg.tocConfig != nil && pdfg.TOC.Include {
+		path, err := pdfg.createTempFile("toc-*.xsl", pdfg.tocConfig.xsl())
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+		original := pdfg.TOC.XslStyleSheet.value
+		pdfg.TOC.XslStyleSheet.Set(path)
+		restore = append(restore, func() { pdfg.TOC.XslStyleSheet.value = original })
+	}
+
+	for _, page := range pdfg.pages {
+		opts := page.Options()
+
+		// pages read from stdin get the inline CSS injected in the HTML, see stdinReader
+		if css := []byte(opts.injectedCSS()); len(css) > 0 && page.Reader() == nil {
+			if opts.UserStyleSheet.value != "" {
+				existing, err := os.ReadFile(opts.UserStyleSheet.value)
+				if err != nil {
+					cleanup()
+					return nil, fmt.Errorf("error reading user style sheet for inline CSS: %w", err)
+				}
+				css = append(append(existing, '\n'), css...)
+			}
+			path, err := pdfg.createTempFile("inline-*.css", css)
+			if err != nil {
+				cleanup()
+				return nil, err
+			}
+			original := opts.UserStyleSheet.value
+			opts.UserStyleSheet.Set(path)
+			restore = append(restore, func() { opts.UserStyleSheet.value = original })
+		}
+	}
+
+	// wkhtmltopdf can read only one page from stdin, the content of other pages is written to temporary files
+	if err := pdfg.spillStdinPages(); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if err := pdfg.filterFilePages(); err != nil {
+		cleanup()
+		return nil, err
+	}
+	restore = append(restore, func() { pdfg.spilled = nil })
+
+	restoreOrientation, err := pdfg.applyAutoOrientation()
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	restore = append(restore, restoreOrientation)
+
+	return cleanup, nil
+}
+
+// SetTempFileHook sets a function which is called with the path of each temporary file the generator writes for a
+// run, with created true when the file is created and false when it is removed, as an audit trail of the files
+// written and to verify they are cleaned up. Temporary files are written for pages read from memory which can't
+// be passed via stdin (see SetSpillFileExtension), pages filtered by SetResourceAllowlist, fetched and combined
+// style sheets, inline CSS of file pages and the header logo. They are removed at the end of Create (and
+// RenderHTMLOnly); a file which can't be removed is not reported as removed. The hook is called from the
+// goroutine running Create, nil turns it off.
+func (pdfg *PDFGenerator) SetTempFileHook(hook func(path string, created bool)) {
+	pdfg.tempFileHook = hook
+}
+
+// SetSpillFileExtension sets the extension (like ".htm") of the temporary files used for pages read from memory
+// (PageReader, MarkdownPage, AsciiDocPage and RenderedPage) when there is more than one such page. wkhtmltopdf
+// reads only the first of them from stdin, the content of the others is written to temporary files, which are
+// removed after Create. wkhtmltopdf decides how to load a local file by its extension, so the default is ".html".
+func (pdfg *PDFGenerator) SetSpillFileExtension(ext string) {
+	pdfg.spillExt = ext
+}
+
+// DefaultStdinSpillThreshold is the size of the page read from stdin above which it is written to a temporary
+// file instead, see SetStdinSpillThreshold
+const DefaultStdinSpillThreshold = 16 << 20
+
+// SetStdinSpillThreshold sets the size in bytes of the HTML of the page read from memory which is passed to
+// wkhtmltopdf via stdin, above which it is written to a temporary file like the other pages from memory (see
+// SetSpillFileExtension). Very large content on stdin can make wkhtmltopdf stall or truncate the input on some
+// platforms. The default is DefaultStdinSpillThreshold (16 MiB) when bytes is 0; a negative value always uses
+// stdin. To measure the size, the HTML of the page is read into memory before wkhtmltopdf is started.
+func (pdfg *PDFGenerator) SetStdinSpillThreshold(bytes int) {
+	pdfg.stdinSpillSize = bytes
+}
+
+// spillStdinPages writes the content of all pages read from memory but the first to temporary files, and the first
+// too if it is larger than the stdin spill threshold
+func (pdfg *PDFGenerator) spillStdinPages() error {
+	ext := pdfg.spillExt
+	if ext == "" {
+		ext = ".html"
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	threshold := pdfg.stdinSpillSize
+	if threshold == 0 {
+		threshold = DefaultStdinSpillThreshold
+	}
+	first := true
+	for i, page := range pdfg.pages {
+		if page.Reader() == nil {
+			continue
+		}
+		if first && threshold < 0 {
+			first = false
+			continue
+		}
+
+		// the content of a PageReader can be read only once, it is buffered to keep it for stdin
+		pr, isReader := page.(*PageReader)
+		var raw []byte
+		if first && isReader && pr.Input != nil {
+			var err error
+			if raw, err = io.ReadAll(pr.Input); err != nil {
+				return fmt.Errorf("error reading content of page %d: %w", i+1, err)
+			}
+			pr.Input = bytes.NewReader(raw)
+		}
+		r, err := stdinReader(page)
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("error reading content of page %d: %w", i+1, err)
+		}
+		if first {
+			first = false
+			if len(content) <= threshold {
+				if raw != nil {
+					pr.Input = bytes.NewReader(raw)
+				}
+				continue
+			}
+		}
+		path, err := pdfg.createTempFile("page-*"+ext, content)
+		if err != nil {
+			return err
+		}
+		if pdfg.spilled == nil {
+			pdfg.spilled = make(map[int]string)
+		}
+		pdfg.spilled[i] = path
+	}
+	return nil
+}
+
+// pageInput returns the input of page i for the command line, the temporary file if its content was spilled
+func (pdfg *PDFGenerator) pageInput(i int) string {
+	if path, ok := pdfg.spilled[i]; ok {
+		return path
+	}
+	return pdfg.pages[i].InputFile()
+}
+
+// stdinReader returns the reader for a page which is passed to wkhtmltopdf via stdin,
+// with all content injected which was set on its PageOptions and resources removed which are not allowed.
+func stdinReader(page PageProvider) (io.Reader, error) {
+	r := page.Reader()
+	opts := page.Options()
+	css := opts.injectedCSS()
+	if css == "" && opts.allowlist == nil && opts.csp == "" {
+		return r, nil
+	}
+	html, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if opts.allowlist != nil {
+		html = filterResources(html, opts.allowlist)
+	}
+	if css != "" {
+		html = injectIntoHead(html, "<style>"+css+"</style>")
+	}
+	if opts.csp != "" {
+		html = injectAtHeadStart(html, cspMeta(opts.csp))
+	}
+	return bytes.NewReader(html), nil
+}
+
+var headEndRegex = regexp.MustCompile(`(?i)</head\s*>`)
+
+// injectIntoHead inserts snippet just before the closing head tag of an HTML document.
+// If the document has no head, the snippet is put in front of the document.
+func injectIntoHead(html []byte, snippet string) []byte {
+	loc := headEndRegex.FindIndex(html)
+	if loc == nil {
+		return append([]byte(snippet), html...)
+	}
+	out := make([]byte, 0, len(html)+len(snippet))
+	out = append(out, html[:loc[0]]...)
+	out = append(out, snippet...)
+	return append(out, html[loc[0]:]...)
+}
+
+// createTempFile writes data to a new temporary file, which is removed after the current run
+func (pdfg *PDFGenerator) createTempFile(pattern string, data []byte) (string, error) {
+	f, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return "", fmt.Errorf("error creating temporary file: %w", err)
+	}
+	pdfg.tempFiles = append(pdfg.tempFiles, f.Name())
+	if pdfg.tempFileHook != nil {
+		pdfg.tempFileHook(f.Name(), true)
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", fmt.Errorf("error writing temporary file: %w", err)
+	}
+	return f.Name(), nil
+}
+
+// removeTempFiles removes all temporary files created by createTempFile
+func (pdfg *PDFGenerator) removeTempFiles() {
+	for _, path := range pdfg.tempFiles {
+		err := os.Remove(path)
+		if pdfg.tempFileHook != nil && (err == nil || errors.Is(err, fs.ErrNotExist)) {
+			pdfg.tempFileHook(path, false)
+		}
+	}
+	pdfg.tempFiles = nil
+}
@@ -0,0 +1,218 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjectIntoHead(t *testing.T) {
+	html := []byte("<html><head><title>T</title></HEAD><body></body></html>")
+	want := "<html><head><title>T</title><style>p{}</style></HEAD><body></body></html>"
+	assert.Equal(t, want, string(injectIntoHead(html, "<style>p{}</style>")))
+
+	// without a head the snippet is put in front
+	assert.Equal(t, "<style>p{}</style><p>x</p>", string(injectIntoHead([]byte("<p>x</p>"), "<style>p{}</style>")))
+}
+
+func TestInlineCSSReader(t *testing.T) {
+	page := NewPageReader(strings.NewReader("<html><head></head><body>Hello</body></html>"))
+	page.SetInlineCSS("body { color: red; }")
+
+	r, err := stdinReader(page)
+	require.NoError(t, err)
+	html, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "<html><head><style>body { color: red; }</style></head><body>Hello</body></html>", string(html))
+}
+
+func TestInlineCSSPage(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	page := NewPage("testdata/htmlsimple.html")
+	page.UserStyleSheet.Set("testdata/theme.css")
+	page.SetInlineCSS("body { color: red; }")
+	pdfg.AddPage(page)
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+
+	// the page now uses a temporary stylesheet with the theme followed by the inline CSS
+	tmpPath := page.UserStyleSheet.value
+	assert.NotEqual(t, "testdata/theme.css", tmpPath)
+	assert.Contains(t, pdfg.ArgString(), "--user-style-sheet "+tmpPath)
+	css, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	theme, err := os.ReadFile("testdata/theme.css")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(css), string(theme)))
+	assert.True(t, strings.HasSuffix(string(css), "body { color: red; }"))
+
+	// cleanup restores the page and removes the temporary file
+	cleanup()
+	assert.Equal(t, "testdata/theme.css", page.UserStyleSheet.value)
+	_, err = os.Stat(tmpPath)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestSetUserStyleSheets(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.css")
+	override := filepath.Join(dir, "override.css")
+	require.NoError(t, os.WriteFile(base, []byte("h1 { color: black; }"), 0644))
+	require.NoError(t, os.WriteFile(override, []byte("h1 { color: red; }"), 0644))
+
+	pdfg := NewPDFPreparer()
+	pdfg.SetUserStyleSheets(base, override)
+	page1 := NewPage("testdata/htmlsimple.html")
+	page2 := NewPage("testdata/htmlsimple.html")
+	page2.UserStyleSheet.Set("testdata/theme.css")
+	pdfg.AddPage(page1)
+	pdfg.AddPage(page2)
+	require.NoError(t, pdfg.Validate())
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+
+	// the style sheets are concatenated in order
+	tmpPath := page1.UserStyleSheet.value
+	css, err := os.ReadFile(tmpPath)
+	require.NoError(t, err)
+	assert.Equal(t, "h1 { color: black; }\nh1 { color: red; }\n", string(css))
+
+	// a page with its own style sheet keeps it
+	assert.Equal(t, "testdata/theme.css", page2.UserStyleSheet.value)
+
+	cleanup()
+	assert.Equal(t, "", page1.UserStyleSheet.value)
+	_, err = os.Stat(tmpPath)
+	assert.True(t, os.IsNotExist(err))
+
+	// missing style sheets are reported by Validate
+	pdfg.SetUserStyleSheets(base, filepath.Join(dir, "missing.css"))
+	assert.Error(t, pdfg.Validate())
+}
+
+func TestSpillStdinPages(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>first</p>")))
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	second := NewPageReader(strings.NewReader("<html><head></head><body>second</body></html>"))
+	second.SetInlineCSS("p{}")
+	pdfg.AddPage(second)
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+
+	// the first page is still read from stdin, the second one from a temporary .html file
+	path := pdfg.spilled[2]
+	assert.Equal(t, ".html", filepath.Ext(path))
+	assert.Equal(t, "page - page testdata/htmlsimple.html page "+path+" -", pdfg.ArgString())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "<html><head><style>p{}</style></head><body>second</body></html>", string(content))
+
+	cleanup()
+	assert.NoFileExists(t, path)
+	assert.Equal(t, "page - page testdata/htmlsimple.html page - -", pdfg.ArgString())
+
+	// the extension can be changed
+	pdfg.SetSpillFileExtension("xhtml")
+	cleanup, err = pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, ".xhtml", filepath.Ext(pdfg.spilled[2]))
+}
+
+func TestSetTempFileHook(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "out.pdf"), newTestPDF(2), 0644))
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\ncat > /dev/null\ncat "+filepath.Join(dir, "out.pdf")+"\n"), 0755))
+
+	type event struct {
+		path    string
+		created bool
+		exists  bool
+	}
+	var events []event
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.SetTempFileHook(func(path string, created bool) {
+		_, err := os.Stat(path)
+		events = append(events, event{path, created, err == nil})
+	})
+	// the second Markdown page is written to a temporary file, the first one is read from stdin
+	pdfg.AddPage(NewMarkdownPage("testdata/testmd.md"))
+	pdfg.AddPage(NewMarkdownPage("testdata/titled.md"))
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+
+	require.Len(t, events, 2)
+	assert.Equal(t, ".html", filepath.Ext(events[0].path))
+	assert.Equal(t, event{events[0].path, true, true}, events[0])
+	assert.Equal(t, event{events[0].path, false, false}, events[1])
+
+	// no temporary files are needed for a single page
+	events = nil
+	pdfg = NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.SetTempFileHook(func(path string, created bool) { events = append(events, event{path, created, true}) })
+	pdfg.AddPage(NewMarkdownPage("testdata/testmd.md"))
+	require.NoError(t, pdfg.CreateContext(context.Background()))
+	assert.Empty(t, events)
+}
+
+func TestStdinSpillThreshold(t *testing.T) {
+	// the HTML of testdata/testmd.md is larger than the threshold, so it is not passed via stdin
+	pdfg := NewPDFPreparer()
+	pdfg.SetStdinSpillThreshold(4 << 10)
+	mp := NewMarkdownPage("testdata/testmd.md")
+	pdfg.AddPage(mp)
+	html := readMarkdownHTML(t, mp)
+	require.Greater(t, len(html), 4<<10)
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	path := pdfg.spilled[0]
+	assert.Equal(t, "page "+path+" -", pdfg.ArgString())
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, html, string(content))
+	cleanup()
+	assert.NoFileExists(t, path)
+
+	// smaller content is still read from stdin, a PageReader can be read again
+	pdfg = NewPDFPreparer()
+	pdfg.SetStdinSpillThreshold(4 << 10)
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>small</p>")))
+	cleanup, err = pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, pdfg.spilled)
+	assert.Equal(t, "page - -", pdfg.ArgString())
+	r, err := stdinReader(pdfg.pages[0])
+	require.NoError(t, err)
+	content, err = io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "<p>small</p>", string(content))
+	cleanup()
+
+	// a negative threshold always uses stdin, by default the limit is DefaultStdinSpillThreshold
+	for _, threshold := range []int{-1, 0} {
+		pdfg = NewPDFPreparer()
+		pdfg.SetStdinSpillThreshold(threshold)
+		pdfg.AddPage(NewMarkdownPage("testdata/testmd.md"))
+		cleanup, err = pdfg.prepare(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, pdfg.spilled, threshold)
+		cleanup()
+	}
+}
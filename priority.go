@@ -0,0 +1,48 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// ProcessPriority is the scheduling priority of the wkhtmltopdf processes, see SetProcessPriority
+type ProcessPriority int
+
+const (
+	PriorityNormal      ProcessPriority = iota // The priority of the calling program (default)
+	PriorityBelowNormal                        // Nice value raised by 10 on Unix, below normal priority class on Windows
+	PriorityIdle                               // Nice 19 on Unix, idle priority class on Windows
+)
+
+func (p ProcessPriority) String() string {
+	switch p {
+	case PriorityNormal:
+		return "normal"
+	case PriorityBelowNormal:
+		return "below normal"
+	case PriorityIdle:
+		return "idle"
+	}
+	return fmt.Sprintf("ProcessPriority(%d)", int(p))
+}
+
+// SetProcessPriority lowers the scheduling priority of the wkhtmltopdf processes, so bulk rendering on a shared
+// machine doesn't slow down other work. On Windows the process is created with the priority class, on Unix it is
+// started with the nice command; on other systems only PriorityNormal is supported and Create returns an error
+// otherwise. The priority applies to all runs of wkhtmltopdf, also those for counting pages. An error is returned
+// for an unknown level.
+func (pdfg *PDFGenerator) SetProcessPriority(level ProcessPriority) error {
+	if level < PriorityNormal || level > PriorityIdle {
+		return fmt.Errorf("invalid process priority %d", int(level))
+	}
+	pdfg.processPriority = level
+	return nil
+}
+
+// runCmd runs cmd with the priority and waits for it to finish, like cmd.Run
+func runCmd(cmd *exec.Cmd, priority ProcessPriority) error {
+	if err := cmdPriority(cmd, priority); err != nil {
+		return fmt.Errorf("error setting the process priority: %w", err)
+	}
+	return cmd.Run()
+}
@@ -0,0 +1,21 @@
+package wkhtmltopdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetProcessPriority(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.Equal(t, PriorityNormal, pdfg.processPriority)
+	require.NoError(t, pdfg.SetProcessPriority(PriorityIdle))
+	assert.Equal(t, PriorityIdle, pdfg.processPriority)
+	assert.EqualError(t, pdfg.SetProcessPriority(ProcessPriority(7)), "invalid process priority 7")
+	assert.EqualError(t, pdfg.SetProcessPriority(-1), "invalid process priority -1")
+	assert.Equal(t, PriorityIdle, pdfg.processPriority)
+
+	assert.Equal(t, "below normal", PriorityBelowNormal.String())
+	assert.Equal(t, "ProcessPriority(7)", ProcessPriority(7).String())
+}
@@ -0,0 +1,54 @@
+//go:build unix
+// +build unix
+
+package wkhtmltopdf
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newNiceCommand returns the path of a fake wkhtmltopdf which logs its nice value, and the path of the log
+func newNiceCommand(t *testing.T) (string, string) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "log")
+	script := "#!/bin/sh\nnice > " + log + "\nprintf '%%PDF-1.4\\n'\n"
+	path := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0755))
+	return path, log
+}
+
+// readNice returns the nice value logged by the command from newNiceCommand
+func readNice(t *testing.T, log string) int {
+	b, err := os.ReadFile(log)
+	require.NoError(t, err)
+	nice, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	require.NoError(t, err)
+	return nice
+}
+
+func TestSetProcessPriorityUnix(t *testing.T) {
+	bin, log := newNiceCommand(t)
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.Create())
+	base := readNice(t, log)
+	if base >= 19 {
+		t.Skip("the test runs with the lowest priority already")
+	}
+
+	require.NoError(t, pdfg.SetProcessPriority(PriorityIdle))
+	require.NoError(t, pdfg.Create())
+	assert.Equal(t, 19, readNice(t, log))
+
+	require.NoError(t, pdfg.SetProcessPriority(PriorityBelowNormal))
+	require.NoError(t, pdfg.Create())
+	assert.Equal(t, min(base+10, 19), readNice(t, log))
+}
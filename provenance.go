@@ -0,0 +1,61 @@
+package wkhtmltopdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+)
+
+// Info dictionary keys written by SetProvenance
+const (
+	ProvenanceHashKey        = "GopdfSourceHash"
+	ProvenanceGeneratedAtKey = "GopdfGeneratedAt"
+)
+
+// SetProvenance enables writing the provenance of the PDF in its Info dictionary, so downstream systems can verify
+// that a PDF matches its source:
+//   - GopdfSourceHash is the hex encoded SHA-256 of the inputs of all pages in order: the contents of local
+//     files, the URL for other inputs, and the HTML sent to wkhtmltopdf for a page read from stdin
+//     (including Markdown pages). The cover and TOC are not included.
+//   - GopdfGeneratedAt is the time the PDF was created, in UTC and RFC 3339 format.
+//
+// It is disabled by default.
+func (pdfg *PDFGenerator) SetProvenance(enabled bool) {
+	pdfg.provenance = enabled
+}
+
+// sourceHash returns the SHA-256 of the inputs of all pages, stdin is the content of the page read from stdin
+func (pdfg *PDFGenerator) sourceHash(stdin []byte) (string, error) {
+	h := sha256.New()
+	for i := range pdfg.pages {
+		input := pdfg.pageInput(i)
+		if input == "-" {
+			h.Write(stdin)
+			continue
+		}
+		if path, ok := localPath(input); ok {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("error reading page input for source hash: %w", err)
+			}
+			h.Write(b)
+			continue
+		}
+		h.Write([]byte(input))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// setProvenance writes the source hash and the generation time in the Info dictionary
+func setProvenance(hash string, generatedAt time.Time) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			info := doc.info()
+			info.Set(ProvenanceHashKey, pdfString(hash))
+			info.Set(ProvenanceGeneratedAtKey, pdfString(generatedAt.UTC().Format(time.RFC3339)))
+			return nil
+		})
+	}
+}
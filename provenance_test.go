@@ -0,0 +1,56 @@
+package wkhtmltopdf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceHash(t *testing.T) {
+	html, err := os.ReadFile("testdata/htmlsimple.html")
+	require.NoError(t, err)
+
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	pdfg.AddPage(NewPage("https://example.com"))
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>stdin</p>")))
+
+	hash, err := pdfg.sourceHash([]byte("<p>stdin</p>"))
+	require.NoError(t, err)
+	sum := sha256.Sum256([]byte(string(html) + "https://example.com" + "<p>stdin</p>"))
+	assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+
+	pdfg.AddPage(NewPage("testdata/missing.html"))
+	_, err = pdfg.sourceHash(nil)
+	assert.Error(t, err)
+}
+
+func TestSetProvenance(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>provenance</p>")))
+	pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) {
+		// user post-processors run after the built-in ones
+		doc, err := parsePDF(pdf)
+		require.NoError(t, err)
+		assert.NotNil(t, doc.info().Get(ProvenanceHashKey))
+		return pdf, nil
+	})
+	pdfg.SetProvenance(true)
+	require.NoError(t, pdfg.Create())
+
+	doc, err := parsePDF(pdfg.Bytes())
+	require.NoError(t, err)
+	info := doc.info()
+	sum := sha256.Sum256([]byte("<p>provenance</p>"))
+	assert.Equal(t, pdfString(hex.EncodeToString(sum[:])), info.Get(ProvenanceHashKey))
+	generatedAt, err := time.Parse(time.RFC3339, string(info.Get(ProvenanceGeneratedAtKey).(pdfString)))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), generatedAt, time.Minute)
+}
@@ -0,0 +1,112 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/html"
+)
+
+// HTMLRenderer produces the HTML of a RenderedPage
+type HTMLRenderer interface {
+	RenderHTML() ([]byte, error)
+}
+
+// HTMLRendererFunc is a function which implements HTMLRenderer
+type HTMLRendererFunc func() ([]byte, error)
+
+// RenderHTML calls f
+func (f HTMLRendererFunc) RenderHTML() ([]byte, error) {
+	return f()
+}
+
+// ASTRenderer returns an HTMLRenderer which renders a parsed gomarkdown document (like the result of
+// parser.Parse of github.com/gomarkdown/markdown/parser) with renderer. If renderer is nil, the HTML renderer of
+// gomarkdown with html.CommonFlags is used.
+// For other Markdown libraries, like goldmark, use an HTMLRendererFunc which renders their AST to a buffer.
+func ASTRenderer(doc ast.Node, renderer markdown.Renderer) HTMLRenderer {
+	return HTMLRendererFunc(func() ([]byte, error) {
+		r := renderer
+		if r == nil {
+			r = html.NewRenderer(html.RendererOptions{Flags: html.CommonFlags})
+		}
+		return markdown.Render(doc, r), nil
+	})
+}
+
+// RenderedPage is a page whose HTML is produced by an HTMLRenderer, for programs which already have their own
+// Markdown processing (like a parsed AST) and want to skip writing the Markdown or HTML to a file or string first.
+// The HTML is wrapped in the same HTML document as the HTML converted from a MarkdownPage and passed to
+// wkhtmltopdf via stdin.
+// It implements the PageProvider interface.
+type RenderedPage struct {
+	// Renderer produces the HTML of the page, the content of the <body>.
+	Renderer HTMLRenderer
+	// Lang, if set, is the language of the content (like "en-US"), set as lang attribute on the <html> element.
+	Lang string
+	// Title is the <title> of the generated document, which wkhtmltopdf uses for [doctitle] in headers and footers.
+	Title string
+	// HeadExtras is raw HTML inserted at the end of the <head> of the generated document, like meta tags or a
+	// <link> to a style sheet. It is not escaped or checked, so it must be trusted, valid HTML for the <head>.
+	HeadExtras string
+	// NoWrap, if true, passes the HTML of Renderer unchanged, for renderers which produce a complete HTML
+	// document. Lang, Title and HeadExtras have no effect then.
+	NoWrap bool
+	PageOptions
+	htmlCache []byte // Cache for the rendered HTML
+	readErr   error  // Store error during rendering
+}
+
+// NewRenderedPage creates a new RenderedPage provider for the HTML produced by renderer.
+func NewRenderedPage(renderer HTMLRenderer) *RenderedPage {
+	return &RenderedPage{
+		Renderer:    renderer,
+		PageOptions: NewPageOptions(),
+	}
+}
+
+// Options returns the PageOptions associated with this RenderedPage.
+func (rp *RenderedPage) Options() *PageOptions {
+	return &rp.PageOptions
+}
+
+// Args returns the argument slice and is part of the page interface
+func (rp *RenderedPage) Args() []string {
+	return rp.PageOptions.Args()
+}
+
+// InputFile returns "-" as the HTML is piped via stdin.
+func (rp *RenderedPage) InputFile() string {
+	return "-"
+}
+
+// Reader calls Renderer and returns the HTML document as an io.Reader.
+// It caches the result, so Renderer is called only once.
+func (rp *RenderedPage) Reader() io.Reader {
+	if rp.readErr != nil {
+		return &errorReader{err: rp.readErr}
+	}
+	if rp.htmlCache != nil {
+		return bytes.NewReader(rp.htmlCache)
+	}
+
+	if rp.Renderer == nil {
+		rp.readErr = errors.New("RenderedPage has no Renderer")
+		return &errorReader{err: rp.readErr}
+	}
+	body, err := rp.Renderer.RenderHTML()
+	if err != nil {
+		rp.readErr = fmt.Errorf("failed to render HTML: %w", err)
+		return &errorReader{err: rp.readErr}
+	}
+	if rp.NoWrap {
+		rp.htmlCache = append([]byte{}, body...) // not nil, also for empty HTML
+	} else {
+		rp.htmlCache = wrapHTMLDocument(body, rp.Lang, rp.Title, rp.HeadExtras)
+	}
+	return bytes.NewReader(rp.htmlCache)
+}
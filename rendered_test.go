@@ -0,0 +1,115 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gomarkdown/markdown/ast"
+	"github.com/gomarkdown/markdown/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testNode is a node of a trivial document tree for TestRenderedPage
+type testNode struct {
+	tag      string
+	text     string
+	children []testNode
+}
+
+// renderTestNode renders a testNode as HTML, like a pipeline with its own document tree would
+func renderTestNode(w io.Writer, n testNode) {
+	fmt.Fprintf(w, "<%s>%s", n.tag, n.text)
+	for _, child := range n.children {
+		renderTestNode(w, child)
+	}
+	fmt.Fprintf(w, "</%s>", n.tag)
+}
+
+func readRenderedHTML(t *testing.T, rp *RenderedPage) string {
+	b, err := io.ReadAll(rp.Reader())
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestRenderedPage(t *testing.T) {
+	tree := testNode{tag: "article", children: []testNode{{tag: "h1", text: "Report"}, {tag: "p", text: "Body"}}}
+	calls := 0
+	rp := NewRenderedPage(HTMLRendererFunc(func() ([]byte, error) {
+		calls++
+		var buf bytes.Buffer
+		renderTestNode(&buf, tree)
+		return buf.Bytes(), nil
+	}))
+	rp.Lang = "en"
+	rp.Title = "Q1 <Report>"
+	rp.HeadExtras = `<meta name="author" content="Finance">`
+
+	want := `<!DOCTYPE html><html lang="en"><head><meta charset="utf-8"><title>Q1 &lt;Report&gt;</title>` +
+		`<meta name="author" content="Finance"></head><body><article><h1>Report</h1><p>Body</p></article></body></html>`
+	assert.Equal(t, want, readRenderedHTML(t, rp))
+	assert.Equal(t, want, readRenderedHTML(t, rp))
+	assert.Equal(t, 1, calls, "the HTML is cached")
+	assert.Equal(t, "-", rp.InputFile())
+
+	// the page is passed via stdin like other pages from memory
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(rp)
+	assert.Equal(t, "page - -", pdfg.ArgString())
+	pages, err := pdfg.RenderHTMLOnly()
+	require.NoError(t, err)
+	assert.Equal(t, want, string(pages[0]))
+
+	// without wrapping the HTML is passed unchanged
+	rp = NewRenderedPage(HTMLRendererFunc(func() ([]byte, error) { return []byte("<html><body>x</body></html>"), nil }))
+	rp.NoWrap = true
+	assert.Equal(t, "<html><body>x</body></html>", readRenderedHTML(t, rp))
+}
+
+func TestRenderedPageAST(t *testing.T) {
+	doc := parser.New().Parse([]byte("# Title\n\nSome *text*."))
+	ast.WalkFunc(doc, func(node ast.Node, entering bool) ast.WalkStatus {
+		if text, ok := node.(*ast.Text); ok && string(text.Literal) == "text" {
+			text.Literal = []byte("changed text")
+		}
+		return ast.GoToNext
+	})
+
+	rp := NewRenderedPage(ASTRenderer(doc, nil))
+	html := readRenderedHTML(t, rp)
+	assert.Contains(t, html, "<h1>Title</h1>")
+	assert.Contains(t, html, "<p>Some <em>changed text</em>.</p>")
+	assert.Contains(t, html, "<title></title>")
+}
+
+func TestRenderedPageError(t *testing.T) {
+	rp := NewRenderedPage(HTMLRendererFunc(func() ([]byte, error) { return nil, errors.New("broken tree") }))
+	_, err := io.ReadAll(rp.Reader())
+	assert.EqualError(t, err, "failed to render HTML: broken tree")
+
+	_, err = io.ReadAll((&RenderedPage{}).Reader())
+	assert.EqualError(t, err, "RenderedPage has no Renderer")
+}
+
+func TestRenderedPageJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	rp := NewRenderedPage(HTMLRendererFunc(func() ([]byte, error) { return []byte("<p>rendered</p>"), nil }))
+	rp.Zoom.Set(1.5)
+	pdfg.AddPage(rp)
+	b, err := pdfg.ToJSON()
+	require.NoError(t, err)
+
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Len(t, restored.pages, 1)
+	pr, ok := restored.pages[0].(*PageReader)
+	require.True(t, ok)
+	assert.Equal(t, 1.5, pr.Zoom.value)
+	content, err := io.ReadAll(pr.Reader())
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(string(content), "<body><p>rendered</p></body></html>"))
+}
@@ -0,0 +1,98 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// replaceEnvSettings controls the expansion of variables in replacement values, see ExpandReplaceEnv
+type replaceEnvSettings struct {
+	enabled bool
+	strict  bool
+	vars    map[string]string
+}
+
+// ExpandReplaceEnv enables or disables the expansion of ${VAR} in the values of replacements (set by SetReplace
+// or on the Replace option of pages and the TOC) when the PDF is created.
+// Variables are taken from the process environment, or from the map set by SetReplaceVars.
+// Only the ${VAR} form is expanded, a $ without braces is kept as is. Unknown variables expand to an empty string,
+// unless strict mode is enabled with SetReplaceEnvStrict. The expansion is disabled by default, so literal
+// ${...} values are not changed unexpectedly. The options themselves are not changed, so ToJSON keeps the variables.
+func (pdfg *PDFGenerator) ExpandReplaceEnv(enabled bool) {
+	pdfg.replaceEnv.enabled = enabled
+}
+
+// SetReplaceVars sets the variables used by ExpandReplaceEnv instead of the process environment.
+// Setting nil uses the process environment again.
+func (pdfg *PDFGenerator) SetReplaceVars(vars map[string]string) {
+	pdfg.replaceEnv.vars = vars
+}
+
+// SetReplaceEnvStrict makes Create return an error when a replacement value contains an unknown variable
+// and ExpandReplaceEnv is enabled.
+func (pdfg *PDFGenerator) SetReplaceEnvStrict(strict bool) {
+	pdfg.replaceEnv.strict = strict
+}
+
+var replaceVarRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// lookup returns the value of a variable
+func (rs replaceEnvSettings) lookup(name string) (string, bool) {
+	if rs.vars != nil {
+		v, ok := rs.vars[name]
+		return v, ok
+	}
+	return os.LookupEnv(name)
+}
+
+// expand returns value with all variables expanded
+func (rs replaceEnvSettings) expand(value string) (string, error) {
+	var err error
+	expanded := replaceVarRegex.ReplaceAllStringFunc(value, func(match string) string {
+		name := replaceVarRegex.FindStringSubmatch(match)[1]
+		v, ok := rs.lookup(name)
+		if !ok && rs.strict && err == nil {
+			err = fmt.Errorf("unknown variable %q in replacement value %q", name, value)
+		}
+		return v
+	})
+	return expanded, err
+}
+
+// expandReplaceEnv expands the variables in the replacement values of all pages and the TOC.
+// It returns a function which restores the original values.
+func (pdfg *PDFGenerator) expandReplaceEnv() (func(), error) {
+	var restore []func()
+	restoreAll := func() {
+		for _, r := range restore {
+			r()
+		}
+	}
+	if !pdfg.replaceEnv.enabled {
+		return restoreAll, nil
+	}
+
+	options := []*mapOption{&pdfg.TOC.Replace}
+	for _, page := range pdfg.pages {
+		options = append(options, &page.Options().Replace)
+	}
+	for _, mo := range options {
+		if len(mo.value) == 0 {
+			continue
+		}
+		original := mo.value
+		expanded := make(map[string]string, len(original))
+		for k, v := range original {
+			ev, err := pdfg.replaceEnv.expand(v)
+			if err != nil {
+				restoreAll()
+				return nil, err
+			}
+			expanded[k] = ev
+		}
+		mo.value = expanded
+		restore = append(restore, func() { mo.value = original })
+	}
+	return restoreAll, nil
+}
@@ -0,0 +1,60 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandReplaceEnv(t *testing.T) {
+	t.Setenv("GOPDF_TEST_VERSION", "1.2.3")
+
+	pdfg := NewPDFPreparer()
+	pdfg.SetReplace("version", "v${GOPDF_TEST_VERSION}")
+	pdfg.SetReplace("unset", "[${GOPDF_TEST_UNSET}]")
+	pdfg.SetReplace("price", "$5 ${ GOPDF_TEST_VERSION}")
+	page := NewPage("https://www.google.com")
+	pdfg.AddPage(page)
+
+	// disabled by default
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v${GOPDF_TEST_VERSION}", page.Replace.value["version"])
+	cleanup()
+
+	pdfg.ExpandReplaceEnv(true)
+	cleanup, err = pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2.3", page.Replace.value["version"])
+	assert.Equal(t, "[]", page.Replace.value["unset"])
+	assert.Equal(t, "$5 ${ GOPDF_TEST_VERSION}", page.Replace.value["price"])
+	assert.Contains(t, pdfg.ArgString(), "--replace version v1.2.3")
+
+	// the original values are restored after the run
+	cleanup()
+	assert.Equal(t, "v${GOPDF_TEST_VERSION}", page.Replace.value["version"])
+	assert.Equal(t, "[${GOPDF_TEST_UNSET}]", page.Replace.value["unset"])
+}
+
+func TestExpandReplaceEnvStrictAndVars(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.ExpandReplaceEnv(true)
+	pdfg.SetReplaceEnvStrict(true)
+	pdfg.SetReplaceVars(map[string]string{"AUTHOR": "Jane"})
+	pdfg.TOC.Replace.Set("author", "${AUTHOR}")
+	pdfg.SetReplace("build", "${BUILD}")
+	pdfg.AddPage(NewPage("https://www.google.com"))
+
+	_, err := pdfg.prepare(context.Background())
+	assert.EqualError(t, err, `unknown variable "BUILD" in replacement value "${BUILD}"`)
+	assert.Equal(t, "${AUTHOR}", pdfg.TOC.Replace.value["author"])
+
+	pdfg.SetReplaceVars(map[string]string{"AUTHOR": "Jane", "BUILD": "42"})
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	defer cleanup()
+	assert.Equal(t, "Jane", pdfg.TOC.Replace.value["author"])
+	assert.Equal(t, "42", pdfg.pages[0].Options().Replace.value["build"])
+}
@@ -0,0 +1,121 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var (
+	resourceRefRegex = regexp.MustCompile(`(?is)\s(?:src|href|poster|background|data|action)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	srcsetRegex      = regexp.MustCompile(`(?is)\ssrcset\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	cssURLRegex      = regexp.MustCompile(`(?i)url\(\s*(?:"([^"]*)"|'([^']*)'|([^)\s]*))\s*\)`)
+	cssImportRegex   = regexp.MustCompile(`(?i)@import\s+(?:"([^"]*)"|'([^']*)')`)
+	baseHrefRegex    = regexp.MustCompile(`(?is)<base\b[^>]*\shref\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+)
+
+// ListExternalResources returns the http(s) URLs the document would load, without loading them and without
+// running wkhtmltopdf, so they can be reviewed before rendering untrusted content. These are the pages, the cover,
+// header and footer HTML and style sheets given as URL, and the src, href, srcset and CSS url() and @import
+// references in the HTML of the pages (after Markdown and AsciiDoc conversion, see RenderHTMLOnly) and in local
+// header, footer, cover and style sheet files. Relative references are resolved against a http(s) <base> element.
+// Each URL is listed once, in the order it was found. Protocol-relative URLs (//example.com/a.js) are listed as
+// they are. Resources removed by SetResourceAllowlist are not listed. URLs built by scripts, references inside
+// pages loaded from URLs and redirects can't be found this way.
+func (pdfg *PDFGenerator) ListExternalResources() ([]string, error) {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		ref = strings.TrimSpace(ref)
+		if !seen[ref] && (isHTTPURL(ref) || strings.HasPrefix(ref, "//")) {
+			seen[ref] = true
+			urls = append(urls, ref)
+		}
+	}
+
+	pageInputs := make(map[string]bool)
+	for _, page := range pdfg.pages {
+		pageInputs[page.InputFile()] = true
+	}
+	for _, ref := range pdfg.fileRefs() {
+		if isHTTPURL(ref.path) {
+			add(ref.path)
+			continue
+		}
+		path, ok := localPath(ref.path)
+		if !ok || pageInputs[ref.path] {
+			continue // pages are read below
+		}
+		if pdfg.headerLogo != nil && ref.path == pdfg.headerLogo.path {
+			continue // embedded as data URL
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", ref.location, err)
+		}
+		for _, u := range extractResourceURLs(content) {
+			add(u)
+		}
+	}
+
+	for i, page := range pdfg.pages {
+		content, err := pdfg.pageHTML(i)
+		if err != nil {
+			return nil, err
+		}
+		if allowlist := page.Options().allowlist; allowlist != nil && page.Reader() == nil {
+			content = filterResources(content, allowlist)
+		}
+		for _, u := range extractResourceURLs(content) {
+			add(u)
+		}
+	}
+	return urls, nil
+}
+
+// extractResourceURLs returns the references to resources in HTML or CSS content, resolved against the <base>
+func extractResourceURLs(content []byte) []string {
+	var refs []string
+	value := func(m [][]byte) string {
+		return html.UnescapeString(string(m[1]) + string(m[2]) + string(m[3]))
+	}
+	for _, m := range resourceRefRegex.FindAllSubmatch(content, -1) {
+		refs = append(refs, value(m))
+	}
+	for _, m := range srcsetRegex.FindAllSubmatch(content, -1) {
+		// candidates like "a.png 1x, b.png 2x"
+		for _, candidate := range strings.Split(html.UnescapeString(string(m[1])+string(m[2])), ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 {
+				refs = append(refs, fields[0])
+			}
+		}
+	}
+	for _, m := range cssURLRegex.FindAllSubmatch(content, -1) {
+		refs = append(refs, value(m))
+	}
+	for _, m := range cssImportRegex.FindAllSubmatch(content, -1) {
+		refs = append(refs, string(m[1])+string(m[2]))
+	}
+
+	m := baseHrefRegex.FindSubmatch(content)
+	if m == nil || !isHTTPURL(value(m)) {
+		return refs
+	}
+	base, err := url.Parse(value(m))
+	if err != nil {
+		return refs
+	}
+	for i, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || strings.HasPrefix(ref, "#") || strings.HasPrefix(ref, "//") {
+			continue
+		}
+		if u, err := url.Parse(ref); err == nil && !u.IsAbs() {
+			refs[i] = base.ResolveReference(u).String()
+		}
+	}
+	return refs
+}
@@ -0,0 +1,72 @@
+package wkhtmltopdf
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractResourceURLs(t *testing.T) {
+	refs := extractResourceURLs([]byte(`<html><head><base href="https://example.com/docs/"></head>` +
+		`<body><img src="a.png"><a href="#top">top</a><script src="//cdn.example.com/x.js"></script></body></html>`))
+	assert.Equal(t, []string{"https://example.com/docs/", "https://example.com/docs/a.png", "#top", "//cdn.example.com/x.js"}, refs)
+
+	refs = extractResourceURLs([]byte(`@import "https://cdn.example.com/a.css"; p { background: url( 'b.png' ) }`))
+	assert.Equal(t, []string{"b.png", "https://cdn.example.com/a.css"}, refs)
+}
+
+func TestListExternalResources(t *testing.T) {
+	pdfg := NewPDFPreparer() // wkhtmltopdf is not needed
+	page := NewPage("testdata/resources/page.html")
+	page.UserStyleSheet.Set("testdata/resources/local.css")
+	page.FooterHTML.Set("https://example.com/footer.html")
+	pdfg.AddPage(page)
+	pdfg.AddPage(NewMarkdownPage("testdata/resources/page.md"))
+	pdfg.AddPage(NewPageReader(strings.NewReader(`<img src="https://images.example.com/logo.png"><img src="https://tracker.example.net/p.gif">`)))
+	pdfg.AddPage(NewPage("https://example.com/terms.html"))
+
+	urls, err := pdfg.ListExternalResources()
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"https://fonts.example.com/brand.woff",
+		"https://example.com/footer.html",
+		"https://example.com/terms.html",
+		"https://fonts.example.com/css?family=Inter&display=swap",
+		"https://cdn.example.com/chart.js",
+		"https://images.example.com/logo.png",
+		"https://example.com/about",
+		"//media.example.com/poster.jpg",
+		"https://images.example.com/logo@2x.png",
+		"https://images.example.com/paper.png",
+		"https://cdn.example.com/print.css",
+		"https://images.example.com/diagram.svg",
+		"https://docs.example.com/guide",
+		"https://tracker.example.net/p.gif",
+	}, urls)
+
+	// the reader page can still be rendered
+	b, err := io.ReadAll(pdfg.pages[2].Reader())
+	require.NoError(t, err)
+	assert.Contains(t, string(b), "tracker.example.net")
+}
+
+func TestListExternalResourcesAllowlist(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	page := NewPage("testdata/resources/page.html")
+	page.SetResourceAllowlist([]string{"https://cdn.example.com"})
+	pdfg.AddPage(page)
+
+	urls, err := pdfg.ListExternalResources()
+	require.NoError(t, err)
+	assert.Contains(t, urls, "https://cdn.example.com/chart.js")
+	assert.NotContains(t, urls, "https://fonts.example.com/css?family=Inter&display=swap")
+
+	// missing files are reported
+	pdfg.AddPage(NewPage("testdata/resources/missing.html"))
+	_, err = pdfg.ListExternalResources()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
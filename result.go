@@ -0,0 +1,146 @@
+package wkhtmltopdf
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RenderResult contains the output and diagnostics of a single wkhtmltopdf run, as returned by CreateWithResult.
+type RenderResult struct {
+	Bytes    []byte        // The generated PDF, empty when the output was sent to OutputFile or a writer set with SetOutput
+	Warnings []string      // Warnings printed by wkhtmltopdf on stderr, without the "Warning:" prefix
+	ExitCode int           // Exit code of the wkhtmltopdf process, -1 if it did not exit normally
+	Duration time.Duration // Time it took to run wkhtmltopdf
+
+	// StderrEvents are the lines wkhtmltopdf printed on stderr, classified by severity, see SetStderrRules
+	StderrEvents []StderrEvent
+
+	// Phases are the phases wkhtmltopdf reported on stderr (like "Loading pages (1/6)") with the time each took,
+	// measured from when the phase was printed until the next phase or "Done" was printed.
+	Phases []RenderPhase
+	// LoadingDuration is the time of the "Loading pages" phase, in which the pages and their resources are loaded
+	// and laid out, including waiting for JavascriptDelay and WindowStatus
+	LoadingDuration time.Duration
+	// RenderingDuration is the time of all phases between loading and printing, like counting pages, creating
+	// the TOC, resolving links and loading headers and footers
+	RenderingDuration time.Duration
+	// PrintingDuration is the time of the "Printing pages" phase, in which the PDF is written
+	PrintingDuration time.Duration
+
+	// PossiblyTruncated is true if wkhtmltopdf printed messages on stderr which it prints when it fails to paint
+	// (part of) a page, like for very tall elements, while still writing a PDF and exiting with 0. It is a
+	// heuristic: content can be missing without these messages, and they don't always mean content is missing,
+	// so it is a signal to check the PDF. See truncationPatterns for the messages.
+	PossiblyTruncated bool
+}
+
+// RenderPhase is a phase of a wkhtmltopdf run and its duration, see RenderResult
+type RenderPhase struct {
+	Name     string // Name of the phase without the counter, like "Loading pages"
+	Duration time.Duration
+}
+
+// setPhases sets Phases and the durations derived from it
+func (r *RenderResult) setPhases(phases []RenderPhase) {
+	r.Phases = phases
+	for _, phase := range phases {
+		switch phase.Name {
+		case "Loading pages":
+			r.LoadingDuration += phase.Duration
+		case "Printing pages":
+			r.PrintingDuration += phase.Duration
+		default:
+			r.RenderingDuration += phase.Duration
+		}
+	}
+}
+
+var phaseRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z ]*) \(\d+/\d+\)$`)
+
+// phaseRecorder is a writer for the stderr output of wkhtmltopdf, which records when each phase starts
+type phaseRecorder struct {
+	now    func() time.Time
+	line   []byte
+	names  []string
+	starts []time.Time
+	done   time.Time
+}
+
+func newPhaseRecorder() *phaseRecorder {
+	return &phaseRecorder{now: time.Now}
+}
+
+func (pr *phaseRecorder) Write(p []byte) (int, error) {
+	for _, c := range p {
+		// progress bars are updated with \r, so lines also end there
+		if c != '\n' && c != '\r' {
+			pr.line = append(pr.line, c)
+			continue
+		}
+		pr.endLine()
+	}
+	return len(p), nil
+}
+
+func (pr *phaseRecorder) endLine() {
+	line := strings.TrimSpace(string(pr.line))
+	pr.line = pr.line[:0]
+	if m := phaseRegex.FindStringSubmatch(line); m != nil {
+		pr.names = append(pr.names, m[1])
+		pr.starts = append(pr.starts, pr.now())
+	} else if line == "Done" && pr.done.IsZero() {
+		pr.done = pr.now()
+	}
+}
+
+// phases returns the recorded phases, end is used as end of the last phase if "Done" was not printed
+func (pr *phaseRecorder) phases(end time.Time) []RenderPhase {
+	if len(pr.line) > 0 {
+		pr.endLine()
+	}
+	if !pr.done.IsZero() {
+		end = pr.done
+	}
+	var phases []RenderPhase
+	for i, name := range pr.names {
+		next := end
+		if i+1 < len(pr.starts) {
+			next = pr.starts[i+1]
+		}
+		phases = append(phases, RenderPhase{Name: name, Duration: next.Sub(pr.starts[i])})
+	}
+	return phases
+}
+
+// truncationPatterns match the messages of Qt on stderr which indicate that wkhtmltopdf could not paint a page or
+// an image, which leaves it blank or cut off, see RenderResult.PossiblyTruncated
+var truncationPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`QPainter::begin\(\): Returned false`),
+	regexp.MustCompile(`QPainter::\w+: Painter not active`),
+	regexp.MustCompile(`QImage: out of memory`),
+	regexp.MustCompile(`QPixmap: Invalid pixmap parameters`),
+	regexp.MustCompile(`QPixmap::scaled: Pixmap is a null pixmap`),
+}
+
+// possiblyTruncated returns true if the stderr output of wkhtmltopdf matches one of truncationPatterns
+func possiblyTruncated(stderr string) bool {
+	for _, pattern := range truncationPatterns {
+		if pattern.MatchString(stderr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseWarnings returns all warning lines from the stderr output of wkhtmltopdf
+func parseWarnings(stderr string) []string {
+	var warnings []string
+	for _, line := range strings.Split(stderr, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "Warning:") {
+			warnings = append(warnings, strings.TrimSpace(strings.TrimPrefix(line, "Warning:")))
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,127 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseWarnings(t *testing.T) {
+	stderr := "Loading pages (1/6)\n" +
+		"Warning: Failed to load file:///missing.png (ignore)\n" +
+		"Counting pages (2/6)\n" +
+		"  Warning: Received createRequest signal on a disposed ResourceObject's NetworkAccessManager.\n" +
+		"Done\n"
+
+	want := []string{
+		"Failed to load file:///missing.png (ignore)",
+		"Received createRequest signal on a disposed ResourceObject's NetworkAccessManager.",
+	}
+	assert.Equal(t, want, parseWarnings(stderr))
+	assert.Empty(t, parseWarnings("Loading pages (1/6)\nDone\n"))
+}
+
+func TestPhaseRecorder(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := start
+	pr := newPhaseRecorder()
+	pr.now = func() time.Time { return clock }
+
+	write := func(s string, elapsed time.Duration) {
+		pr.Write([]byte(s))
+		clock = clock.Add(elapsed)
+	}
+	write("Loading pages (1/6)\n", 3*time.Second)
+	write("[>                    ] 0%\r[==========>         ] 50%\r", 0)
+	write("[====================] 100%\rCounting pages (2/6)\n", 100*time.Millisecond)
+	write("Resolving links (4/6)\n", 200*time.Millisecond)
+	write("Loading headers and footers (5/6)\n", 300*time.Millisecond)
+	write("Printing pages (6/6)\n", time.Second)
+	write("Done\n", time.Minute)
+
+	var result RenderResult
+	result.setPhases(pr.phases(clock))
+	assert.Equal(t, []RenderPhase{
+		{"Loading pages", 3 * time.Second},
+		{"Counting pages", 100 * time.Millisecond},
+		{"Resolving links", 200 * time.Millisecond},
+		{"Loading headers and footers", 300 * time.Millisecond},
+		{"Printing pages", time.Second},
+	}, result.Phases)
+	assert.Equal(t, 3*time.Second, result.LoadingDuration)
+	assert.Equal(t, 600*time.Millisecond, result.RenderingDuration)
+	assert.Equal(t, time.Second, result.PrintingDuration)
+
+	// without Done the last phase ends at the given end time
+	pr = newPhaseRecorder()
+	pr.now = func() time.Time { return start }
+	pr.Write([]byte("Loading pages (1/6)"))
+	assert.Equal(t, []RenderPhase{{"Loading pages", 2 * time.Second}}, pr.phases(start.Add(2*time.Second)))
+}
+
+func TestCreateWithResult(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+
+	htmlfile, err := os.ReadFile("testdata/htmlsimple.html")
+	require.NoError(t, err)
+	pdfg.AddPage(NewPageReader(bytes.NewReader(htmlfile)))
+
+	result, err := pdfg.CreateWithResult(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, pdfg.Bytes(), result.Bytes)
+	assert.True(t, bytes.HasPrefix(result.Bytes, []byte("%PDF-")))
+	assert.Greater(t, result.Duration.Nanoseconds(), int64(0))
+	require.NotEmpty(t, result.Phases)
+	assert.Equal(t, "Loading pages", result.Phases[0].Name)
+	assert.False(t, result.PossiblyTruncated)
+}
+
+func TestCreateWithResultError(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+
+	// no input makes wkhtmltopdf exit with a non zero exit code
+	result, err := pdfg.CreateWithResult(context.Background())
+	assert.Error(t, err)
+	require.NotNil(t, result)
+	assert.NotEqual(t, 0, result.ExitCode)
+	assert.Empty(t, result.Bytes)
+}
+
+func TestPossiblyTruncated(t *testing.T) {
+	assert.False(t, possiblyTruncated("Loading pages (1/6)\nWarning: Failed to load file:///missing.png (ignore)\nDone\n"))
+	assert.True(t, possiblyTruncated("Printing pages (6/6)\nQPainter::begin(): Returned false\nDone\n"))
+	assert.True(t, possiblyTruncated("QPainter::setPen: Painter not active\n"))
+	assert.True(t, possiblyTruncated("QImage: out of memory, returning null image\n"))
+}
+
+func TestCreateWithResultPossiblyTruncated(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	// a fake wkhtmltopdf which fails to paint a page, but writes a PDF and exits with 0 like the real one
+	dir := t.TempDir()
+	script := "#!/bin/sh\ncat > /dev/null\necho 'Loading pages (1/6)' >&2\necho 'Printing pages (6/6)' >&2\n" +
+		"echo 'QPainter::begin(): Returned false' >&2\necho 'Done' >&2\nprintf '%%PDF-1.4\\n'\n"
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = bin
+	pdfg.AddPage(NewPageReader(bytes.NewReader([]byte("<p>tall</p>"))))
+	result, err := pdfg.CreateWithResult(context.Background())
+	require.NoError(t, err)
+	assert.True(t, result.PossiblyTruncated)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Empty(t, result.Warnings)
+}
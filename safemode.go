@@ -0,0 +1,118 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+)
+
+// safeModeProxy is an address nothing listens on, so all network requests of wkhtmltopdf fail in safe mode
+const safeModeProxy = "http://127.0.0.1:1"
+
+// SetSafeMode configures the generator for rendering untrusted content. When enabled, the cover, the TOC and all
+// pages (also pages added later) get these options:
+//
+//	--disable-javascript          no JavaScript is run
+//	--disable-external-links      no links to remote web pages are created
+//	--disable-local-file-access   the content can't load other local files
+//	--proxy http://127.0.0.1:1    all network requests go to a closed port and fail, so nothing is fetched
+//	--proxy-hostname-lookup       host names are resolved by the proxy, so no DNS queries are made either
+//
+// and the options which weaken these (--allow, --enable-local-file-access, --enable-plugins, --bypass-proxy-for
+// and --run-script) are removed. Create returns an error for a page or cover which is a URL, except for data: URLs,
+// and for style sheets (see SetUserStyleSheet) and header or footer HTML which are URLs, so nothing is fetched by
+// this process either; content from stdin (PageReader, MarkdownPage and AsciiDocPage) and local files given as
+// input are accepted. Markdown includes are not expanded in safe mode, see MarkdownPage.ExpandIncludes.
+// Disabling safe mode stops applying the options to new pages, it does not undo them on existing ones.
+func (pdfg *PDFGenerator) SetSafeMode(safe bool) {
+	pdfg.safeMode = safe
+	if !safe {
+		return
+	}
+	applySafeMode(&pdfg.Cover.pageOptions)
+	applySafeMode(&pdfg.TOC.pageOptions)
+	for _, page := range pdfg.pages {
+		applySafeMode(&page.Options().pageOptions)
+	}
+}
+
+// applySafeMode sets the page options of SetSafeMode
+func applySafeMode(po *pageOptions) {
+	po.DisableJavascript.Set(true)
+	po.DisableExternalLinks.Set(true)
+	po.DisableLocalFileAccess.Set(true)
+	po.Proxy.Set(safeModeProxy)
+	po.ProxyHostnameLookup.Set(true)
+	po.Allow.Unset()
+	po.EnableLocalFileAccess.Unset()
+	po.EnablePlugins.Unset()
+	po.BypassProxyFor.Unset()
+	po.RunScript.Unset()
+}
+
+// checkSafeMode returns an error if safe mode is enabled and the cover or a page is a URL other than a data: URL,
+// or a style sheet or header or footer HTML is a URL. Style sheets from http(s) URLs would be fetched by this
+// process itself, outside of the proxy of wkhtmltopdf.
+func (pdfg *PDFGenerator) checkSafeMode() error {
+	if !pdfg.safeMode {
+		return nil
+	}
+	check := func(location, input string) error {
+		if input == "" || input == "-" {
+			return nil
+		}
+		// a single letter scheme is a Windows drive letter, see localPath
+		if u, err := url.Parse(input); err != nil || len(u.Scheme) <= 1 || u.Scheme == "data" {
+			return nil
+		}
+		return fmt.Errorf("%s: URL %s is not allowed in safe mode", location, input)
+	}
+	checkOptions := func(location string, po *pageOptions, hf *headerAndFooterOptions) error {
+		if err := check(location+" user style sheet", po.UserStyleSheet.value); err != nil {
+			return err
+		}
+		if hf == nil {
+			return nil
+		}
+		if err := check(location+" header HTML", hf.HeaderHTML.value); err != nil {
+			return err
+		}
+		return check(location+" footer HTML", hf.FooterHTML.value)
+	}
+
+	if err := check("cover", pdfg.Cover.Input); err != nil {
+		return err
+	}
+	if err := checkOptions("cover", &pdfg.Cover.pageOptions, nil); err != nil {
+		return err
+	}
+	if err := checkOptions("TOC", &pdfg.TOC.pageOptions, &pdfg.TOC.headerAndFooterOptions); err != nil {
+		return err
+	}
+	for i, page := range pdfg.pages {
+		location := fmt.Sprintf("page %d", i+1)
+		if err := check(location, page.InputFile()); err != nil {
+			return err
+		}
+		opts := page.Options()
+		if err := checkOptions(location, &opts.pageOptions, &opts.headerAndFooterOptions); err != nil {
+			return err
+		}
+	}
+
+	if err := check("user style sheet", pdfg.userStyleSheetPath); err != nil {
+		return err
+	}
+	for _, styleSheet := range pdfg.userStyleSheets {
+		if err := check("user style sheet", styleSheet); err != nil {
+			return err
+		}
+	}
+	for _, size := range slices.Sorted(maps.Keys(pdfg.sizeStyleSheets)) {
+		if err := check("user style sheet for "+size, pdfg.sizeStyleSheets[size]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
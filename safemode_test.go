@@ -0,0 +1,95 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSafeMode(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	before := NewPage("testdata/htmlsimple.html")
+	before.EnableLocalFileAccess.Set(true)
+	before.Allow.Set("/etc")
+	pdfg.AddPage(before)
+	pdfg.SetSafeMode(true)
+	pdfg.AddPage(NewPageReader(strings.NewReader("<p>untrusted</p>")))
+
+	safeArgs := "--disable-external-links --disable-javascript --disable-local-file-access --proxy http://127.0.0.1:1 --proxy-hostname-lookup"
+	assert.Equal(t, "page testdata/htmlsimple.html "+safeArgs+" page - "+safeArgs+" -", pdfg.ArgString())
+	assert.NoError(t, pdfg.Validate())
+
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.TOC.Include = true
+	assert.Contains(t, pdfg.ArgString(), "cover testdata/htmlsimple.html "+safeArgs+" toc "+safeArgs)
+}
+
+func TestSafeModeURLs(t *testing.T) {
+	for input, allowed := range map[string]bool{
+		"testdata/htmlsimple.html":           true,
+		"data:text/html,<p>Hello</p>":        true,
+		"https://example.com":                false,
+		"http://169.254.169.254/latest/meta": false,
+		"file:///etc/passwd":                 false,
+	} {
+		pdfg := NewPDFPreparer()
+		pdfg.SetSafeMode(true)
+		pdfg.AddPage(NewPage(input))
+		err := pdfg.checkSafeMode()
+		if allowed {
+			assert.NoError(t, err, input)
+		} else {
+			assert.EqualError(t, err, "page 1: URL "+input+" is not allowed in safe mode")
+		}
+	}
+
+	pdfg := NewPDFPreparer()
+	pdfg.SetSafeMode(true)
+	pdfg.SetCover("https://example.com/cover.html")
+	assert.EqualError(t, pdfg.Validate(), "cover: URL https://example.com/cover.html is not allowed in safe mode")
+}
+
+func TestSafeModeStyleSheetURLs(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetSafeMode(true)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.Validate())
+
+	pdfg.SetUserStyleSheets("testdata/theme.css", "https://example.com/style.css")
+	assert.EqualError(t, pdfg.Validate(), "user style sheet: URL https://example.com/style.css is not allowed in safe mode")
+	pdfg.SetUserStyleSheets()
+
+	pdfg.SetUserStyleSheetFor(PageSizeLetter, "http://169.254.169.254/letter.css")
+	assert.EqualError(t, pdfg.Validate(), "user style sheet for letter: URL http://169.254.169.254/letter.css is not allowed in safe mode")
+	pdfg.SetUserStyleSheetFor(PageSizeLetter, "")
+
+	page := NewPage("testdata/htmlsimple.html")
+	page.UserStyleSheet.Set("https://example.com/page.css")
+	pdfg.AddPage(page)
+	assert.EqualError(t, pdfg.Validate(), "page 2 user style sheet: URL https://example.com/page.css is not allowed in safe mode")
+	page.UserStyleSheet.Unset()
+	page.FooterHTML.Set("https://example.com/footer.html")
+	assert.EqualError(t, pdfg.Validate(), "page 2 footer HTML: URL https://example.com/footer.html is not allowed in safe mode")
+	page.FooterHTML.Unset()
+
+	pdfg.TOC.UserStyleSheet.Set("https://example.com/toc.css")
+	assert.EqualError(t, pdfg.Validate(), "TOC user style sheet: URL https://example.com/toc.css is not allowed in safe mode")
+}
+
+func TestSafeModeJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetSafeMode(true)
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.True(t, restored.safeMode)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+
+	restored.AddPage(NewPage("https://example.com"))
+	assert.EqualError(t, restored.Validate(), "page 2: URL https://example.com is not allowed in safe mode")
+}
@@ -0,0 +1,132 @@
+package wkhtmltopdf
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StderrSeverity is the category of a line wkhtmltopdf printed on stderr
+type StderrSeverity int
+
+const (
+	SeverityProgress StderrSeverity = iota // Progress output like phases and progress bars
+	SeverityInfo                           // Any other output, like messages of Qt
+	SeverityWarning                        // Warnings, like resources that failed to load
+	SeverityError                          // Errors which usually make the run fail
+)
+
+func (s StderrSeverity) String() string {
+	switch s {
+	case SeverityProgress:
+		return "progress"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// StderrEvent is a classified line of the stderr output of wkhtmltopdf
+type StderrEvent struct {
+	Severity StderrSeverity
+	Message  string // The message, without a prefix like "Warning:"
+	Line     string // The complete line, with surrounding whitespace removed
+}
+
+// StderrRule classifies stderr lines matching Pattern as Severity.
+// If Pattern has a capture group, the first group is used as message of the event, otherwise the whole line.
+type StderrRule struct {
+	Pattern  *regexp.Regexp
+	Severity StderrSeverity
+}
+
+// DefaultStderrRules are the rules used to classify stderr lines if SetStderrRules was not called.
+// Lines which don't match any rule are SeverityInfo.
+var DefaultStderrRules = []StderrRule{
+	{regexp.MustCompile(`^Warning:\s*(.*)$`), SeverityWarning},
+	{regexp.MustCompile(`^Error:\s*(.*)$`), SeverityError},
+	{regexp.MustCompile(`^Exit with code \d+`), SeverityError},
+	{regexp.MustCompile(`Failed to load`), SeverityWarning},
+	{phaseRegex, SeverityProgress},
+	{regexp.MustCompile(`^\[[=> ]*\]\s*\d+%$`), SeverityProgress},
+	{regexp.MustCompile(`^Done$`), SeverityProgress},
+}
+
+// SetStderrRules sets the rules used to classify the stderr output of wkhtmltopdf into the StderrEvents of the
+// RenderResult. The first matching rule is used. Calling it without rules restores DefaultStderrRules.
+func (pdfg *PDFGenerator) SetStderrRules(rules ...StderrRule) {
+	pdfg.stderrRules = rules
+}
+
+// SetStderrHandler sets a function which is called for every classified stderr line while wkhtmltopdf runs,
+// for example to log errors as they happen. It is called from the goroutine reading stderr, one event at a time.
+func (pdfg *PDFGenerator) SetStderrHandler(handler func(StderrEvent)) {
+	pdfg.stderrHandler = handler
+}
+
+// classifyStderr returns the event for line using the first matching rule
+func classifyStderr(rules []StderrRule, line string) StderrEvent {
+	for _, rule := range rules {
+		m := rule.Pattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		msg := line
+		if len(m) > 1 {
+			msg = strings.TrimSpace(m[1])
+		}
+		return StderrEvent{Severity: rule.Severity, Message: msg, Line: line}
+	}
+	return StderrEvent{Severity: SeverityInfo, Message: line, Line: line}
+}
+
+// stderrScanner is a writer for the stderr output of wkhtmltopdf, which classifies each line
+type stderrScanner struct {
+	rules   []StderrRule
+	handler func(StderrEvent)
+	line    []byte
+	events  []StderrEvent
+}
+
+func newStderrScanner(rules []StderrRule, handler func(StderrEvent)) *stderrScanner {
+	if len(rules) == 0 {
+		rules = DefaultStderrRules
+	}
+	return &stderrScanner{rules: rules, handler: handler}
+}
+
+func (s *stderrScanner) Write(p []byte) (int, error) {
+	for _, c := range p {
+		// progress bars are updated with \r, so lines also end there
+		if c != '\n' && c != '\r' {
+			s.line = append(s.line, c)
+			continue
+		}
+		s.endLine()
+	}
+	return len(p), nil
+}
+
+func (s *stderrScanner) endLine() {
+	line := strings.TrimSpace(string(s.line))
+	s.line = s.line[:0]
+	if line == "" {
+		return
+	}
+	event := classifyStderr(s.rules, line)
+	s.events = append(s.events, event)
+	if s.handler != nil {
+		s.handler(event)
+	}
+}
+
+// flush classifies a last line without line ending and returns all events
+func (s *stderrScanner) flush() []StderrEvent {
+	if len(s.line) > 0 {
+		s.endLine()
+	}
+	return s.events
+}
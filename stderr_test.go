@@ -0,0 +1,68 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStderrScanner(t *testing.T) {
+	var handled []StderrEvent
+	s := newStderrScanner(nil, func(e StderrEvent) { handled = append(handled, e) })
+	s.Write([]byte("Loading pages (1/6)\n[>          ] 0%\r[==========>] 100%\r\n"))
+	s.Write([]byte("  Warning: Failed to load file:///missing.png (ignore)\n"))
+	s.Write([]byte("QFont::setPixelSize: Pixel size <= 0 (0)\nFailed to load http://example.com/a.css"))
+	s.Write([]byte(", with network status code 3\nError: Failed loading page http://invalid (sometimes it will work just to ignore this error with --load-error-handling ignore)\n"))
+	s.Write([]byte("Exit with code 1 due to network error: HostNotFoundError\nDone"))
+	events := s.flush()
+
+	want := []StderrEvent{
+		{SeverityProgress, "Loading pages", "Loading pages (1/6)"},
+		{SeverityProgress, "[>          ] 0%", "[>          ] 0%"},
+		{SeverityProgress, "[==========>] 100%", "[==========>] 100%"},
+		{SeverityWarning, "Failed to load file:///missing.png (ignore)", "Warning: Failed to load file:///missing.png (ignore)"},
+		{SeverityInfo, "QFont::setPixelSize: Pixel size <= 0 (0)", "QFont::setPixelSize: Pixel size <= 0 (0)"},
+		{SeverityWarning, "Failed to load http://example.com/a.css, with network status code 3", "Failed to load http://example.com/a.css, with network status code 3"},
+		{SeverityError, "Failed loading page http://invalid (sometimes it will work just to ignore this error with --load-error-handling ignore)", "Error: Failed loading page http://invalid (sometimes it will work just to ignore this error with --load-error-handling ignore)"},
+		{SeverityError, "Exit with code 1 due to network error: HostNotFoundError", "Exit with code 1 due to network error: HostNotFoundError"},
+		{SeverityProgress, "Done", "Done"},
+	}
+	assert.Equal(t, want, events)
+	assert.Equal(t, want, handled)
+}
+
+func TestStderrRules(t *testing.T) {
+	rules := append([]StderrRule{
+		{regexp.MustCompile(`^Warning: (Received createRequest signal.*)$`), SeverityInfo},
+	}, DefaultStderrRules...)
+	s := newStderrScanner(rules, nil)
+	s.Write([]byte("Warning: Received createRequest signal on a disposed ResourceObject's NetworkAccessManager.\nWarning: other\n"))
+	events := s.flush()
+	require.Len(t, events, 2)
+	assert.Equal(t, SeverityInfo, events[0].Severity)
+	assert.Equal(t, "Received createRequest signal on a disposed ResourceObject's NetworkAccessManager.", events[0].Message)
+	assert.Equal(t, SeverityWarning, events[1].Severity)
+	assert.Equal(t, "warning", events[1].Severity.String())
+}
+
+func TestCreateWithResultStderrEvents(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+
+	htmlfile, err := os.ReadFile("testdata/htmlsimple.html")
+	require.NoError(t, err)
+	pdfg.AddPage(NewPageReader(bytes.NewReader(htmlfile)))
+
+	var handled int
+	pdfg.SetStderrHandler(func(StderrEvent) { handled++ })
+	result, err := pdfg.CreateWithResult(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, result.StderrEvents)
+	assert.Equal(t, len(result.StderrEvents), handled)
+	assert.Equal(t, StderrEvent{SeverityProgress, "Loading pages", "Loading pages (1/6)"}, result.StderrEvents[0])
+}
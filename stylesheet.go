@@ -0,0 +1,161 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultHTTPTimeout is the timeout for fetching style sheets from a URL if none is set with SetHTTPTimeout
+const defaultHTTPTimeout = 30 * time.Second
+
+// SetHTTPTimeout sets the timeout for fetching style sheets from http(s) URLs, see SetUserStyleSheet.
+// The default is 30 seconds. Fetching is canceled as well when the context of CreateContext is done.
+func (pdfg *PDFGenerator) SetHTTPTimeout(timeout time.Duration) {
+	pdfg.httpTimeout = timeout
+}
+
+// SetAssetCacheDir sets a directory where style sheets fetched from http(s) URLs are stored and reused by
+// later runs, instead of fetching them again for every Create. Delete the files in the directory to fetch
+// them again. Without a cache directory, fetched style sheets are written to temporary files which are
+// removed after Create.
+func (pdfg *PDFGenerator) SetAssetCacheDir(dir string) {
+	pdfg.assetCacheDir = dir
+}
+
+// SetUserStyleSheetFor sets a style sheet which is used instead of the default style sheet (see SetUserStyleSheet
+// and SetUserStyleSheets) when the PDF is created with the page size pageSize, like PageSizeA4 or PageSizeLetter,
+// for CSS which depends on the size, like margins and font scaling. The size is compared without case, an unset
+// PageSize counts as A4 (the default of wkhtmltopdf) and a custom size set with PageWidth and PageHeight only
+// uses the default style sheet. The style sheet is chosen at Create, so one generator can render all sizes, and
+// is used for the pages without their own UserStyleSheet. Validate returns an error if there is neither a style
+// sheet for the page size nor a default style sheet. An empty path removes the style sheet of the size. Like for
+// SetUserStyleSheet, the path can be a http(s) URL.
+func (pdfg *PDFGenerator) SetUserStyleSheetFor(pageSize string, path string) {
+	if path == "" {
+		delete(pdfg.sizeStyleSheets, strings.ToLower(pageSize))
+		return
+	}
+	if pdfg.sizeStyleSheets == nil {
+		pdfg.sizeStyleSheets = make(map[string]string)
+	}
+	pdfg.sizeStyleSheets[strings.ToLower(pageSize)] = path
+}
+
+// pageSizeName returns the page size in lower case, "a4" if none is set, or "" for a custom page size
+func (pdfg *PDFGenerator) pageSizeName() string {
+	switch {
+	case pdfg.PageWidth.isSet || pdfg.PageHeight.isSet || pdfg.PageWidthUnit.value != "" || pdfg.PageHeightUnit.value != "":
+		return ""
+	case pdfg.PageSize.value == "":
+		return strings.ToLower(PageSizeA4)
+	}
+	return strings.ToLower(pdfg.PageSize.value)
+}
+
+// sizeStyleSheet returns the style sheet set with SetUserStyleSheetFor for the page size, or "" if there is none
+func (pdfg *PDFGenerator) sizeStyleSheet() string {
+	if size := pdfg.pageSizeName(); size != "" {
+		return pdfg.sizeStyleSheets[size]
+	}
+	return ""
+}
+
+// checkSizeStyleSheets returns an error if style sheets are set with SetUserStyleSheetFor, but none for the page
+// size and no default style sheet
+func (pdfg *PDFGenerator) checkSizeStyleSheets() error {
+	if len(pdfg.sizeStyleSheets) == 0 || pdfg.sizeStyleSheet() != "" || pdfg.userStyleSheetPath != "" || len(pdfg.userStyleSheets) > 0 {
+		return nil
+	}
+	size := pdfg.PageSize.value
+	switch {
+	case pdfg.pageSizeName() == "":
+		size = "custom"
+	case size == "":
+		size = PageSizeA4
+	}
+	return fmt.Errorf("no user style sheet for page size %s and no default style sheet", size)
+}
+
+// isHTTPURL returns true if s is a http or https URL
+func isHTTPURL(s string) bool {
+	s = strings.ToLower(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// styleSheetFetcher fetches style sheets from URLs to local files for one run, each URL is fetched only once
+type styleSheetFetcher struct {
+	pdfg  *PDFGenerator
+	paths map[string]string
+}
+
+// localPath returns a local path for the style sheet, which is fetched first if it is a http(s) URL
+func (f *styleSheetFetcher) localPath(ctx context.Context, styleSheet string) (string, error) {
+	if !isHTTPURL(styleSheet) {
+		return styleSheet, nil
+	}
+	if path, ok := f.paths[styleSheet]; ok {
+		return path, nil
+	}
+
+	var cachePath string
+	if f.pdfg.assetCacheDir != "" {
+		sum := sha256.Sum256([]byte(styleSheet))
+		cachePath = filepath.Join(f.pdfg.assetCacheDir, "stylesheet-"+hex.EncodeToString(sum[:])+".css")
+		if _, err := os.Stat(cachePath); err == nil {
+			f.paths[styleSheet] = cachePath
+			return cachePath, nil
+		}
+	}
+
+	css, err := f.fetch(ctx, styleSheet)
+	if err != nil {
+		return "", err
+	}
+	var path string
+	if cachePath != "" {
+		// written to a temporary file which is renamed, so concurrent runs never read a partial style sheet
+		if err := WriteFileFrom(ctx, bytes.NewReader(css), cachePath, nil); err != nil {
+			return "", fmt.Errorf("error writing style sheet to asset cache: %w", err)
+		}
+		path = cachePath
+	} else if path, err = f.pdfg.createTempFile("stylesheet-*.css", css); err != nil {
+		return "", err
+	}
+	f.paths[styleSheet] = path
+	return path, nil
+}
+
+// fetch returns the content of the style sheet at url, the request is canceled when ctx is done
+func (f *styleSheetFetcher) fetch(ctx context.Context, url string) ([]byte, error) {
+	timeout := f.pdfg.httpTimeout
+	if timeout <= 0 {
+		timeout = defaultHTTPTimeout
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching style sheet: %w", err)
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching style sheet: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("error fetching style sheet %s: %s", url, resp.Status)
+	}
+	css, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching style sheet %s: %w", url, err)
+	}
+	return css, nil
+}
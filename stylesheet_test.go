@@ -0,0 +1,174 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStyleSheetServer(t *testing.T, requests *int) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		switch r.URL.Path {
+		case "/print.css":
+			w.Write([]byte("body { color: navy; }"))
+		case "/slow.css":
+			time.Sleep(200 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestUserStyleSheetURL(t *testing.T) {
+	var requests int
+	srv := newStyleSheetServer(t, &requests)
+
+	pdfg := NewPDFPreparer()
+	pdfg.SetUserStyleSheet(srv.URL + "/print.css")
+	page1 := NewPage("testdata/htmlsimple.html")
+	page2 := NewPage("testdata/htmlsimple.html")
+	pdfg.AddPage(page1)
+	pdfg.AddPage(page2)
+
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	path := page1.UserStyleSheet.value
+	assert.Equal(t, path, page2.UserStyleSheet.value)
+	css, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "body { color: navy; }", string(css))
+	assert.Equal(t, 1, requests, "the style sheet is fetched once per run")
+
+	cleanup()
+	assert.Equal(t, srv.URL+"/print.css", page1.UserStyleSheet.value)
+	assert.NoFileExists(t, path)
+}
+
+func TestUserStyleSheetURLCacheDir(t *testing.T) {
+	var requests int
+	srv := newStyleSheetServer(t, &requests)
+
+	pdfg := NewPDFPreparer()
+	pdfg.SetAssetCacheDir(t.TempDir())
+	pdfg.SetUserStyleSheets("testdata/theme.css", srv.URL+"/print.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+
+	for i := 0; i < 2; i++ {
+		cleanup, err := pdfg.prepare(context.Background())
+		require.NoError(t, err)
+		css, err := os.ReadFile(pdfg.pages[0].Options().UserStyleSheet.value)
+		require.NoError(t, err)
+		assert.Contains(t, string(css), "body { color: navy; }")
+		cleanup()
+	}
+	assert.Equal(t, 1, requests, "the cached style sheet is reused")
+	files, err := os.ReadDir(pdfg.assetCacheDir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1, "no temporary file is left in the cache")
+}
+
+func TestUserStyleSheetURLErrors(t *testing.T) {
+	var requests int
+	srv := newStyleSheetServer(t, &requests)
+
+	pdfg := NewPDFPreparer()
+	pdfg.SetUserStyleSheet(srv.URL + "/missing.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	_, err := pdfg.prepare(context.Background())
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	pdfg = NewPDFPreparer()
+	pdfg.SetHTTPTimeout(50 * time.Millisecond)
+	pdfg.SetUserStyleSheet(srv.URL + "/slow.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	_, err = pdfg.prepare(context.Background())
+	assert.ErrorContains(t, err, "error fetching style sheet")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	pdfg = NewPDFPreparer()
+	pdfg.SetUserStyleSheet(srv.URL + "/print.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	_, err = pdfg.prepare(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestUserStyleSheetFor(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetUserStyleSheet("testdata/theme.css")
+	pdfg.SetUserStyleSheetFor(PageSizeA4, "testdata/pagesize/a4.css")
+	pdfg.SetUserStyleSheetFor("letter", "testdata/pagesize/letter.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	own := NewPage("testdata/multipage.html")
+	own.UserStyleSheet.Set("testdata/allowlist/local.css")
+	pdfg.AddPage(own)
+
+	tests := []struct {
+		pageSize string
+		want     string
+	}{
+		{"", "testdata/pagesize/a4.css"},
+		{PageSizeLetter, "testdata/pagesize/letter.css"},
+		{PageSizeLegal, "testdata/theme.css"},
+	}
+	for _, tt := range tests {
+		pdfg.PageSize.Set(tt.pageSize)
+		require.NoError(t, pdfg.Validate())
+		cleanup, err := pdfg.prepare(context.Background())
+		require.NoError(t, err)
+		args := pdfg.Args()
+		cleanup()
+		assert.Equal(t, []string{tt.want, "testdata/allowlist/local.css"}, userStyleSheetArgs(args), tt.pageSize)
+	}
+	assert.Equal(t, []string{"testdata/theme.css", "testdata/allowlist/local.css"}, userStyleSheetArgs(pdfg.Args()))
+
+	// the style sheets by size are restored from JSON
+	b, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	restored.PageSize.Set(PageSizeLetter)
+	cleanup, err := restored.prepare(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"testdata/pagesize/letter.css", "testdata/allowlist/local.css"}, userStyleSheetArgs(restored.Args()))
+	cleanup()
+}
+
+func TestUserStyleSheetForValidate(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetUserStyleSheetFor(PageSizeLetter, "testdata/pagesize/letter.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.EqualError(t, pdfg.Validate(), "no user style sheet for page size A4 and no default style sheet")
+	require.NoError(t, pdfg.SetCustomPageSize("100mm", "150mm"))
+	assert.EqualError(t, pdfg.Validate(), "no user style sheet for page size custom and no default style sheet")
+
+	pdfg = NewPDFPreparer()
+	pdfg.PageSize.Set(PageSizeLetter)
+	pdfg.SetUserStyleSheetFor(PageSizeLetter, "testdata/pagesize/missing.css")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.ErrorContains(t, pdfg.Validate(), "user style sheet for page size: testdata/pagesize/missing.css")
+
+	pdfg.SetUserStyleSheetFor(PageSizeLetter, "")
+	assert.NoError(t, pdfg.Validate())
+}
+
+// userStyleSheetArgs returns the values of the --user-style-sheet arguments
+func userStyleSheetArgs(args []string) []string {
+	var paths []string
+	for i, arg := range args {
+		if arg == "--user-style-sheet" && i+1 < len(args) {
+			paths = append(paths, args[i+1])
+		}
+	}
+	return paths
+}
@@ -0,0 +1,252 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SetTagged adds a basic structure tree to the PDF, for screen readers and accessibility checks which require a
+// tagged PDF. wkhtmltopdf writes untagged PDFs, so the structure is built from the HTML of the pages (including
+// the HTML converted from Markdown and AsciiDoc): headings, paragraphs, lists, tables and images become H1 to H6,
+// P, L and LI, Table, TR, TH and TD and Figure elements, in the order of the HTML, with their text as ActualText
+// and the alt text of images as Alt. The content of each PDF page is marked as one marked-content sequence,
+// linked to a Sect element of the structure tree after these elements, so assistive technology still reads all
+// text on the pages in the order wkhtmltopdf draws it.
+// This is best effort and the PDF is not PDF/UA compliant: the elements built from the HTML are not linked to the
+// text on the pages, which is only tagged per page, links are not tagged, other elements (like div or span) only
+// contribute their text to the element they are in, and text outside these elements, the cover, the TOC, headers
+// and footers and pages loaded from URLs are not included in them. Set the language with SetLang as well.
+func (pdfg *PDFGenerator) SetTagged(tagged bool) {
+	pdfg.tagged = tagged
+}
+
+// structElem is an element of the structure tree built by parseStructure
+type structElem struct {
+	tag  string // The HTML tag
+	role string // The standard structure type, like H1 or P
+	text strings.Builder
+	alt  string
+	kids []*structElem
+}
+
+// structRoles are the standard structure types of the HTML elements in the structure tree
+var structRoles = map[string]string{
+	"h1": "H1", "h2": "H2", "h3": "H3", "h4": "H4", "h5": "H5", "h6": "H6", "p": "P", "blockquote": "BlockQuote",
+	"pre": "Code", "ul": "L", "ol": "L", "li": "LI", "table": "Table", "tr": "TR", "th": "TH", "td": "TD",
+	"img": "Figure",
+}
+
+// structAutoClose are the elements which are closed by the start tag of an element, when the end tag is omitted
+var structAutoClose = map[string][]string{
+	"p": {"p"}, "li": {"li"}, "tr": {"td", "th", "tr"}, "td": {"td", "th"}, "th": {"td", "th"},
+	"h1": {"p"}, "h2": {"p"}, "h3": {"p"}, "h4": {"p"}, "h5": {"p"}, "h6": {"p"}, "ul": {"p"}, "ol": {"p"},
+	"table": {"p"}, "blockquote": {"p"}, "pre": {"p"},
+}
+
+var (
+	htmlAltRegex        = regexp.MustCompile(`(?is)\salt\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	htmlWhitespaceRegex = regexp.MustCompile(`\s+`)
+)
+
+// parseStructure adds the structure elements of an HTML document to root, see SetTagged
+func parseStructure(root *structElem, content []byte) {
+	stack := []*structElem{root}
+	lower := bytes.ToLower(content)
+	text := func(data []byte) {
+		if top := stack[len(stack)-1]; top != root {
+			top.text.WriteString(html.UnescapeString(string(data)))
+		}
+	}
+	pos := 0
+	for {
+		loc := htmlTokenRegex.FindSubmatchIndex(content[pos:])
+		if loc == nil {
+			text(content[pos:])
+			break
+		}
+		for i := range loc {
+			if loc[i] >= 0 {
+				loc[i] += pos
+			}
+		}
+		text(content[pos:loc[0]])
+		pos = loc[1]
+		if loc[4] < 0 {
+			continue // comments, <!DOCTYPE html> and the like
+		}
+		name := string(lower[loc[4]:loc[5]])
+		if loc[3] > loc[2] { // end tag
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].tag == name {
+					stack = stack[:i]
+					break
+				}
+			}
+			continue
+		}
+		if rawTextElements[name] || name == "head" {
+			// the content is not shown
+			end := bytes.Index(lower[pos:], []byte("</"+name))
+			if end < 0 {
+				break
+			}
+			pos += end
+			continue
+		}
+		role, ok := structRoles[name]
+		if !ok {
+			continue
+		}
+		for len(stack) > 1 && slices.Contains(structAutoClose[name], stack[len(stack)-1].tag) {
+			stack = stack[:len(stack)-1]
+		}
+		elem := &structElem{tag: name, role: role}
+		parent := stack[len(stack)-1]
+		parent.kids = append(parent.kids, elem)
+		if name == "img" {
+			if m := htmlAltRegex.FindSubmatch(content[loc[0]:loc[1]]); m != nil {
+				elem.alt = html.UnescapeString(string(bytes.Join(m[1:], nil)))
+			}
+			continue
+		}
+		stack = append(stack, elem)
+	}
+}
+
+// structureHTML returns the HTML of the pages for the structure tree, stdin is the content of the page read from
+// stdin. Pages loaded from URLs are skipped.
+func (pdfg *PDFGenerator) structureHTML(stdin []byte) ([][]byte, error) {
+	var pages [][]byte
+	for i := range pdfg.pages {
+		input := pdfg.pageInput(i)
+		if input == "-" {
+			pages = append(pages, stdin)
+			continue
+		}
+		if path, ok := localPath(input); ok {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("error reading page input for structure tree: %w", err)
+			}
+			pages = append(pages, b)
+		}
+	}
+	return pages, nil
+}
+
+// setStructTree returns a post-processor which adds a structure tree built from the HTML of the pages and links
+// the content of each page to the tree, see SetTagged
+func setStructTree(pages [][]byte) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		root := &structElem{role: "Document"}
+		for _, page := range pages {
+			parseStructure(root, page)
+		}
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			pageRefs, err := doc.pages()
+			if err != nil {
+				return err
+			}
+
+			treeRoot := newPDFDict()
+			treeRoot.Set("Type", pdfName("StructTreeRoot"))
+			treeRootRef := doc.add(treeRoot)
+			documentRef := doc.addStructElem(root, treeRootRef)
+			treeRoot.Set("K", documentRef)
+
+			// the content of each page is a Sect element after the elements from the HTML, so assistive
+			// technology reads the text on the pages; the parent tree maps the pages to their Sect element
+			document := doc.dict(documentRef)
+			kids := document.Get("K").(pdfArray)
+			nums := pdfArray{}
+			for i, ref := range pageRefs {
+				marked, err := doc.markContent(ref, "Sect")
+				if err != nil {
+					return err
+				}
+				if !marked {
+					continue
+				}
+				sect := newPDFDict()
+				sect.Set("Type", pdfName("StructElem"))
+				sect.Set("S", pdfName("Sect"))
+				sect.Set("P", documentRef)
+				sect.Set("Pg", ref)
+				sect.Set("K", pdfInt(0))
+				sectRef := doc.add(sect)
+				kids = append(kids, sectRef)
+				doc.dict(ref).Set("StructParents", pdfInt(i))
+				nums = append(nums, pdfInt(i), pdfArray{sectRef})
+			}
+			document.Set("K", kids)
+			parentTree := newPDFDict()
+			parentTree.Set("Nums", nums)
+			treeRoot.Set("ParentTree", doc.add(parentTree))
+			treeRoot.Set("ParentTreeNextKey", pdfInt(len(pageRefs)))
+
+			cat.Set("StructTreeRoot", treeRootRef)
+			markInfo := newPDFDict()
+			markInfo.Set("Marked", true)
+			cat.Set("MarkInfo", markInfo)
+			return nil
+		})
+	}
+}
+
+// addStructElem adds elem and its kids as structure elements with the parent element parent
+func (doc *pdfDocument) addStructElem(elem *structElem, parent pdfRef) pdfRef {
+	dict := newPDFDict()
+	dict.Set("Type", pdfName("StructElem"))
+	dict.Set("S", pdfName(elem.role))
+	dict.Set("P", parent)
+	ref := doc.add(dict)
+	if text := strings.TrimSpace(htmlWhitespaceRegex.ReplaceAllString(elem.text.String(), " ")); text != "" {
+		dict.Set("ActualText", pdfTextString(text))
+	}
+	if elem.role == "Figure" {
+		dict.Set("Alt", pdfTextString(elem.alt))
+	}
+	kids := pdfArray{}
+	for _, kid := range elem.kids {
+		kids = append(kids, doc.addStructElem(kid, ref))
+	}
+	dict.Set("K", kids)
+	return ref
+}
+
+// markContent wraps the content of a page in a marked-content sequence with the tag and marked-content ID 0, it
+// returns false if the page has no content
+func (doc *pdfDocument) markContent(ref pdfRef, tag pdfName) (bool, error) {
+	page := doc.dict(ref)
+	if page == nil {
+		return false, fmt.Errorf("PDF page %d not found", ref.num)
+	}
+	var contents pdfArray
+	switch v := page.Get("Contents").(type) {
+	case nil:
+		return false, nil
+	case pdfRef:
+		if arr, ok := doc.resolve(v).(pdfArray); ok {
+			contents = arr
+		} else {
+			contents = pdfArray{v}
+		}
+	case pdfArray:
+		contents = v
+	default:
+		return false, fmt.Errorf("invalid contents of PDF page %d", ref.num)
+	}
+	begin := doc.add(&pdfStream{dict: newPDFDict(), data: []byte("/" + string(tag) + " <</MCID 0>> BDC\n")})
+	end := doc.add(&pdfStream{dict: newPDFDict(), data: []byte("\nEMC\n")})
+	page.Set("Contents", append(append(pdfArray{begin}, contents...), end))
+	return true, nil
+}
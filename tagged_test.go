@@ -0,0 +1,138 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// structOutline returns the roles and texts of the structure elements in depth-first order, indented by level
+func structOutline(elem *structElem, indent string) []string {
+	line := indent + elem.role
+	if text := strings.TrimSpace(htmlWhitespaceRegex.ReplaceAllString(elem.text.String(), " ")); text != "" {
+		line += " " + text
+	}
+	if elem.alt != "" {
+		line += " alt=" + elem.alt
+	}
+	lines := []string{line}
+	for _, kid := range elem.kids {
+		lines = append(lines, structOutline(kid, indent+"  ")...)
+	}
+	return lines
+}
+
+func TestParseStructure(t *testing.T) {
+	root := &structElem{role: "Document"}
+	parseStructure(root, []byte(readMarkdownHTML(t, NewMarkdownPage("testdata/tagged.md"))))
+	assert.Equal(t, []string{
+		"Document",
+		"  H1 User Guide",
+		"  P Welcome to the guide & reference.",
+		"  H2 Steps",
+		"  L",
+		"    LI Install the package",
+		"    LI Configure it",
+		"      L",
+		"        LI set the path",
+		"        LI set the user",
+		"  Table",
+		"    TR",
+		"      TH Option",
+		"      TH Default",
+		"    TR",
+		"      TD Path",
+		"      TD /usr",
+		"  P",
+		"    Figure alt=Architecture diagram",
+	}, structOutline(root, ""))
+
+	// end tags which may be omitted, and content which is not shown
+	root = &structElem{role: "Document"}
+	parseStructure(root, []byte("<html><head><title>T</title><style>p{}</style></head><p>One<p>Two<ul><li>a<li>b</ul><script>x</script>"))
+	assert.Equal(t, []string{"Document", "  P One", "  P Two", "  L", "    LI a", "    LI b"}, structOutline(root, ""))
+}
+
+func TestSetStructTree(t *testing.T) {
+	html := []byte("<h1>Title</h1><p>Grüße</p>")
+	pdf, err := setStructTree([][]byte{html})(newTestPDF(2))
+	require.NoError(t, err)
+
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	assert.Equal(t, true, doc.dict(cat.Get("MarkInfo")).Get("Marked"))
+	treeRoot := doc.dict(cat.Get("StructTreeRoot"))
+	require.NotNil(t, treeRoot)
+	assert.Equal(t, pdfName("StructTreeRoot"), treeRoot.Get("Type"))
+	document := doc.dict(treeRoot.Get("K"))
+	assert.Equal(t, pdfName("Document"), document.Get("S"))
+	kids := document.Get("K").(pdfArray)
+	require.Len(t, kids, 4)
+	assert.Equal(t, pdfName("H1"), doc.dict(kids[0]).Get("S"))
+	assert.Equal(t, pdfString("Title"), doc.dict(kids[0]).Get("ActualText"))
+	assert.Equal(t, pdfString("\xfe\xff\x00G\x00r\x00\xfc\x00\xdf\x00e"), doc.dict(kids[1]).Get("ActualText"))
+	assert.Equal(t, treeRoot.Get("K"), doc.dict(kids[0]).Get("P"))
+	assert.Equal(t, cat.Get("StructTreeRoot"), document.Get("P"))
+
+	// the content of each page is a marked-content sequence with MCID 0, which belongs to a Sect element for the
+	// page, and the parent tree maps the page back to the element
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	parentTree := doc.dict(treeRoot.Get("ParentTree"))
+	require.NotNil(t, parentTree)
+	nums := parentTree.Get("Nums").(pdfArray)
+	require.Len(t, nums, 4)
+	assert.Equal(t, pdfNumber("2"), treeRoot.Get("ParentTreeNextKey"))
+	for i, ref := range pages {
+		page := doc.dict(ref)
+		var content []byte
+		for _, s := range page.Get("Contents").(pdfArray) {
+			data, err := doc.decodeStream(doc.resolve(s).(*pdfStream))
+			require.NoError(t, err)
+			content = append(content, data...)
+		}
+		assert.Equal(t, "/Sect <</MCID 0>> BDC\n"+testPageContent(i+1)+"\nEMC\n", string(content))
+		assert.NotContains(t, string(content), "/Artifact")
+
+		key, ok := doc.intValue(page.Get("StructParents"))
+		require.True(t, ok)
+		assert.Equal(t, pdfNumber(fmt.Sprint(key)), nums[2*i])
+		sectRef := doc.resolve(nums[2*i+1]).(pdfArray)[0]
+		assert.Equal(t, kids[2+i], sectRef)
+		sect := doc.dict(sectRef)
+		assert.Equal(t, pdfName("Sect"), sect.Get("S"))
+		assert.Equal(t, ref, sect.Get("Pg"))
+		assert.Equal(t, pdfNumber("0"), sect.Get("K"))
+		assert.Equal(t, treeRoot.Get("K"), sect.Get("P"))
+	}
+}
+
+func TestSetTagged(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.SetTagged(true)
+	pdfg.AddPage(NewMarkdownPage("testdata/tagged.md"))
+	pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) {
+		// post-processors added with AddPostProcessor see the structure tree
+		assert.Contains(t, string(pdf), "/StructTreeRoot")
+		return pdf, nil
+	})
+	require.NoError(t, pdfg.SetPDFVersion("1.7"))
+	result, err := pdfg.CreateWithResult(context.Background())
+	require.NoError(t, err)
+
+	doc, err := parsePDF(result.Bytes)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	treeRoot := doc.dict(cat.Get("StructTreeRoot"))
+	require.NotNil(t, treeRoot)
+	kids := doc.dict(treeRoot.Get("K")).Get("K").(pdfArray)
+	assert.Equal(t, pdfString("User Guide"), doc.dict(kids[0]).Get("ActualText"))
+}
@@ -0,0 +1,50 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+)
+
+// SetTOCMinPages leaves out the TOC (see TOC.Include) when the document has less than n pages, so short documents
+// don't get a TOC with a single entry. The length of the document is only known after rendering, so the cover and
+// the pages added with AddPage are rendered once without the TOC to count their pages, and the PDF is then
+// rendered with or without the TOC. This costs an extra wkhtmltopdf run each time a PDF with a TOC is created,
+// also when the TOC is left out. 0 or less always includes the TOC, which is the default. TOC.Include is not
+// changed.
+func (pdfg *PDFGenerator) SetTOCMinPages(n int) {
+	pdfg.tocMinPages = max(n, 0)
+}
+
+// includeTOC returns whether the TOC is part of the current run
+func (pdfg *PDFGenerator) includeTOC() bool {
+	return pdfg.TOC.Include && !pdfg.skipTOC
+}
+
+// checkTOCMinPages renders the cover and pages without the TOC to count them for SetTOCMinPages, and leaves out
+// the TOC for the current run if there are less pages than the minimum. stdin is the content of the page read from
+// stdin. The returned function includes the TOC again, it must always be called.
+func (pdfg *PDFGenerator) checkTOCMinPages(ctx context.Context, stdin []byte) (func(), error) {
+	reset := func() { pdfg.skipTOC = false }
+	if pdfg.tocMinPages == 0 || !pdfg.TOC.Include || len(pdfg.pages) == 0 {
+		return reset, nil
+	}
+
+	args := pdfg.countArgs()
+	args = append(args, pdfg.outlineOptions.Args()...)
+	if pdfg.Cover.Input != "" {
+		args = append(args, "cover", pdfg.Cover.Input)
+		args = append(args, pdfg.Cover.pageOptions.Args()...)
+	}
+	for i, page := range pdfg.pages {
+		args = append(args, "page", pdfg.pageInput(i))
+		args = append(args, page.Args()...)
+	}
+	args = append(args, "-")
+
+	pages, err := pdfg.renderPageCount(ctx, args, stdin)
+	if err != nil {
+		return reset, fmt.Errorf("error counting pages for the TOC: %w", err)
+	}
+	pdfg.skipTOC = pages < pdfg.tocMinPages
+	return reset, nil
+}
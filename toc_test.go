@@ -0,0 +1,76 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTOCMinPages(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	// a fake wkhtmltopdf which logs its arguments and renders toclong.html to 5 pages and the rest to 1 page,
+	// plus 1 page for the TOC
+	dir := t.TempDir()
+	for _, pages := range []int{1, 5, 6} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.pdf", pages)), newTestPDF(pages), 0644))
+	}
+	log := filepath.Join(dir, "args.log")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\npages=1\ncase \"$*\" in *toclong.html*) pages=5;; esac\n" +
+		"case \" $* \" in *' toc '*) pages=$((pages+1));; esac\ncat " + dir + "/$pages.pdf\n"
+	bin := filepath.Join(dir, "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(bin, []byte(script), 0755))
+
+	create := func(input string) (runs []string, pages int) {
+		require.NoError(t, os.WriteFile(log, nil, 0644))
+		pdfg := NewPDFPreparer()
+		pdfg.binPath = bin
+		pdfg.TOC.Include = true
+		pdfg.SetTOCMinPages(3)
+		pdfg.AddPage(NewPage(input))
+		require.NoError(t, pdfg.CreateContext(context.Background()))
+		assert.True(t, pdfg.TOC.Include)
+		assert.Contains(t, pdfg.Args(), "toc")
+		b, err := os.ReadFile(log)
+		require.NoError(t, err)
+		pages, err = pdfg.PageCount()
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(b)), "\n"), pages
+	}
+
+	// the short document is rendered without the TOC
+	runs, pages := create("testdata/tocshort.html")
+	require.Len(t, runs, 2)
+	assert.NotContains(t, strings.Fields(runs[0]), "toc")
+	assert.NotContains(t, strings.Fields(runs[1]), "toc")
+	assert.Equal(t, 1, pages)
+
+	// the long document gets the TOC
+	runs, pages = create("testdata/toclong.html")
+	require.Len(t, runs, 2)
+	assert.NotContains(t, strings.Fields(runs[0]), "toc")
+	assert.Contains(t, strings.Fields(runs[1]), "toc")
+	assert.Equal(t, 6, pages)
+}
+
+func TestSetTOCMinPagesDisabled(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.TOC.Include = true
+	pdfg.AddPage(NewPage("testdata/tocshort.html"))
+	pdfg.SetTOCMinPages(-1)
+	assert.Equal(t, 0, pdfg.tocMinPages)
+
+	// without a minimum the pages are not counted
+	reset, err := pdfg.checkTOCMinPages(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, pdfg.includeTOC())
+	reset()
+}
@@ -0,0 +1,140 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// TOCConfig is the appearance of the TOC set with ConfigureTOC
+type TOCConfig struct {
+	// Title is the heading of the TOC, empty is "Table of Contents".
+	Title string
+	// DepthLimit is the number of heading levels listed, like 2 for chapters and sections. 0 lists all levels.
+	DepthLimit int
+	// DottedLines draws a dotted line under each entry, which leads from the heading to its page number.
+	DottedLines bool
+	// FontSize is the font size of the entries, a length like "12pt" (see ParseLength). Empty is 12pt.
+	FontSize string
+	// IndentPerLevel is how far each level of headings is indented, a length like "5mm" (see ParseLength).
+	// Empty is 5mm.
+	IndentPerLevel string
+}
+
+// ConfigureTOC includes the TOC (see TOC.Include) and sets its appearance, without the need to write an XSL style
+// sheet for wkhtmltopdf: the TOC is styled by an XSL style sheet generated from cfg, which is written to a
+// temporary file for Create and removed afterwards. It is used instead of TOC.XslStyleSheet, and TOC options
+// which change the appearance, like TocHeaderText and DisableDottedLines, have no effect. The entries are still
+// links to their headings unless DisableTocLinks is set.
+// An error is returned for a negative depth limit or an invalid length, in which case nothing is changed.
+func (pdfg *PDFGenerator) ConfigureTOC(cfg TOCConfig) error {
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+	pdfg.tocConfig = &cfg
+	pdfg.TOC.Include = true
+	return nil
+}
+
+// validate returns an error if cfg can't be used for the TOC
+func (cfg TOCConfig) validate() error {
+	if cfg.DepthLimit < 0 {
+		return fmt.Errorf("invalid TOC depth limit %d, use 0 for all levels", cfg.DepthLimit)
+	}
+	if cfg.FontSize != "" {
+		if _, err := ParseLength(cfg.FontSize); err != nil {
+			return fmt.Errorf("invalid TOC font size: %w", err)
+		}
+	}
+	if cfg.IndentPerLevel != "" {
+		if _, err := ParseLength(cfg.IndentPerLevel); err != nil {
+			return fmt.Errorf("invalid TOC indentation: %w", err)
+		}
+	}
+	return nil
+}
+
+// xsl returns the XSL style sheet which transforms the outline of wkhtmltopdf to the HTML of the TOC, based on the
+// default style sheet of wkhtmltopdf (see wkhtmltopdf --dump-default-toc-xsl). cfg must be valid.
+func (cfg TOCConfig) xsl() []byte {
+	title := cfg.Title
+	if title == "" {
+		title = "Table of Contents"
+	}
+	fontSize, indent := "12pt", "5mm"
+	if l, err := ParseLength(cfg.FontSize); err == nil {
+		fontSize = l.String()
+	}
+	if l, err := ParseLength(cfg.IndentPerLevel); err == nil {
+		indent = l.String()
+	}
+	line := "none"
+	if cfg.DottedLines {
+		line = "1px dotted #999"
+	}
+
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<xsl:stylesheet version="2.0" xmlns:xsl="http://www.w3.org/1999/XSL/Transform"
+                xmlns:outline="http://wkhtmltopdf.org/outline" xmlns="http://www.w3.org/1999/xhtml">
+  <xsl:output doctype-public="-//W3C//DTD XHTML 1.0 Strict//EN"
+              doctype-system="http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd" indent="yes" />
+  <xsl:template match="outline:outline">
+    <html>
+      <head>
+`)
+	fmt.Fprintf(&b, "        <title>%s</title>\n", html.EscapeString(title))
+	b.WriteString(`        <meta http-equiv="Content-Type" content="text/html; charset=utf-8" />
+        <style>
+`)
+	b.WriteString("          h1 { text-align: center; font-family: arial; }\n")
+	fmt.Fprintf(&b, "          div { border-bottom: %s; }\n", line)
+	b.WriteString("          span { float: right; }\n")
+	b.WriteString("          li { list-style: none; }\n")
+	fmt.Fprintf(&b, "          ul { font-size: %s; font-family: arial; padding-left: 0; }\n", fontSize)
+	fmt.Fprintf(&b, "          ul ul { padding-left: %s; }\n", indent)
+	b.WriteString("          a { text-decoration: none; color: black; }\n")
+	b.WriteString(`        </style>
+      </head>
+      <body>
+`)
+	fmt.Fprintf(&b, "        <h1>%s</h1>\n", html.EscapeString(title))
+	b.WriteString(`        <ul><xsl:apply-templates select="outline:item/outline:item"/></ul>
+      </body>
+    </html>
+  </xsl:template>
+  <xsl:template match="outline:item">
+    <li>
+      <xsl:if test="@title!=''">
+        <div>
+          <a>
+            <xsl:if test="@link">
+              <xsl:attribute name="href"><xsl:value-of select="@link"/></xsl:attribute>
+            </xsl:if>
+            <xsl:if test="@backLink">
+              <xsl:attribute name="name"><xsl:value-of select="@backLink"/></xsl:attribute>
+            </xsl:if>
+            <xsl:value-of select="@title" />
+          </a>
+          <span> <xsl:value-of select="@page" /> </span>
+        </div>
+      </xsl:if>
+`)
+	// the items of level n have n outline:item ancestors, the first one is the document
+	if cfg.DepthLimit > 0 {
+		fmt.Fprintf(&b, "      <xsl:if test=\"count(ancestor::outline:item) &lt; %d\">\n", cfg.DepthLimit)
+	}
+	b.WriteString(`      <ul>
+        <xsl:comment>added to prevent self-closing tags in QtXmlPatterns</xsl:comment>
+        <xsl:apply-templates select="outline:item"/>
+      </ul>
+`)
+	if cfg.DepthLimit > 0 {
+		b.WriteString("      </xsl:if>\n")
+	}
+	b.WriteString(`    </li>
+  </xsl:template>
+</xsl:stylesheet>
+`)
+	return b.Bytes()
+}
@@ -0,0 +1,86 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertWellFormedXML fails if data is not well-formed XML
+func assertWellFormedXML(t *testing.T, data []byte) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return
+		}
+		require.NoError(t, err)
+	}
+}
+
+func TestTOCConfigXSL(t *testing.T) {
+	xsl := string(TOCConfig{
+		Title:          "Contents & Figures",
+		DepthLimit:     2,
+		DottedLines:    true,
+		FontSize:       "11pt",
+		IndentPerLevel: "0.5cm",
+	}.xsl())
+	assertWellFormedXML(t, []byte(xsl))
+	assert.Contains(t, xsl, "<title>Contents &amp; Figures</title>")
+	assert.Contains(t, xsl, "<h1>Contents &amp; Figures</h1>")
+	assert.Contains(t, xsl, `<xsl:if test="count(ancestor::outline:item) &lt; 2">`)
+	assert.Contains(t, xsl, "div { border-bottom: 1px dotted #999; }")
+	assert.Contains(t, xsl, "ul { font-size: 11pt;")
+	assert.Contains(t, xsl, "ul ul { padding-left: 0.5cm; }")
+
+	// the defaults list all levels without lines
+	xsl = string(TOCConfig{}.xsl())
+	assertWellFormedXML(t, []byte(xsl))
+	assert.Contains(t, xsl, "<h1>Table of Contents</h1>")
+	assert.NotContains(t, xsl, "ancestor::outline:item")
+	assert.Contains(t, xsl, "div { border-bottom: none; }")
+	assert.Contains(t, xsl, "ul { font-size: 12pt;")
+	assert.Contains(t, xsl, "ul ul { padding-left: 5mm; }")
+}
+
+func TestConfigureTOC(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	require.NoError(t, pdfg.ConfigureTOC(TOCConfig{Title: "Overview", DepthLimit: 1}))
+	assert.True(t, pdfg.TOC.Include)
+
+	// the generated style sheet is used for the run and removed afterwards
+	cleanup, err := pdfg.prepare(context.Background())
+	require.NoError(t, err)
+	path := pdfg.TOC.XslStyleSheet.value
+	assert.Contains(t, pdfg.ArgString(), "toc --xsl-style-sheet "+path+" ")
+	xsl, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(xsl), "<h1>Overview</h1>")
+	cleanup()
+	assert.NoFileExists(t, path)
+	assert.Equal(t, "", pdfg.TOC.XslStyleSheet.value)
+
+	// the configuration is stored in JSON
+	b, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(b))
+	require.NoError(t, err)
+	assert.Equal(t, &TOCConfig{Title: "Overview", DepthLimit: 1}, restored.tocConfig)
+}
+
+func TestConfigureTOCInvalid(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.ConfigureTOC(TOCConfig{DepthLimit: -1}), "invalid TOC depth limit -1, use 0 for all levels")
+	assert.Error(t, pdfg.ConfigureTOC(TOCConfig{FontSize: "12"}))
+	assert.Error(t, pdfg.ConfigureTOC(TOCConfig{IndentPerLevel: "1em"}))
+	assert.Nil(t, pdfg.tocConfig)
+	assert.False(t, pdfg.TOC.Include)
+}
@@ -0,0 +1,103 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+)
+
+// blankPageOperators are the content stream operators which don't paint anything: graphics state, colors,
+// and path construction which is only used for clipping
+var blankPageOperators = map[string]bool{
+	"q": true, "Q": true, "cm": true, "w": true, "J": true, "j": true, "M": true, "d": true, "ri": true, "i": true,
+	"gs": true, "g": true, "G": true, "rg": true, "RG": true, "k": true, "K": true, "cs": true, "CS": true,
+	"sc": true, "SC": true, "scn": true, "SCN": true, "m": true, "l": true, "c": true, "v": true, "y": true,
+	"h": true, "re": true, "W": true, "W*": true, "n": true,
+}
+
+// SetTrimTrailingBlankPages removes blank pages from the end of the PDF, which wkhtmltopdf sometimes adds when
+// the content or its margins overflow the last page, see TrimTrailingBlankPages.
+func (pdfg *PDFGenerator) SetTrimTrailingBlankPages(trim bool) {
+	pdfg.trimBlankPages = trim
+}
+
+// TrimTrailingBlankPages returns a post-processor which removes blank pages at the very end of the PDF.
+// It is conservative: a page is blank if it has no annotations (like links) and its content streams are
+// missing, empty or only change the graphics state and clip, without painting, text or images. Blank pages
+// before the last page with content are kept, and the first page is never removed.
+func TrimTrailingBlankPages() PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		doc, err := parsePDF(pdf)
+		if err != nil {
+			return nil, err
+		}
+		pages, err := doc.pages()
+		if err != nil {
+			return nil, err
+		}
+		keep := len(pages)
+		for keep > 1 && doc.isBlankPage(pages[keep-1]) {
+			keep--
+		}
+		if keep == len(pages) {
+			return pdf, nil
+		}
+		if err := doc.setPages(pages[:keep]); err != nil {
+			return nil, err
+		}
+		return doc.bytes(), nil
+	}
+}
+
+// isBlankPage returns true if the page certainly does not show anything, see TrimTrailingBlankPages
+func (doc *pdfDocument) isBlankPage(ref pdfRef) bool {
+	page := doc.dict(ref)
+	if page == nil {
+		return false
+	}
+	if annots, ok := doc.resolve(page.Get("Annots")).(pdfArray); ok && len(annots) > 0 {
+		return false
+	}
+	var streams []pdfObject
+	switch contents := doc.resolve(page.Get("Contents")).(type) {
+	case nil:
+		return true
+	case *pdfStream:
+		streams = append(streams, contents)
+	case pdfArray:
+		streams = contents
+	default:
+		return false
+	}
+	for _, obj := range streams {
+		s, ok := doc.resolve(obj).(*pdfStream)
+		if !ok {
+			return false
+		}
+		data, err := doc.decodeStream(s)
+		if err != nil || !isBlankContent(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// isBlankContent returns true if a content stream contains only operators of blankPageOperators.
+// Content with strings, dictionaries or inline images is never blank.
+func isBlankContent(data []byte) bool {
+	if bytes.ContainsAny(data, "()<>{}%") {
+		return false
+	}
+	for _, token := range strings.Fields(strings.NewReplacer("[", " ", "]", " ").Replace(string(data))) {
+		if token[0] == '/' {
+			continue // a name, like the graphics state of gs
+		}
+		if _, err := strconv.ParseFloat(token, 64); err == nil {
+			continue
+		}
+		if !blankPageOperators[token] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,100 @@
+package wkhtmltopdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appendTestPages adds pages with the given content streams to the end of pdf, nil adds a page without contents
+func appendTestPages(t *testing.T, pdf []byte, contents ...[]byte) []byte {
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	for _, content := range contents {
+		ref := doc.newBlankPage(pages[len(pages)-1])
+		if content != nil {
+			doc.dict(ref).Set("Contents", doc.add(newFlateStream(nil, content)))
+		}
+		pages = append(pages, ref)
+	}
+	require.NoError(t, doc.setPages(pages))
+	return doc.bytes()
+}
+
+func TestIsBlankContent(t *testing.T) {
+	blank := []string{
+		"",
+		" \n ",
+		"q 1 0 0 -1 0 842 cm [] 0 d 0 J 0.5 w /GS0 gs 0 0 595 842 re W n Q",
+		"q 1 1 1 rg 0 0 0 RG Q",
+	}
+	for _, content := range blank {
+		assert.True(t, isBlankContent([]byte(content)), content)
+	}
+	notBlank := []string{
+		testPageContent(1),
+		"0 0 m 10 10 l S",
+		"1 1 1 rg 0 0 595 842 re f",
+		"q 100 0 0 100 0 0 cm /Im1 Do Q",
+		"BI /W 1 /H 1 ID x EI",
+		"q (n) Q",
+	}
+	for _, content := range notBlank {
+		assert.False(t, isBlankContent([]byte(content)), content)
+	}
+}
+
+func TestTrimTrailingBlankPages(t *testing.T) {
+	pdf := appendTestPages(t, newTestPDF(2), nil, []byte("q 0 0 595 842 re W n Q"), []byte(""))
+	require.Len(t, testPDFPageTexts(t, pdf), 5)
+	trimmed, err := TrimTrailingBlankPages()(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, []string{testPageContent(1), testPageContent(2)}, testPDFPageTexts(t, trimmed))
+
+	// blank pages before the last page with content are kept
+	pdf = appendTestPages(t, newTestPDF(1), nil, []byte(testPageContent(3)))
+	trimmed, err = TrimTrailingBlankPages()(pdf)
+	require.NoError(t, err)
+	assert.Equal(t, pdf, trimmed)
+
+	// a page with a link is not blank
+	pdf = appendTestPages(t, newTestPDF(1), nil)
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	link := newPDFDict()
+	link.Set("Type", pdfName("Annot"))
+	link.Set("Subtype", pdfName("Link"))
+	doc.dict(pages[1]).Set("Annots", pdfArray{doc.add(link)})
+	trimmed, err = TrimTrailingBlankPages()(doc.bytes())
+	require.NoError(t, err)
+	assert.Len(t, testPDFPageTexts(t, trimmed), 2)
+
+	// the first page is never removed
+	doc, err = parsePDF(appendTestPages(t, newTestPDF(1), nil))
+	require.NoError(t, err)
+	pages, err = doc.pages()
+	require.NoError(t, err)
+	doc.dict(pages[0]).Del("Contents")
+	trimmed, err = TrimTrailingBlankPages()(doc.bytes())
+	require.NoError(t, err)
+	assert.Len(t, testPDFPageTexts(t, trimmed), 1)
+}
+
+func TestSetTrimTrailingBlankPages(t *testing.T) {
+	pdfg, err := NewPDFGenerator()
+	require.NoError(t, err)
+	pdfg.SetTrimTrailingBlankPages(true)
+	pdfg.AddPage(NewPage("testdata/trailingblank.html"))
+	require.NoError(t, pdfg.Create())
+
+	doc, err := parsePDF(pdfg.Bytes())
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	assert.Len(t, pages, 1)
+}
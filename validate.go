@@ -0,0 +1,164 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Validate checks the configuration of the PDFGenerator without calling wkhtmltopdf. It returns an error for
+// duplicate global options and for local files referenced by the options (cover, pages, header and footer HTML,
+// style sheets) which do not exist, listing all missing files at once. URLs and "-" (stdin) are not checked. It
+// also returns an error for a zoom factor which is not greater than 0, for URL inputs in safe mode (see
+// SetSafeMode), for URL pages with a resource allowlist (see SetResourceAllowlist) or a Content Security Policy
+// (see SetCSP), for a page size without a style sheet when style sheets are set by page size (see
+// SetUserStyleSheetFor) and for PDFs added with AppendPDFAt which don't exist or are beyond the pages. Validate
+// doesn't write any files, whether OutputFile can be written is checked by Create (see ErrOutputNotWritable).
+// Validate is called by Create and CreateContext.
+func (pdfg *PDFGenerator) Validate() error {
+	err := pdfg.checkDuplicateFlags()
+	if err != nil {
+		return err
+	}
+	if err := pdfg.checkZoom(); err != nil {
+		return err
+	}
+	if err := pdfg.checkSafeMode(); err != nil {
+		return err
+	}
+	if err := pdfg.checkResourceAllowlist(); err != nil {
+		return err
+	}
+	if err := pdfg.checkCSP(); err != nil {
+		return err
+	}
+	if err := pdfg.checkSizeStyleSheets(); err != nil {
+		return err
+	}
+	if err := pdfg.checkAppendedPDFs(); err != nil {
+		return err
+	}
+	return pdfg.checkLocalFiles()
+}
+
+// checkZoom returns an error if the zoom of the cover, the TOC or a page is set to an invalid value
+func (pdfg *PDFGenerator) checkZoom() error {
+	check := func(location string, zoom floatOption) error {
+		if !zoom.isSet {
+			return nil
+		}
+		if err := validateZoom(zoom.value); err != nil {
+			return fmt.Errorf("%s: %w", location, err)
+		}
+		return nil
+	}
+	if pdfg.Cover.Input != "" {
+		if err := check("cover", pdfg.Cover.Zoom); err != nil {
+			return err
+		}
+	}
+	if pdfg.TOC.Include {
+		if err := check("toc", pdfg.TOC.Zoom); err != nil {
+			return err
+		}
+	}
+	for i, page := range pdfg.pages {
+		if err := check(fmt.Sprintf("page %d", i+1), page.Options().Zoom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileRef is a local file referenced by the configuration
+type fileRef struct {
+	location string // where the file is referenced, like "page 1 --header-html"
+	path     string
+}
+
+// fileRefs returns all files referenced by the configuration, which may be local files or URLs
+func (pdfg *PDFGenerator) fileRefs() []fileRef {
+	var refs []fileRef
+	add := func(location, path string) {
+		if path != "" {
+			refs = append(refs, fileRef{location: location, path: path})
+		}
+	}
+	addPageOptions := func(location string, po *pageOptions) {
+		add(location+" --user-style-sheet", po.UserStyleSheet.value)
+	}
+	addHeaderAndFooterOptions := func(location string, hfo *headerAndFooterOptions) {
+		add(location+" --header-html", hfo.HeaderHTML.value)
+		add(location+" --footer-html", hfo.FooterHTML.value)
+	}
+
+	for _, path := range pdfg.userStyleSheets {
+		add("user style sheets", path)
+	}
+	add("user style sheet for page size", pdfg.sizeStyleSheet())
+	if pdfg.headerLogo != nil {
+		add("header logo", pdfg.headerLogo.path)
+	}
+	add("cover", pdfg.Cover.Input)
+	addPageOptions("cover", &pdfg.Cover.pageOptions)
+	if pdfg.TOC.Include {
+		addPageOptions("toc", &pdfg.TOC.pageOptions)
+		addHeaderAndFooterOptions("toc", &pdfg.TOC.headerAndFooterOptions)
+		add("toc --xsl-style-sheet", pdfg.TOC.XslStyleSheet.value)
+	}
+	for i, page := range pdfg.pages {
+		location := fmt.Sprintf("page %d", i+1)
+		add(location, page.InputFile())
+		opts := page.Options()
+		addPageOptions(location, &opts.pageOptions)
+		addHeaderAndFooterOptions(location, &opts.headerAndFooterOptions)
+	}
+	return refs
+}
+
+// checkLocalFiles returns an error listing all referenced local files which do not exist
+func (pdfg *PDFGenerator) checkLocalFiles() error {
+	var missing []string
+	for _, ref := range pdfg.fileRefs() {
+		path, ok := localPath(ref.path)
+		if !ok {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			missing = append(missing, fmt.Sprintf("%s: %s", ref.location, ref.path))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("referenced local files do not exist:\n%s", strings.Join(missing, "\n"))
+	}
+	return nil
+}
+
+const driveLetters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// localPath returns the local file path for an input or option value and false if it is not a local file,
+// like a URL or "-" for stdin. file:// URLs are converted to a path, like file:///C:/docs/header.html to
+// C:\docs\header.html on Windows; file:// URLs of other hosts are not local.
+func localPath(input string) (string, bool) {
+	if input == "" || input == "-" {
+		return "", false
+	}
+	u, err := url.Parse(input)
+	if err != nil || len(u.Scheme) <= 1 { // a single letter scheme is a Windows drive letter
+		return input, true
+	}
+	if u.Scheme == "file" {
+		if u.Host != "" && u.Host != "localhost" {
+			return "", false
+		}
+		// the path of file:///C:/docs is /C:/docs
+		path := u.Path
+		if len(path) >= 3 && path[0] == '/' && path[2] == ':' && strings.ContainsRune(driveLetters, rune(path[1])) {
+			path = path[1:]
+		}
+		return filepath.FromSlash(path), true
+	}
+	return "", false
+}
@@ -0,0 +1,95 @@
+package wkhtmltopdf
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.SetFooterHTML("testdata/footer.html")
+	pdfg.SetUserStyleSheet("testdata/theme.css")
+	pdfg.AddPage(NewPage("https://www.google.com"))
+	html5, err := filepath.Abs("testdata/html5.html")
+	assert.NoError(t, err)
+	pdfg.AddPage(NewPage("file://" + filepath.ToSlash(html5)))
+	pdfg.AddPage(NewMarkdownPage("testdata/testmd.md"))
+	assert.NoError(t, pdfg.Validate())
+
+	pdfg.MarginRight.Set(1)
+	pdfg.MarginRightUnit.Set("1cm")
+	assert.EqualError(t, pdfg.Validate(), "duplicate argument: --margin-right")
+}
+
+func TestValidateZoom(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	page := NewPage("testdata/html5.html")
+	page.Zoom.Set(0)
+	pdfg.AddPage(page)
+	assert.EqualError(t, pdfg.Validate(), "page 2: invalid zoom 0, must be greater than 0")
+
+	page.Zoom.Set(0.8)
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.SetCoverZoom(-1)
+	assert.EqualError(t, pdfg.Validate(), "cover: invalid zoom -1, must be greater than 0")
+}
+
+func TestValidateMissingFiles(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCover("testdata/missing-cover.html")
+	pdfg.TOC.Include = true
+	pdfg.TOC.XslStyleSheet.Set("testdata/missing.xsl")
+
+	page := NewPage("testdata/missing.html")
+	page.HeaderHTML.Set("testdata/missing-header.html")
+	page.FooterHTML.Set("https://example.com/footer.html")
+	pdfg.AddPage(page)
+
+	pageReader := NewPageReader(nil)
+	pageReader.UserStyleSheet.Set("file:///missing/theme.css")
+	pdfg.AddPage(pageReader)
+
+	want := "referenced local files do not exist:\n" +
+		"cover: testdata/missing-cover.html\n" +
+		"toc --xsl-style-sheet: testdata/missing.xsl\n" +
+		"page 1: testdata/missing.html\n" +
+		"page 1 --header-html: testdata/missing-header.html\n" +
+		"page 2 --user-style-sheet: file:///missing/theme.css"
+	assert.EqualError(t, pdfg.Validate(), want)
+
+	// Create fails before wkhtmltopdf is called
+	SetPath("/path/to/wkhtmltopdf")
+	defer SetPath("")
+	pdfg.binPath = GetPath()
+	assert.EqualError(t, pdfg.Create(), want)
+}
+
+func TestLocalPath(t *testing.T) {
+	tests := []struct {
+		input string
+		path  string
+		local bool
+	}{
+		{"testdata/footer.html", "testdata/footer.html", true},
+		{"/abs/footer.html", "/abs/footer.html", true},
+		{`C:\footer.html`, `C:\footer.html`, true},
+		{"file:///abs/footer.html", filepath.FromSlash("/abs/footer.html"), true},
+		{"file://localhost/abs/footer.html", filepath.FromSlash("/abs/footer.html"), true},
+		{"file:///C:/docs/header.html", filepath.FromSlash("C:/docs/header.html"), true},
+		{"file:///c:/docs/my%20header.html", filepath.FromSlash("c:/docs/my header.html"), true},
+		{"file://server/share/footer.html", "", false},
+		{"https://example.com/footer.html", "", false},
+		{"data:text/html,<p>hi</p>", "", false},
+		{"-", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		path, local := localPath(tt.input)
+		assert.Equal(t, tt.path, path, tt.input)
+		assert.Equal(t, tt.local, local, tt.input)
+	}
+}
@@ -0,0 +1,134 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// ErrInvalidPDF is returned by ValidatePDF for a PDF which is structurally broken
+var ErrInvalidPDF = errors.New("invalid PDF")
+
+var (
+	startXrefRegex = regexp.MustCompile(`startxref[\x00\t\n\f\r ]+(\d+)`)
+	xrefEntryRegex = regexp.MustCompile(`^(\d{10}) (\d{5}) ([nf])`)
+	objStartRegex  = regexp.MustCompile(`^(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj`)
+)
+
+// ValidatePDF checks the basic structure of a PDF, like after post-processing: the %PDF header, the %%EOF marker,
+// the cross-reference table or stream pointed to by startxref (the objects in a table must be at the offsets
+// listed), the trailer, the catalog and the page tree, whose /Count must match the pages found and whose pages
+// need a MediaBox. It doesn't check the content of the pages, fonts and images, and for files with incremental
+// updates only the last cross-reference section. The errors wrap ErrInvalidPDF.
+// To check the result of each post-processing step, add a post-processor which only validates, like
+// pdfg.AddPostProcessor(func(pdf []byte) ([]byte, error) { return pdf, ValidatePDF(pdf) }).
+func ValidatePDF(data []byte) error {
+	invalid := func(format string, a ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidPDF, fmt.Sprintf(format, a...))
+	}
+	if !pdfHeaderRegex.Match(data) {
+		return invalid("no %%PDF header")
+	}
+	if !bytes.Contains(data[max(len(data)-1024, 0):], []byte("%%EOF")) {
+		return invalid("no %%%%EOF at the end, the file may be truncated")
+	}
+	matches := startXrefRegex.FindAllSubmatch(data, -1)
+	if matches == nil {
+		return invalid("no startxref")
+	}
+	xref, err := strconv.Atoi(string(matches[len(matches)-1][1]))
+	if err != nil || xref >= len(data) {
+		return invalid("startxref %s is beyond the end of the file", matches[len(matches)-1][1])
+	}
+	if err := checkXref(data, xref); err != nil {
+		return invalid("%v", err)
+	}
+
+	doc, err := parsePDF(data)
+	if err != nil {
+		return invalid("%v", err)
+	}
+	cat, err := doc.catalog()
+	if err != nil {
+		return invalid("%v", err)
+	}
+	if t := cat.Get("Type"); t != pdfName("Catalog") {
+		return invalid("the catalog has type %v instead of /Catalog", t)
+	}
+	pages, err := doc.pages()
+	if err != nil {
+		return invalid("%v", err)
+	}
+	count := doc.resolve(doc.dict(cat.Get("Pages")).Get("Count"))
+	if n, ok := doc.intValue(count); !ok || n != len(pages) {
+		return invalid("the page tree has /Count %v, but %d pages", count, len(pages))
+	}
+	for i, ref := range pages {
+		if _, ok := doc.resolve(doc.inheritedValue(doc.dict(ref), "MediaBox")).(pdfArray); !ok {
+			return invalid("page %d has no MediaBox", i+1)
+		}
+	}
+	return nil
+}
+
+// checkXref checks the cross-reference section at offset, which is a table whose objects must be at the offsets
+// listed, or a cross-reference stream
+func checkXref(data []byte, offset int) error {
+	p := &pdfParser{data: data, pos: offset}
+	token := func() string {
+		p.skip()
+		k := p.keyword()
+		p.pos += len(k)
+		return k
+	}
+	if token() != "xref" {
+		p = &pdfParser{data: data, pos: offset}
+		p.skip()
+		m := objStartRegex.FindSubmatchIndex(data[p.pos:])
+		if m == nil {
+			return fmt.Errorf("startxref %d doesn't point to a cross-reference table or stream", offset)
+		}
+		p.pos += m[1]
+		obj, err := p.parseIndirect()
+		if err != nil {
+			return fmt.Errorf("error reading cross-reference stream: %w", err)
+		}
+		if s, ok := obj.(*pdfStream); !ok || s.dict.Get("Type") != pdfName("XRef") {
+			return fmt.Errorf("startxref %d doesn't point to a cross-reference table or stream", offset)
+		}
+		return nil
+	}
+
+	for {
+		p.skip()
+		if bytes.HasPrefix(data[p.pos:], []byte("trailer")) {
+			return nil
+		}
+		first, errFirst := strconv.Atoi(token())
+		count, errCount := strconv.Atoi(token())
+		if errFirst != nil || errCount != nil || first < 0 || count < 0 {
+			return errors.New("cross-reference table is damaged or has no trailer")
+		}
+		for num := first; num < first+count; num++ {
+			p.skip()
+			m := xrefEntryRegex.FindSubmatch(data[p.pos:])
+			if m == nil {
+				return fmt.Errorf("cross-reference entry of object %d is damaged", num)
+			}
+			p.pos += len(m[0])
+			if string(m[3]) == "f" {
+				continue
+			}
+			off, _ := strconv.Atoi(string(m[1]))
+			if off >= len(data) {
+				return fmt.Errorf("object %d is listed at offset %d, beyond the end of the file", num, off)
+			}
+			obj := objStartRegex.FindSubmatch(data[off:])
+			if obj == nil || string(obj[1]) != strconv.Itoa(num) {
+				return fmt.Errorf("object %d is not at offset %d listed in the cross-reference table", num, off)
+			}
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePDF(t *testing.T) {
+	assert.NoError(t, ValidatePDF(newTestPDF(3)))
+
+	// the output of the built-in post-processing is valid, including PDFs with a cross-reference stream
+	pdf, err := PadToOddPages(2)(newTestPDF(3))
+	require.NoError(t, err)
+	assert.NoError(t, ValidatePDF(pdf))
+	xrefStream := []byte("%PDF-1.5\n1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n" +
+		"2 0 obj\n<< /Type /Pages /Kids [3 0 R] /Count 1 >>\nendobj\n" +
+		"3 0 obj\n<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] >>\nendobj\n" +
+		"4 0 obj\n<< /Type /XRef /Size 5 /Root 1 0 R /W [1 2 1] /Length 0 >>\nstream\n\nendstream\nendobj\n" +
+		"startxref\nXREF\n%%EOF\n")
+	xrefStream = bytes.Replace(xrefStream, []byte("XREF"), []byte(strconv.Itoa(bytes.Index(xrefStream, []byte("4 0 obj")))), 1)
+	assert.NoError(t, ValidatePDF(xrefStream))
+}
+
+func TestValidatePDFCorrupted(t *testing.T) {
+	good := newTestPDF(2)
+	tests := map[string]struct {
+		pdf  []byte
+		want string
+	}{
+		"no header": {append([]byte("garbage"), good...), "no %PDF header"},
+		"truncated": {good[:len(good)-200], "no %%EOF at the end, the file may be truncated"},
+		"no xref":   {bytes.Replace(good, []byte("startxref"), []byte("startxxxx"), 1), "no startxref"},
+		"count": {bytes.Replace(good, []byte("/Count 2"), []byte("/Count 3"), 1),
+			"the page tree has /Count 3, but 2 pages"},
+		"no catalog": {bytes.Replace(good, []byte("/Type /Catalog"), []byte("/Type /Katalog"), 1),
+			"the catalog has type Katalog instead of /Catalog"},
+		"media box": {bytes.Replace(good, []byte("/MediaBox [0 0 595 842]"), []byte("/MediaBox null         "), 1),
+			"page 1 has no MediaBox"},
+	}
+	for name, tt := range tests {
+		err := ValidatePDF(tt.pdf)
+		require.Error(t, err, name)
+		assert.True(t, errors.Is(err, ErrInvalidPDF), name)
+		assert.Contains(t, err.Error(), tt.want, name)
+	}
+
+	// a stray byte moves all objects after it
+	shifted := bytes.Replace(good, []byte("endobj\n2 0 obj"), []byte("endobj\n\n2 0 obj"), 1)
+	assert.ErrorContains(t, ValidatePDF(shifted), "object 2 is not at offset")
+}
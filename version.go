@@ -0,0 +1,110 @@
+package wkhtmltopdf
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Version is the version of the wkhtmltopdf executable, as returned by DetectVersion
+type Version struct {
+	Major, Minor, Patch int
+	PatchedQt           bool   // Built with the patched Qt, which is needed for most features, see Capabilities
+	Raw                 string // The complete output of wkhtmltopdf --version
+}
+
+// String returns the version like "0.12.6 (with patched qt)"
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PatchedQt {
+		s += " (with patched qt)"
+	}
+	return s
+}
+
+// AtLeast returns true if the version is major.minor.patch or newer
+func (v Version) AtLeast(major, minor, patch int) bool {
+	if v.Major != major {
+		return v.Major > major
+	}
+	if v.Minor != minor {
+		return v.Minor > minor
+	}
+	return v.Patch >= patch
+}
+
+var versionRegex = regexp.MustCompile(`wkhtmltopdf\s+(\d+)\.(\d+)\.(\d+)`)
+
+// parseVersion parses the output of wkhtmltopdf --version
+func parseVersion(output string) (Version, error) {
+	m := versionRegex.FindStringSubmatch(output)
+	if m == nil {
+		return Version{}, fmt.Errorf("unknown wkhtmltopdf version output: %q", strings.TrimSpace(output))
+	}
+	v := Version{
+		PatchedQt: strings.Contains(strings.ToLower(output), "patched qt"),
+		Raw:       strings.TrimSpace(output),
+	}
+	v.Major, _ = strconv.Atoi(m[1])
+	v.Minor, _ = strconv.Atoi(m[2])
+	v.Patch, _ = strconv.Atoi(m[3])
+	return v, nil
+}
+
+// versionCache caches the detected version for each wkhtmltopdf path
+var versionCache sync.Map
+
+// DetectVersion runs wkhtmltopdf --version and returns the parsed version.
+// The result is cached for each path of the wkhtmltopdf executable, so it only runs once per binary.
+func (pdfg *PDFGenerator) DetectVersion() (Version, error) {
+	if pdfg.binPath == "" {
+		return Version{}, errors.New("wkhtmltopdf path not set, use NewPDFGenerator or SetPath")
+	}
+	if v, ok := versionCache.Load(pdfg.binPath); ok {
+		return v.(Version), nil
+	}
+
+	cmd := exec.Command(pdfg.binPath, "--version")
+	cmdConfig(cmd)
+	out, err := cmd.Output()
+	if err != nil {
+		return Version{}, fmt.Errorf("error running wkhtmltopdf --version: %w", err)
+	}
+	v, err := parseVersion(string(out))
+	if err != nil {
+		return Version{}, err
+	}
+	versionCache.Store(pdfg.binPath, v)
+	return v, nil
+}
+
+// Capabilities are the features supported by the wkhtmltopdf executable, as returned by Capabilities
+type Capabilities struct {
+	SupportsHeaderFooter  bool // Text and HTML headers and footers
+	SupportsTOC           bool // Table of contents
+	SupportsOutline       bool // PDF outline (bookmarks)
+	SupportsCover         bool // Cover page
+	SupportsMultiplePages bool // More than one input page in one PDF
+}
+
+// Capabilities returns the features supported by the wkhtmltopdf executable, based on DetectVersion.
+// wkhtmltopdf built without the patched Qt has reduced functionality: it supports a single input page only,
+// without headers and footers, TOC, outline or cover. Use this to leave out these features instead of getting
+// errors from wkhtmltopdf.
+func (pdfg *PDFGenerator) Capabilities() (Capabilities, error) {
+	v, err := pdfg.DetectVersion()
+	if err != nil {
+		return Capabilities{}, err
+	}
+	return Capabilities{
+		SupportsHeaderFooter:  v.PatchedQt,
+		SupportsTOC:           v.PatchedQt,
+		SupportsOutline:       v.PatchedQt,
+		SupportsCover:         v.PatchedQt,
+		SupportsMultiplePages: v.PatchedQt,
+	}, nil
+}
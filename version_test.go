@@ -0,0 +1,72 @@
+package wkhtmltopdf
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVersionGenerator returns a PDFGenerator with a fake wkhtmltopdf which prints output for --version
+func newVersionGenerator(t *testing.T, output string) *PDFGenerator {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake wkhtmltopdf is a shell script")
+	}
+	path := filepath.Join(t.TempDir(), "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho '"+output+"'\n"), 0755))
+	pdfg := NewPDFPreparer()
+	pdfg.binPath = path
+	return pdfg
+}
+
+func TestParseVersion(t *testing.T) {
+	v, err := parseVersion("wkhtmltopdf 0.12.6.1 (with patched qt)\n")
+	require.NoError(t, err)
+	assert.Equal(t, Version{Major: 0, Minor: 12, Patch: 6, PatchedQt: true, Raw: "wkhtmltopdf 0.12.6.1 (with patched qt)"}, v)
+	assert.Equal(t, "0.12.6 (with patched qt)", v.String())
+	assert.True(t, v.AtLeast(0, 12, 5))
+	assert.True(t, v.AtLeast(0, 12, 6))
+	assert.False(t, v.AtLeast(0, 13, 0))
+
+	v, err = parseVersion("wkhtmltopdf 0.12.5")
+	require.NoError(t, err)
+	assert.False(t, v.PatchedQt)
+
+	_, err = parseVersion("command not found")
+	assert.Error(t, err)
+}
+
+func TestCapabilities(t *testing.T) {
+	pdfg := newVersionGenerator(t, "wkhtmltopdf 0.12.6 (with patched qt)")
+	c, err := pdfg.Capabilities()
+	require.NoError(t, err)
+	assert.Equal(t, Capabilities{true, true, true, true, true}, c)
+
+	pdfg = newVersionGenerator(t, "wkhtmltopdf 0.12.4")
+	c, err = pdfg.Capabilities()
+	require.NoError(t, err)
+	assert.Equal(t, Capabilities{}, c)
+
+	pdfg = newVersionGenerator(t, "something else")
+	_, err = pdfg.Capabilities()
+	assert.Error(t, err)
+
+	_, err = NewPDFPreparer().Capabilities()
+	assert.Error(t, err)
+}
+
+func TestDetectVersionCache(t *testing.T) {
+	pdfg := newVersionGenerator(t, "wkhtmltopdf 0.12.5 (with patched qt)")
+	v, err := pdfg.DetectVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 5, v.Patch)
+
+	// the version is cached for the path, so a changed binary is not run again
+	require.NoError(t, os.WriteFile(pdfg.binPath, []byte("#!/bin/sh\necho 'wkhtmltopdf 0.12.6'\n"), 0755))
+	v, err = pdfg.DetectVersion()
+	require.NoError(t, err)
+	assert.Equal(t, 5, v.Patch)
+}
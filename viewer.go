@@ -0,0 +1,138 @@
+package wkhtmltopdf
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PageLayout is how a PDF viewer arranges the pages, see ViewerPreferences
+type PageLayout string
+
+const (
+	PageLayoutDefault        PageLayout = ""               // Chosen by the viewer
+	PageLayoutSinglePage     PageLayout = "SinglePage"     // One page at a time
+	PageLayoutOneColumn      PageLayout = "OneColumn"      // Pages in one column
+	PageLayoutTwoColumnLeft  PageLayout = "TwoColumnLeft"  // Pages in two columns, odd pages on the left
+	PageLayoutTwoColumnRight PageLayout = "TwoColumnRight" // Pages in two columns, odd pages on the right
+	PageLayoutTwoPageLeft    PageLayout = "TwoPageLeft"    // Two pages at a time, odd pages on the left
+	PageLayoutTwoPageRight   PageLayout = "TwoPageRight"   // Two pages at a time, odd pages on the right
+)
+
+// PageMode is the panel a PDF viewer shows next to the document when it is opened, see ViewerPreferences
+type PageMode string
+
+const (
+	PageModeDefault        PageMode = ""               // Chosen by the viewer
+	PageModeUseNone        PageMode = "UseNone"        // No panel
+	PageModeUseOutlines    PageMode = "UseOutlines"    // The bookmarks (outline)
+	PageModeUseThumbs      PageMode = "UseThumbs"      // Page thumbnails
+	PageModeFullScreen     PageMode = "FullScreen"     // Full screen, without menu and panels
+	PageModeUseAttachments PageMode = "UseAttachments" // The attachments
+)
+
+// ViewerZoom is the zoom of the first page when a PDF is opened, see ViewerPreferences
+type ViewerZoom string
+
+const (
+	ZoomDefault    ViewerZoom = ""     // Chosen by the viewer
+	ZoomFitPage    ViewerZoom = "Fit"  // The whole page fits in the window
+	ZoomFitWidth   ViewerZoom = "FitH" // The width of the page fits in the window
+	ZoomFitHeight  ViewerZoom = "FitV" // The height of the page fits in the window
+	ZoomFitVisible ViewerZoom = "FitB" // The content of the page, without the margins, fits in the window
+)
+
+// ZoomPercent returns a ViewerZoom which opens the document at a fixed zoom, like 150 for 150%
+func ZoomPercent(percent float64) ViewerZoom {
+	return ViewerZoom(strconv.FormatFloat(percent, 'f', -1, 64) + "%")
+}
+
+var zoomPercentRegex = regexp.MustCompile(`^(\d+(?:\.\d+)?)%$`)
+
+// ViewerPreferences are the preferences for how a PDF viewer shows the document when it is opened.
+// Empty values leave the choice to the viewer.
+type ViewerPreferences struct {
+	PageLayout PageLayout
+	PageMode   PageMode   // Like PageModeUseOutlines to show the bookmarks
+	Zoom       ViewerZoom // Like ZoomFitWidth or ZoomPercent(150)
+}
+
+// SetViewerPreferences sets how a PDF viewer like Acrobat shows the document when it is opened, like opening it
+// at the page width with the bookmarks panel. wkhtmltopdf doesn't write these settings, so /PageLayout, /PageMode
+// and an /OpenAction which shows the first page at the zoom are added to the catalog by post-processing the PDF.
+// An error is returned for unknown values. Setting empty ViewerPreferences removes the preferences.
+func (pdfg *PDFGenerator) SetViewerPreferences(vp ViewerPreferences) error {
+	switch vp.PageLayout {
+	case PageLayoutDefault, PageLayoutSinglePage, PageLayoutOneColumn, PageLayoutTwoColumnLeft,
+		PageLayoutTwoColumnRight, PageLayoutTwoPageLeft, PageLayoutTwoPageRight:
+	default:
+		return fmt.Errorf("unknown page layout %q", vp.PageLayout)
+	}
+	switch vp.PageMode {
+	case PageModeDefault, PageModeUseNone, PageModeUseOutlines, PageModeUseThumbs, PageModeFullScreen, PageModeUseAttachments:
+	default:
+		return fmt.Errorf("unknown page mode %q", vp.PageMode)
+	}
+	if _, err := vp.Zoom.destination(); err != nil {
+		return err
+	}
+	if vp == (ViewerPreferences{}) {
+		pdfg.viewerPrefs = nil
+		return nil
+	}
+	pdfg.viewerPrefs = &vp
+	return nil
+}
+
+// destination returns the parameters of an explicit destination after the page for the zoom, like [/FitH null]
+func (z ViewerZoom) destination() (pdfArray, error) {
+	switch z {
+	case ZoomDefault:
+		return nil, nil
+	case ZoomFitPage, ZoomFitVisible:
+		return pdfArray{pdfName(z)}, nil
+	case ZoomFitWidth, ZoomFitHeight:
+		return pdfArray{pdfName(z), pdfNull{}}, nil
+	}
+	m := zoomPercentRegex.FindStringSubmatch(strings.TrimSpace(string(z)))
+	if m == nil {
+		return nil, fmt.Errorf("unknown zoom %q, use a Zoom constant or ZoomPercent", z)
+	}
+	percent, err := strconv.ParseFloat(m[1], 64)
+	if err != nil || percent <= 0 {
+		return nil, fmt.Errorf("invalid zoom %q, the percentage must be greater than 0", z)
+	}
+	return pdfArray{pdfName("XYZ"), pdfNull{}, pdfNull{}, pdfFloat(percent / 100)}, nil
+}
+
+// setViewerPreferences returns a post-processor which writes vp to the catalog
+func setViewerPreferences(vp ViewerPreferences) PostProcessor {
+	return func(pdf []byte) ([]byte, error) {
+		return modifyPDF(pdf, func(doc *pdfDocument) error {
+			cat, err := doc.catalog()
+			if err != nil {
+				return err
+			}
+			if vp.PageLayout != PageLayoutDefault {
+				cat.Set("PageLayout", pdfName(vp.PageLayout))
+			}
+			if vp.PageMode != PageModeDefault {
+				cat.Set("PageMode", pdfName(vp.PageMode))
+			}
+			dest, err := vp.Zoom.destination()
+			if err != nil || dest == nil {
+				return err
+			}
+			pages, err := doc.pages()
+			if err != nil {
+				return err
+			}
+			if len(pages) == 0 {
+				return nil
+			}
+			cat.Set("OpenAction", append(pdfArray{pages[0]}, dest...))
+			return nil
+		})
+	}
+}
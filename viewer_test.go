@@ -0,0 +1,84 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetViewerPreferences(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	assert.EqualError(t, pdfg.SetViewerPreferences(ViewerPreferences{PageLayout: "Spread"}), `unknown page layout "Spread"`)
+	assert.EqualError(t, pdfg.SetViewerPreferences(ViewerPreferences{PageMode: "UseBookmarks"}), `unknown page mode "UseBookmarks"`)
+	assert.EqualError(t, pdfg.SetViewerPreferences(ViewerPreferences{Zoom: "fit page"}),
+		`unknown zoom "fit page", use a Zoom constant or ZoomPercent`)
+	assert.EqualError(t, pdfg.SetViewerPreferences(ViewerPreferences{Zoom: ZoomPercent(0)}),
+		`invalid zoom "0%", the percentage must be greater than 0`)
+	assert.Empty(t, pdfg.postProcessors())
+
+	require.NoError(t, pdfg.SetViewerPreferences(ViewerPreferences{
+		PageLayout: PageLayoutOneColumn,
+		PageMode:   PageModeUseOutlines,
+		Zoom:       ZoomFitWidth,
+	}))
+	processors := pdfg.postProcessors()
+	require.Len(t, processors, 1)
+
+	pdf, err := processors[0](newTestPDF(2))
+	require.NoError(t, err)
+	doc, err := parsePDF(pdf)
+	require.NoError(t, err)
+	cat, err := doc.catalog()
+	require.NoError(t, err)
+	pages, err := doc.pages()
+	require.NoError(t, err)
+	assert.Equal(t, pdfName("OneColumn"), cat.Get("PageLayout"))
+	assert.Equal(t, pdfName("UseOutlines"), cat.Get("PageMode"))
+	assert.Equal(t, pdfArray{pages[0], pdfName("FitH"), pdfNull{}}, cat.Get("OpenAction"))
+
+	// empty preferences remove them
+	require.NoError(t, pdfg.SetViewerPreferences(ViewerPreferences{}))
+	assert.Empty(t, pdfg.postProcessors())
+}
+
+func TestViewerZoomDestination(t *testing.T) {
+	tests := []struct {
+		zoom ViewerZoom
+		want pdfArray
+	}{
+		{ZoomDefault, nil},
+		{ZoomFitPage, pdfArray{pdfName("Fit")}},
+		{ZoomFitVisible, pdfArray{pdfName("FitB")}},
+		{ZoomFitHeight, pdfArray{pdfName("FitV"), pdfNull{}}},
+		{ZoomPercent(150), pdfArray{pdfName("XYZ"), pdfNull{}, pdfNull{}, pdfNumber("1.5")}},
+		{"80%", pdfArray{pdfName("XYZ"), pdfNull{}, pdfNull{}, pdfNumber("0.8")}},
+	}
+	for _, tt := range tests {
+		dest, err := tt.zoom.destination()
+		require.NoError(t, err, tt.zoom)
+		assert.Equal(t, tt.want, dest, tt.zoom)
+	}
+}
+
+func TestViewerPreferencesOnlyZoom(t *testing.T) {
+	pdf, err := setViewerPreferences(ViewerPreferences{Zoom: ZoomFitPage})(newTestPDF(1))
+	require.NoError(t, err)
+	assert.Contains(t, string(pdf), "/OpenAction [")
+	assert.False(t, bytes.Contains(pdf, []byte("/PageMode")))
+	assert.False(t, bytes.Contains(pdf, []byte("/PageLayout")))
+}
+
+func TestViewerPreferencesJSON(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	vp := ViewerPreferences{PageMode: PageModeUseThumbs, Zoom: ZoomPercent(125)}
+	require.NoError(t, pdfg.SetViewerPreferences(vp))
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	require.NotNil(t, restored.viewerPrefs)
+	assert.Equal(t, vp, *restored.viewerPrefs)
+}
@@ -0,0 +1,63 @@
+package wkhtmltopdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// waitForStatus is the window.status set by the script of WaitForSelector
+const waitForStatus = "gopdf-wait-for-ready"
+
+// waitForScriptMarker starts the script of WaitForSelector, so it can be replaced by a later WaitFor
+const waitForScriptMarker = "/*gopdf-wait-for*/"
+
+// WaitCondition is a condition wkhtmltopdf waits for before rendering a page, see PageOptions.WaitFor
+type WaitCondition interface {
+	apply(po *PageOptions)
+}
+
+type waitForSelector string
+
+// WaitForSelector returns a condition which waits until an element matching the CSS selector is in the page,
+// like content which is rendered by JavaScript after the page has loaded.
+// This is done with a small script (--run-script) which polls for the element and then sets window.status,
+// combined with WindowStatus. JavaScript must be enabled for the page. If the element never appears,
+// wkhtmltopdf waits forever, so use CreateContext with a timeout.
+func WaitForSelector(selector string) WaitCondition {
+	return waitForSelector(selector)
+}
+
+func (s waitForSelector) apply(po *PageOptions) {
+	selector, _ := json.Marshal(string(s)) // a JSON string is a valid JavaScript string
+	script := fmt.Sprintf(`%s(function(){function check(){if(document.querySelector(%s)){window.status=%q;}else{setTimeout(check,50);}}check();})();`,
+		waitForScriptMarker, selector, waitForStatus)
+	po.RunScript.value = slices.DeleteFunc(po.RunScript.value, func(existing string) bool {
+		return strings.HasPrefix(existing, waitForScriptMarker)
+	})
+	po.RunScript.Set(script)
+	po.WindowStatus.Set(waitForStatus)
+}
+
+type waitForTimeout time.Duration
+
+// WaitForTimeout returns a condition which waits for a fixed time after the page has loaded, to give scripts time
+// to finish. It sets JavascriptDelay, so the time is rounded down to milliseconds.
+func WaitForTimeout(d time.Duration) WaitCondition {
+	return waitForTimeout(d)
+}
+
+func (d waitForTimeout) apply(po *PageOptions) {
+	po.JavascriptDelay.Set(uint(time.Duration(d).Milliseconds()))
+}
+
+// WaitFor makes wkhtmltopdf wait for the conditions before the page is rendered, see WaitForSelector and
+// WaitForTimeout. A selector condition replaces one set by an earlier call. When both are used, wkhtmltopdf
+// first waits for the timeout and then for the selector.
+func (po *PageOptions) WaitFor(conditions ...WaitCondition) {
+	for _, cond := range conditions {
+		cond.apply(po)
+	}
+}
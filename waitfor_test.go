@@ -0,0 +1,34 @@
+package wkhtmltopdf
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWaitFor(t *testing.T) {
+	page := NewPage("testdata/htmlsimple.html")
+	page.RunScript.Set("console.log('user script')")
+	page.WaitFor(WaitForSelector(`#chart[data-ready="yes"]`), WaitForTimeout(1500*time.Millisecond))
+
+	assert.Equal(t, waitForStatus, page.WindowStatus.value)
+	assert.Equal(t, uint(1500), page.JavascriptDelay.value)
+	require.Len(t, page.RunScript.value, 2)
+	script := page.RunScript.value[1]
+	assert.True(t, strings.HasPrefix(script, waitForScriptMarker))
+	assert.Contains(t, script, `document.querySelector("#chart[data-ready=\"yes\"]")`)
+	assert.Contains(t, script, `window.status="gopdf-wait-for-ready"`)
+
+	// a new selector replaces the previous one, other scripts are kept
+	page.WaitFor(WaitForSelector(".done"))
+	require.Len(t, page.RunScript.value, 2)
+	assert.Equal(t, "console.log('user script')", page.RunScript.value[0])
+	assert.Contains(t, page.RunScript.value[1], `document.querySelector(".done")`)
+
+	args := strings.Join(page.Args(), " ")
+	assert.Contains(t, args, "--window-status gopdf-wait-for-ready")
+	assert.Contains(t, args, "--javascript-delay 1500")
+}
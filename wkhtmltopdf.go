@@ -12,14 +12,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	gohtml "html"
 	"io"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gomarkdown/markdown"
+	"github.com/gomarkdown/markdown/ast"
 	"github.com/gomarkdown/markdown/html"
 	"github.com/gomarkdown/markdown/parser"
 )
@@ -54,6 +59,55 @@ func GetPath() string {
 	return binPath.Get()
 }
 
+// SearchLocation is a location where wkhtmltopdf is searched for, see SetSearchOrder
+type SearchLocation int
+
+// The locations where wkhtmltopdf is searched for
+const (
+	SearchPreferredDir SearchLocation = iota // The dir set with SetPreferredBinDir
+	SearchExeDir                             // The dir of the current executable
+	SearchPATH                               // The dirs in the PATH environment variable
+	SearchEnvDir                             // The dir in the WKHTMLTOPDF_PATH environment variable
+)
+
+var defaultSearchOrder = []SearchLocation{SearchPreferredDir, SearchExeDir, SearchPATH, SearchEnvDir}
+
+var (
+	preferredBinDir stringStore
+	searchOrder     struct {
+		locations []SearchLocation
+		sync.Mutex
+	}
+)
+
+// SetPreferredBinDir sets a dir which is searched for wkhtmltopdf before any other location, like a specific
+// build bundled with the application. On Windows, wkhtmltopdf.exe is looked for in the dir regardless of PATHEXT.
+// It resets the cached path, so the next NewPDFGenerator searches again.
+func SetPreferredBinDir(dir string) {
+	preferredBinDir.Set(dir)
+	binPath.Set("")
+}
+
+// SetSearchOrder sets the locations which are searched for wkhtmltopdf and their order. Locations which are
+// not included are not searched. Without locations, the default order is restored: SearchPreferredDir,
+// SearchExeDir, SearchPATH, SearchEnvDir.
+// It resets the cached path, so the next NewPDFGenerator searches again.
+func SetSearchOrder(locations ...SearchLocation) {
+	searchOrder.Lock()
+	searchOrder.locations = append([]SearchLocation{}, locations...)
+	searchOrder.Unlock()
+	binPath.Set("")
+}
+
+func getSearchOrder() []SearchLocation {
+	searchOrder.Lock()
+	defer searchOrder.Unlock()
+	if len(searchOrder.locations) == 0 {
+		return defaultSearchOrder
+	}
+	return searchOrder.locations
+}
+
 // Page is the input struct for each page
 type Page struct {
 	Input string
@@ -134,8 +188,79 @@ type MarkdownPage struct {
 	// before converting to HTML. This is useful if the H1/H2 are used for a
 	// separate cover page.
 	SkipFirstH1H2 bool
+	// Lang, if set, is the language of the content (like "en-US"), set as lang attribute on the <html> element.
+	Lang string
+	// HeadExtras is raw HTML inserted at the end of the <head> of the generated document, like meta tags or a
+	// <link> to a style sheet. It is not escaped or checked, so it must be trusted, valid HTML for the <head>.
+	HeadExtras string
+	// NoWrap, if true, outputs only the HTML converted from the Markdown, without wrapping it in an
+	// <html><head>...</head><body> document. Use this when the Markdown contains its own HTML document shell
+	// as raw HTML: if the file starts with <!DOCTYPE> or <html> and ends with </body></html>, everything up to
+	// and including <body> and from </body> is passed through unchanged and only the content in between is
+	// converted. Options which change the wrapper, like Lang and HeadExtras, have no effect when NoWrap is set.
+	NoWrap bool
+	// Flavor selects the Markdown syntax, FlavorGFM (the default) or FlavorCommonMark.
+	Flavor MarkdownFlavor
+	// ASTTransformers are called in order with the parsed document (an *ast.Document of
+	// github.com/gomarkdown/markdown/ast) before it is rendered to HTML. They can change the tree in place, like
+	// replacing *ast.Text nodes with *ast.Link nodes or inserting *ast.HTMLSpan and *ast.HTMLBlock nodes with raw
+	// HTML. Use ast.WalkFunc to visit all nodes.
+	ASTTransformers []func(doc ast.Node)
+	// FailOnEmpty, if true, makes Reader fail with an error wrapping ErrEmptyMarkdown, so Create fails, when the
+	// Markdown has no content, like an empty file or a file with only whitespace, HTML comments or a YAML front
+	// matter block (between two "---" lines at the start). By default an empty file gives a blank page.
+	FailOnEmpty bool
+	// NoTargetBlank, if true, generates links without target="_blank". By default links to other documents get
+	// target="_blank", which PDF viewers ignore. Links to anchors in the document (#heading) and relative links
+	// starting with / or ./ never get it, so they keep working in PDF viewers.
+	NoTargetBlank bool
+	// LinkRewriter, if set, is called with the destination of each Markdown link (including autolinks and links
+	// added by ASTTransformers) and returns the destination to use, like "#installation" for "install.md" when
+	// several Markdown files are combined into one PDF, or an absolute URL of the published site. Return href
+	// unchanged to keep a link, such as external URLs and anchors. Images and links in raw HTML are not passed to it.
+	LinkRewriter func(href string) string
+	// StripComments, if true, removes HTML comments, like <!-- TODO -->, from the raw HTML in the Markdown, so they
+	// are not part of the generated HTML. Comments in code blocks and code spans are text of the code and are kept.
+	StripComments bool
+	// KeepComments are the comments StripComments keeps, by their text without <!-- and --> and surrounding
+	// whitespace, like "pagebreak" for <!-- pagebreak -->.
+	KeepComments []string
+	// Title is the <title> of the generated document, which wkhtmltopdf uses for [doctitle] in headers and footers
+	// and as title of the PDF (unless the Title option is set). If empty, it is taken from the Markdown as
+	// selected by TitleSource.
+	Title string
+	// TitleSource selects where the title is taken from when Title is empty: TitleAuto (the default) uses the
+	// title of the front matter, or else the text of the first H1 heading. The heading is found in the Markdown
+	// before SkipFirstH1H2 removes it, so a heading moved to the cover is still the title.
+	TitleSource MarkdownTitleSource
+	// ExpandIncludes, if true, replaces a line "!include path.md" or "{{include: path.md}}" (outside fenced code
+	// blocks) with the content of that file, relative to the directory of InputPath. Included files can include
+	// other files, up to 16 levels. Only files in the directory of InputPath and its subdirectories can be
+	// included, Reader fails for other paths. Includes are not expanded for Markdown without an InputPath (like
+	// from NewMarkdownTemplatePage) and in safe mode (see SetSafeMode) or with DisableLocalFileAccess, the lines
+	// are then converted as text.
+	ExpandIncludes bool
+	// ParseFrontmatter, if true, removes a YAML front matter block (from a "---" line at the start of the file to
+	// the next "---" or "..." line) from the Markdown before it is converted, so it is not rendered as text, and
+	// stores its values in Frontmatter. Reader fails with an error wrapping ErrInvalidFrontmatter, so Create fails,
+	// if the block is not closed or not a YAML mapping; the Markdown is then not converted. Markdown without front
+	// matter is converted unchanged. The title of the front matter is still used by TitleSource.
+	ParseFrontmatter bool
+	// Frontmatter holds the values of the front matter, like "title", "author" and "keywords", as decoded by
+	// gopkg.in/yaml.v3, after Reader was called with ParseFrontmatter set, for example to set the Title option of
+	// the PDFGenerator or values for SetReplace. It is nil if the Markdown has no front matter.
+	Frontmatter map[string]any
+	// Chapter, if true, makes the document a chapter of the PDF outline when several Markdown pages are combined:
+	// a bookmark for the document is added at the top level and the bookmarks of its headings are nested below it,
+	// also when the document doesn't start with an H1 heading. The bookmark is labeled with Title, or else the
+	// title of the front matter, the file name without extension or the first H1 heading. It is made by a hidden
+	// H1 heading at the start of the document, so the headings of the document are moved down one level (H1
+	// becomes H2 and so on, H6 stays H6) and style sheets and SetOutlineDepth must count with the extra level.
+	// The chapter is listed in the TOC too. See PDFGenerator.SetMarkdownChapters.
+	Chapter bool
 	PageOptions
-	htmlCache []byte // Cache for the converted HTML
+	source    []byte // Markdown used instead of reading InputPath, see NewMarkdownTemplatePage
+	htmlCache []byte // Cache for the converted HTML, set up front by NewMarkdownPageFromHTML
 	readErr   error  // Store error during file read/conversion
 }
 
@@ -166,6 +291,7 @@ func (mp *MarkdownPage) InputFile() string {
 
 // Reader reads the Markdown file, converts it to HTML, and returns it as an io.Reader.
 // It caches the result to avoid re-reading and re-converting.
+// With ExpandIncludes, include lines are replaced with the content of the included files.
 // If SkipFirstH1H2 is true, it attempts to skip the first H1 and subsequent H2 block.
 func (mp *MarkdownPage) Reader() io.Reader {
 	if mp.htmlCache != nil || mp.readErr != nil {
@@ -176,10 +302,44 @@ func (mp *MarkdownPage) Reader() io.Reader {
 		return bytes.NewReader(mp.htmlCache)
 	}
 
-	mdBytesAll, err := os.ReadFile(mp.InputPath)
-	if err != nil {
-		mp.readErr = fmt.Errorf("failed to read markdown file %s: %w", mp.InputPath, err)
-		return &errorReader{err: mp.readErr}
+	mdBytesAll := mp.source
+	if mdBytesAll == nil {
+		var err error
+		mdBytesAll, err = os.ReadFile(mp.InputPath)
+		if err != nil {
+			mp.readErr = fmt.Errorf("failed to read markdown file %s: %w", mp.InputPath, err)
+			return &errorReader{err: mp.readErr}
+		}
+	}
+
+	// with ExpandIncludes, include lines are replaced with the included files from the directory of InputPath
+	var err error
+	if mp.ExpandIncludes && mp.InputPath != "" && !mp.DisableLocalFileAccess.value {
+		dir := filepath.Dir(mp.InputPath)
+		mdBytesAll, err = expandIncludes(mdBytesAll, dir, includeRoot(dir), []string{filepath.Clean(mp.InputPath)})
+		if err != nil {
+			mp.readErr = err
+			return &errorReader{err: mp.readErr}
+		}
+	}
+
+	// with ParseFrontmatter, the front matter is stored in Frontmatter and not converted, its title is still used
+	source := mdBytesAll
+	if mp.ParseFrontmatter {
+		if mp.Frontmatter, mdBytesAll, err = parseFrontMatter(mdBytesAll); err != nil {
+			name := mp.InputPath
+			if name == "" {
+				name = "template"
+			}
+			mp.readErr = fmt.Errorf("%w in %s: %v", ErrInvalidFrontmatter, name, err)
+			return &errorReader{err: mp.readErr}
+		}
+	}
+
+	// with NoWrap, a raw HTML document shell around the Markdown is kept as is and not converted
+	var shellStart, shellEnd []byte
+	if mp.NoWrap {
+		shellStart, mdBytesAll, shellEnd = splitHTMLShell(mdBytesAll)
 	}
 
 	mdBytesToParse := mdBytesAll // Default to parsing all bytes
@@ -234,27 +394,86 @@ func (mp *MarkdownPage) Reader() io.Reader {
 		}
 	}
 
+	if mp.FailOnEmpty && isEmptyMarkdown(mdBytesToParse, mp.Flavor) {
+		name := mp.InputPath
+		if name == "" {
+			name = "template"
+		}
+		mp.readErr = fmt.Errorf("%w: %s has no content", ErrEmptyMarkdown, name)
+		return &errorReader{err: mp.readErr}
+	}
+
 	// Configure markdown parser and renderer
-	extensions := parser.CommonExtensions | parser.AutoHeadingIDs | parser.NoEmptyLineBeforeBlock
-	p := parser.NewWithExtensions(extensions)
+	p := parser.NewWithExtensions(mp.Flavor.parserExtensions())
 	doc := p.Parse(mdBytesToParse) // Parse the potentially truncated bytes
+	if mp.StripComments {
+		stripHTMLComments(doc, mp.KeepComments)
+	}
+	title := mp.Title
+	if title == "" {
+		// the title is detected in the content before SkipFirstH1H2, the skipped H1 moved to the cover is the title
+		titleDoc := doc
+		if len(mdBytesToParse) != len(mdBytesAll) && mp.TitleSource != TitleNone {
+			titleDoc = parser.NewWithExtensions(mp.Flavor.parserExtensions()).Parse(mdBytesAll)
+		}
+		title = mp.TitleSource.title(source, titleDoc)
+	}
+	for _, transform := range mp.ASTTransformers {
+		transform(doc)
+	}
+	if mp.LinkRewriter != nil {
+		rewriteLinks(doc, mp.LinkRewriter)
+	}
+	if mp.Chapter {
+		shiftHeadings(doc)
+	}
 
-	htmlFlags := html.CommonFlags | html.HrefTargetBlank
+	htmlFlags := html.CommonFlags
+	if !mp.NoTargetBlank {
+		htmlFlags |= html.HrefTargetBlank // not added to relative links, like #heading
+	}
 	opts := html.RendererOptions{Flags: htmlFlags}
-	renderer := html.NewRenderer(opts)
+	var renderer *html.Renderer
+	opts.RenderNodeHook = func(w io.Writer, node ast.Node, entering bool) (ast.WalkStatus, bool) {
+		return renderAlignedTableCell(renderer, w, node, entering)
+	}
+	renderer = html.NewRenderer(opts)
 
 	// Render the main markdown body
 	bodyContent := markdown.Render(doc, renderer)
+	if mp.Chapter {
+		bodyContent = append(chapterHeading(mp.chapterLabel(source, title)), bodyContent...)
+	}
+
+	if mp.NoWrap {
+		mp.htmlCache = append(append(shellStart, bodyContent...), shellEnd...)
+	} else {
+		mp.htmlCache = mp.wrapHTML(bodyContent, title)
+	}
+	return bytes.NewReader(mp.htmlCache)
+}
 
-	// Wrap in basic HTML structure WITHOUT injecting styles here.
-	// Styling will be handled by the external CSS file set via SetUserStyleSheet.
+// wrapHTML wraps the converted Markdown in a basic HTML document WITHOUT injecting styles here.
+// Styling will be handled by the external CSS file set via SetUserStyleSheet.
+func (mp *MarkdownPage) wrapHTML(body []byte, title string) []byte {
+	return wrapHTMLDocument(body, mp.Lang, title, mp.HeadExtras)
+}
+
+// wrapHTMLDocument wraps body in a basic HTML document with the language, title and extra head elements
+func wrapHTMLDocument(body []byte, lang, title, headExtras string) []byte {
 	var fullHTML bytes.Buffer
-	fullHTML.WriteString("<!DOCTYPE html><html><head><meta charset=\"utf-8\"><title></title></head><body>") // Removed <style> block
-	fullHTML.Write(bodyContent)
+	fullHTML.WriteString("<!DOCTYPE html>")
+	if lang != "" {
+		fmt.Fprintf(&fullHTML, "<html lang=\"%s\">", gohtml.EscapeString(lang))
+	} else {
+		fullHTML.WriteString("<html>")
+	}
+	fmt.Fprintf(&fullHTML, "<head><meta charset=\"utf-8\"><title>%s</title>", gohtml.EscapeString(title))
+	fullHTML.WriteString(headExtras)
+	fullHTML.WriteString("</head><body>")
+	fullHTML.Write(body)
 	fullHTML.WriteString("</body></html>")
-
-	mp.htmlCache = fullHTML.Bytes()
-	return bytes.NewReader(mp.htmlCache)
+	return fullHTML.Bytes()
 }
 
 // Helper type to return an error from an io.Reader
@@ -267,7 +486,7 @@ func (er *errorReader) Read(p []byte) (n int, err error) {
 }
 
 // PageProvider is the interface which provides a single input page.
-// Implemented by Page, PageReader, and MarkdownPage.
+// Implemented by Page, PageReader, MarkdownPage, AsciiDocPage and RenderedPage.
 type PageProvider interface {
 	Args() []string
 	InputFile() string
@@ -279,6 +498,21 @@ type PageProvider interface {
 type PageOptions struct {
 	pageOptions
 	headerAndFooterOptions
+
+	inlineCSS      string      // CSS set by SetInlineCSS
+	mediaTargetCSS bool        // Inject MediaTargetCSS, see SetPrintMediaType
+	fontFallback   []string    // Fonts injected as font-family, see SetFontFallback
+	allowlist      []string    // Allowed origins of linked resources and scripts, see SetResourceAllowlist
+	includePages   []pageRange // Pages kept from the output of the page, see IncludePages
+	csp            string      // Content Security Policy injected in the <head>, see SetCSP
+}
+
+// SetInlineCSS sets CSS which is applied to this page only, without the need for a stylesheet file.
+// For pages read from stdin (PageReader, MarkdownPage and AsciiDocPage) the CSS is injected in the <head> of the HTML.
+// For pages read from a file or URL the CSS is written to a temporary stylesheet which is used as UserStyleSheet,
+// appended to the contents of UserStyleSheet if that is set. The temporary file is removed after Create.
+func (po *PageOptions) SetInlineCSS(css string) {
+	po.inlineCSS = css
 }
 
 // Args returns the argument slice
@@ -323,35 +557,97 @@ type PDFGenerator struct {
 
 	// Global settings applied to pages added after these are set
 	userStyleSheetPath string
+	userStyleSheets    []string          // Style sheets set by SetUserStyleSheets, concatenated at Create
+	sizeStyleSheets    map[string]string // Style sheets by lower case page size, see SetUserStyleSheetFor
 	headerHTMLPath     string
+	headerLogo         *headerLogo // Logo shown in the header, see SetHeaderLogo
 	footerHTMLPath     string
 	replace            mapOption // Added global replace map
+	replaceEnv         replaceEnvSettings
+	lang               string    // Document language, see SetLang
+	headerStyle        textStyle // Text header font, see SetHeaderStyle
+	footerStyle        textStyle // Text footer font, see SetFooterStyle
+	outputIntent       *outputIntent
+	pageNumberOffset   int               // Offset for the page numbers, see SetPageNumberOffset
+	excludeCover       bool              // Exclude the cover from the page numbers, see SetExcludeCoverFromNumbering
+	coverPages         int               // Number of cover pages counted for the current run
+	provenance         bool              // Write the source hash and generation time, see SetProvenance
+	httpTimeout        time.Duration     // Timeout for fetching style sheets, see SetHTTPTimeout
+	assetCacheDir      string            // Directory for fetched style sheets, see SetAssetCacheDir
+	maxOutputBytes     int64             // Maximum size of the PDF, see SetMaxOutputBytes
+	forceOddStart      bool              // Start the body on an odd page, see SetForceOddStart
+	bodyPages          int               // Number of body pages counted for the current run
+	pageGroupPages     []int             // Number of pages of each page counted for IncludePages in the current run
+	rawArgs            []string          // Arguments added with AddRawArg
+	stderrRules        []StderrRule      // Rules to classify stderr lines, see SetStderrRules
+	stderrHandler      func(StderrEvent) // Called for each stderr line, see SetStderrHandler
+	printMediaType     bool              // Render with print media type, see SetPrintMediaType
+	zoom               float64           // Zoom for pages without their own, see SetZoom
+	pageLabels         []PageLabelRange  // Page labels, see SetPageLabels
+	safeMode           bool              // Options for untrusted content, see SetSafeMode
+	customHeader       mapOption         // Custom headers for all pages, see AddCustomHeader
+	spillExt           string            // Extension of spilled page files, see SetSpillFileExtension
+	stdinSpillSize     int               // Size above which the stdin page is spilled, see SetStdinSpillThreshold
+	spilled            map[int]string    // Temporary files of spilled pages by index, for the current run
+	propagateHeaders   bool              // Send custom headers for all resources, see SetCustomHeaderPropagation
+	autoOrientation    bool              // Switch to landscape for wide content, see SetAutoOrientation
+	metricsWriter      io.Writer         // Receives a JSON record for each run, see SetMetricsWriter
+	trimBlankPages     bool              // Remove blank pages at the end, see SetTrimTrailingBlankPages
+	outputFallback     bool              // Keep the PDF in the buffer if OutputFile is not writable
+	pdfVersion         string            // Version in the PDF header, see SetPDFVersion
+	nUp                *nUpConfig        // Pages per sheet, see NUp
+	fontFallback       []string          // Font stack for pages without their own, see SetFontFallback
+	tagged             bool              // Add a structure tree, see SetTagged
+	exitCodePolicy     ExitCodePolicy    // Decides which exit codes are errors, see SetExitCodePolicy
+	tocMinPages        int               // Pages needed for the TOC, see SetTOCMinPages
+	tocConfig          *TOCConfig        // Appearance of the TOC, see ConfigureTOC
+	skipTOC            bool              // Leave out the TOC in the current run, see SetTOCMinPages
+	locale             string            // Locale of the wkhtmltopdf processes, see SetLocale
+	deterministic      bool              // Normalize dates and the ID, see SetDeterministic
+	embedSource        bool              // Embed the source of the pages, see EmbedSource
+	hfMinHeight        float64           // Height of headers and footers checked by Lint, see SetHeaderFooterMinHeight
+	fitToOnePage       bool              // Shrink the page to one PDF page, see SetFitToOnePage
+	appendedPDFs       []appendedPDF     // PDF files inserted into the PDF, see AppendPDFAt
+	enableForms        bool              // Turn HTML forms into PDF form fields, see SetEnableForms
+	csp                string            // Content Security Policy of the pages, see SetCSP
+	markdownChapters   bool              // Make Markdown pages chapters of the outline, see SetMarkdownChapters
 
 	binPath   string
 	outbuf    bytes.Buffer
 	outWriter io.Writer
 	stdErr    io.Writer
 	pages     []PageProvider // Keep track of added pages
+	tempFiles []string       // Temporary files created for the current run
+	maxPages  int            // Maximum number of pages for AddPagesFrom, 0 is unlimited
+
+	tempFileHook func(path string, created bool) // Called for created and removed temporary files, see SetTempFileHook
+
+	postProcessFuncs []PostProcessor    // Post-processors added by AddPostProcessor
+	viewerPrefs      *ViewerPreferences // How viewers open the PDF, see SetViewerPreferences
+	imageRendering   ImageRenderOptions // Image quality settings, see SetImageRendering
+	processPriority  ProcessPriority    // Scheduling priority of wkhtmltopdf, see SetProcessPriority
 }
 
 // Args returns the commandline arguments as a string slice
 func (pdfg *PDFGenerator) Args() []string {
 	args := append([]string{}, pdfg.globalOptions.Args()...)
+	args = append(args, pdfg.pageNumberArgs()...)
+	args = append(args, pdfg.rawArgs...)
 	args = append(args, pdfg.outlineOptions.Args()...)
 	if pdfg.Cover.Input != "" {
 		args = append(args, "cover")
 		args = append(args, pdfg.Cover.Input)
 		args = append(args, pdfg.Cover.pageOptions.Args()...)
 	}
-	if pdfg.TOC.Include {
+	if pdfg.includeTOC() {
 		args = append(args, "toc")
 		args = append(args, pdfg.TOC.pageOptions.Args()...)
 		args = append(args, pdfg.TOC.tocOptions.Args()...)
 		args = append(args, pdfg.TOC.headerAndFooterOptions.Args()...)
 	}
-	for _, page := range pdfg.pages {
+	for i, page := range pdfg.pages {
 		args = append(args, "page")
-		args = append(args, page.InputFile())
+		args = append(args, pdfg.pageInput(i))
 		args = append(args, page.Args()...)
 	}
 	if pdfg.OutputFile != "" {
@@ -362,6 +658,26 @@ func (pdfg *PDFGenerator) Args() []string {
 	return args
 }
 
+// AddRawArg adds arguments which are passed to wkhtmltopdf as they are, after the global options, like
+// pdfg.AddRawArg("--log-level", "warn"). This allows using global options which have no field in this package.
+// They are passed to the extra wkhtmltopdf runs which count pages as well.
+func (pdfg *PDFGenerator) AddRawArg(args ...string) {
+	pdfg.rawArgs = append(pdfg.rawArgs, args...)
+}
+
+// EffectivePageArgs returns the arguments of the page with the given index (counting from 0, in the order the
+// pages were added) as they are passed to wkhtmltopdf: "page", the input and the page options, including the
+// global settings AddPage applied to the page (like the header and footer HTML set with SetHeaderHTML).
+// Style sheets which are combined or fetched at Create (SetUserStyleSheets, SetInlineCSS on file pages and style
+// sheet URLs) are replaced with temporary files only during Create, so they show as configured here.
+// An error is returned if there is no page with the index.
+func (pdfg *PDFGenerator) EffectivePageArgs(index int) ([]string, error) {
+	if index < 0 || index >= len(pdfg.pages) {
+		return nil, fmt.Errorf("page index %d out of range, there are %d pages", index, len(pdfg.pages))
+	}
+	return append([]string{"page", pdfg.pageInput(index)}, pdfg.pages[index].Args()...), nil
+}
+
 // ArgString returns Args as a single string
 func (pdfg *PDFGenerator) ArgString() string {
 	return strings.Join(pdfg.Args(), " ")
@@ -370,7 +686,7 @@ func (pdfg *PDFGenerator) ArgString() string {
 // AddPage adds a new input page to the document.
 // A page is an input HTML page, it can span multiple pages in the output document.
 // It is a Page when read from file or URL, a PageReader when read from memory,
-// or a MarkdownPage when read from a Markdown file.
+// a MarkdownPage when read from a Markdown file, or an AsciiDocPage when read from an AsciiDoc file.
 //
 // It applies the generator's global settings (stylesheet, header, footer, replacements)
 // to the page's options if they are not already set on the page itself.
@@ -393,6 +709,10 @@ func (pdfg *PDFGenerator) AddPage(p PageProvider) {
 		opts.FooterHTML.Set(pdfg.footerHTMLPath)
 	}
 
+	// Apply global header and footer font styles if not set on page
+	pdfg.headerStyle.apply(&opts.HeaderFontName, &opts.HeaderFontSize)
+	pdfg.footerStyle.apply(&opts.FooterFontName, &opts.FooterFontSize)
+
 	// Apply global replacements if not already set on page
 	if pdfg.replace.value != nil {
 		if opts.Replace.value == nil {
@@ -405,6 +725,62 @@ func (pdfg *PDFGenerator) AddPage(p PageProvider) {
 		}
 	}
 
+	// Apply global custom headers if not already set on page
+	for name, value := range pdfg.customHeader.value {
+		if _, exists := opts.CustomHeader.value[name]; !exists {
+			opts.CustomHeader.Set(name, value)
+		}
+	}
+	if pdfg.propagateHeaders {
+		opts.CustomHeaderPropagation.Set(true)
+	}
+
+	// Apply global language to Markdown pages without a language
+	if mp, ok := p.(*MarkdownPage); ok && pdfg.lang != "" && mp.Lang == "" {
+		mp.Lang = pdfg.lang
+	}
+
+	// Make Markdown pages chapters of the outline, see SetMarkdownChapters
+	if mp, ok := p.(*MarkdownPage); ok && pdfg.markdownChapters {
+		mp.Chapter = true
+	}
+
+	// Apply global zoom if not set on page
+	if pdfg.zoom > 0 && !opts.Zoom.isSet {
+		opts.Zoom.Set(pdfg.zoom)
+	}
+
+	// Apply the smart shrinking setting of SetImageRendering
+	if pdfg.imageRendering.DisableSmartShrinking {
+		opts.DisableSmartShrinking.Set(true)
+	}
+
+	// Apply the options of safe mode
+	if pdfg.safeMode {
+		applySafeMode(&opts.pageOptions)
+	}
+
+	// Render with the print media type and the CSS for data-pdf-only and data-screen-only content
+	if pdfg.printMediaType {
+		opts.PrintMediaType.Set(true)
+		opts.mediaTargetCSS = true
+	}
+
+	// Apply the Content Security Policy of SetCSP
+	if pdfg.csp != "" {
+		opts.csp = pdfg.csp
+	}
+
+	// Turn HTML form elements into PDF form fields
+	if pdfg.enableForms {
+		opts.EnableForms.Set(true)
+	}
+
+	// Inject the font stack of SetFontFallback if the page has none
+	if len(pdfg.fontFallback) > 0 && len(opts.fontFallback) == 0 {
+		opts.fontFallback = pdfg.fontFallback
+	}
+
 	pdfg.pages = append(pdfg.pages, p)
 }
 
@@ -445,10 +821,20 @@ func (pdfg *PDFGenerator) SetStderr(w io.Writer) {
 // SetUserStyleSheet sets a global CSS stylesheet path to be applied to all subsequent pages added via AddPage.
 // This setting overrides any UserStyleSheet setting on individual PageOptions unless the path is empty.
 // It corresponds to the --user-style-sheet wkhtmltopdf option.
+// The path can also be a http or https URL, the style sheet is then fetched when the PDF is created, see
+// SetHTTPTimeout and SetAssetCacheDir. Fetch errors are returned by Create.
 func (pdfg *PDFGenerator) SetUserStyleSheet(path string) {
 	pdfg.userStyleSheetPath = path
 }
 
+// SetUserStyleSheets sets multiple global CSS stylesheets, which are concatenated in the given order into one
+// temporary stylesheet when the PDF is created, so rules in later stylesheets override earlier ones.
+// The result is used as --user-style-sheet for all pages which do not have their own UserStyleSheet.
+// The temporary file is removed after Create.
+func (pdfg *PDFGenerator) SetUserStyleSheets(paths ...string) {
+	pdfg.userStyleSheets = paths
+}
+
 // SetHeaderHTML sets a global header HTML file path to be applied to all subsequent pages added via AddPage.
 // This setting overrides any HeaderHTML setting on individual PageOptions unless the path is empty.
 // It corresponds to the --header-html wkhtmltopdf option.
@@ -471,29 +857,128 @@ func (pdfg *PDFGenerator) SetReplace(key, value string) {
 	pdfg.replace.Set(key, value)
 }
 
+// AddCustomHeader adds an HTTP header (like an Authorization token) which is sent when loading pages, the cover
+// and the TOC. It is applied to the cover, the TOC and the pages added after this call, unless they already
+// have a custom header with the same name. It corresponds to the --custom-header wkhtmltopdf option.
+func (pdfg *PDFGenerator) AddCustomHeader(name, value string) {
+	pdfg.customHeader.Set(name, value)
+	for _, po := range []*pageOptions{&pdfg.Cover.pageOptions, &pdfg.TOC.pageOptions} {
+		if _, exists := po.CustomHeader.value[name]; !exists {
+			po.CustomHeader.Set(name, value)
+		}
+	}
+}
+
+// SetCustomHeaderPropagation makes wkhtmltopdf also send the custom headers for every resource a page loads (like
+// images and style sheets) and when following redirects, instead of only for the page itself.
+// It is applied to the cover, the TOC and the pages added after this call.
+// It corresponds to the --custom-header-propagation wkhtmltopdf option.
+func (pdfg *PDFGenerator) SetCustomHeaderPropagation(propagate bool) {
+	pdfg.propagateHeaders = propagate
+	pdfg.Cover.CustomHeaderPropagation.Set(propagate)
+	pdfg.TOC.CustomHeaderPropagation.Set(propagate)
+}
+
+// SetLang sets the natural language of the document (like "en-US"), which is used by screen readers and other
+// accessibility tools. It is written as /Lang to the document catalog of the generated PDF, and set as the
+// lang attribute of the HTML of Markdown pages added after this call, unless they have their own Lang set.
+func (pdfg *PDFGenerator) SetLang(lang string) {
+	pdfg.lang = lang
+}
+
+// SetMarkdownChapters makes each Markdown page added after this call a chapter of the PDF outline (see
+// MarkdownPage.Chapter): every Markdown document gets a top-level bookmark, labeled with its front matter title or
+// file name, with the bookmarks of its headings nested below it, even if its first heading isn't an H1.
+func (pdfg *PDFGenerator) SetMarkdownChapters(enable bool) {
+	pdfg.markdownChapters = enable
+}
+
+// SetOutlineDepth limits the levels of headings which get a bookmark in the PDF outline (--outline-depth),
+// for example 2 for only h1 and h2 headings. This includes the headings of Markdown pages, which are converted
+// to regular <h1>-<h6> elements. wkhtmltopdf defaults to a depth of 4.
+// An error is returned if depth is less than 1, use NoOutline to omit the outline.
+func (pdfg *PDFGenerator) SetOutlineDepth(depth int) error {
+	if depth < 1 {
+		return fmt.Errorf("invalid outline depth %d, must be at least 1", depth)
+	}
+	pdfg.OutlineDepth.Set(uint(depth))
+	return nil
+}
+
 // SetCover sets the cover page from an HTML file path.
-// Options for the cover page (like zoom, margins) can be set directly via pdfg.Cover.pageOptions.
+// Options for the cover page (like zoom) can be set via CoverOptions.
 // It corresponds to the cover wkhtmltopdf command.
 func (pdfg *PDFGenerator) SetCover(path string) {
 	pdfg.Cover.Input = path
-	// Note: Cover page options can be set directly via pdfg.Cover.pageOptions if needed.
 }
 
-// WriteFile writes the contents of the output buffer to a file
+// CoverPageOptions are the options of the cover page, see CoverOptions. These are the same options as for other
+// pages, except for headers and footers, which wkhtmltopdf does not print on a cover.
+type CoverPageOptions struct {
+	*pageOptions
+}
+
+// CoverOptions returns the options of the cover page set with SetCover, like pdfg.CoverOptions().Zoom.Set(0.75).
+// These are the same options as pdfg.Cover.Zoom etc. Margins and the page size are global options in
+// wkhtmltopdf, they can't be set for the cover only.
+func (pdfg *PDFGenerator) CoverOptions() *CoverPageOptions {
+	return &CoverPageOptions{&pdfg.Cover.pageOptions}
+}
+
+// ErrLargeZoom is returned by SetZoom for a zoom factor above MaxReasonableZoom. The zoom is set anyway, so callers
+// which really want such a zoom can ignore it with errors.Is.
+var ErrLargeZoom = errors.New("unusually large zoom factor")
+
+// MaxReasonableZoom is the largest zoom factor SetZoom accepts without returning ErrLargeZoom
+const MaxReasonableZoom = 10.0
+
+// SetZoom sets the zoom factor for all pages added after this call which don't set their own Zoom.
+// An error is returned if zoom is not greater than 0, in which case the zoom is not changed. A zoom above
+// MaxReasonableZoom is set, but an error wrapping ErrLargeZoom is returned, because it is most likely a mistake.
+func (pdfg *PDFGenerator) SetZoom(zoom float64) error {
+	if err := validateZoom(zoom); err != nil {
+		return err
+	}
+	pdfg.zoom = zoom
+	if zoom > MaxReasonableZoom {
+		return fmt.Errorf("zoom %g is larger than %g: %w", zoom, MaxReasonableZoom, ErrLargeZoom)
+	}
+	return nil
+}
+
+// validateZoom returns an error if zoom is not a valid zoom factor
+func validateZoom(zoom float64) error {
+	if !(zoom > 0) || math.IsInf(zoom, 1) {
+		return fmt.Errorf("invalid zoom %g, must be greater than 0", zoom)
+	}
+	return nil
+}
+
+// SetCoverZoom sets the zoom factor of the cover page
+func (pdfg *PDFGenerator) SetCoverZoom(zoom float64) {
+	pdfg.Cover.Zoom.Set(zoom)
+}
+
+// WriteFile writes the contents of the output buffer to a file.
+// If the file can't be written, the returned error wraps ErrOutputNotWritable and tells why.
 func (pdfg *PDFGenerator) WriteFile(filename string) error {
-	return os.WriteFile(filename, pdfg.Bytes(), 0666)
+	if err := os.WriteFile(filename, pdfg.Bytes(), 0666); err != nil {
+		return outputError(filename, err)
+	}
+	return nil
 }
 
 var lookPath = exec.LookPath
 
-// findPath finds the path to wkhtmltopdf by
-// - first looking in the current dir
-// - looking in the PATH and PATHEXT environment dirs
-// - using the WKHTMLTOPDF_PATH environment dir
+// findPath finds the path to wkhtmltopdf by looking in the locations set with SetSearchOrder, by default:
+// - the dir set with SetPreferredBinDir
+// - the dir of the current executable
+// - the PATH and PATHEXT environment dirs
+// - the WKHTMLTOPDF_PATH environment dir
 // Warning: Running executables from the current path is no longer possible in Go 1.19
 // See https://pkg.go.dev/os/exec@master#hdr-Executables_in_the_current_directory
 // The path is cached, meaning you can not change the location of wkhtmltopdf in
-// a running program once it has been found
+// a running program once it has been found, except with SetPath, SetPreferredBinDir or SetSearchOrder
 func (pdfg *PDFGenerator) findPath() error {
 	const exe = "wkhtmltopdf"
 	pdfg.binPath = GetPath()
@@ -501,37 +986,45 @@ func (pdfg *PDFGenerator) findPath() error {
 		// wkhtmltopdf has already been found, return
 		return nil
 	}
-	exeDir, err := filepath.Abs(filepath.Dir(os.Args[0]))
-	if err != nil {
-		return err
-	}
-	path, err := lookPath(filepath.Join(exeDir, exe))
-	if err == nil && path != "" {
-		binPath.Set(path)
-		pdfg.binPath = path
-		return nil
-	}
-	path, err = lookPath(exe)
-	if errors.Is(err, exec.ErrDot) {
-		return err
-	}
-	if err == nil && path != "" {
-		binPath.Set(path)
-		pdfg.binPath = path
-		return nil
-	}
-	dir := os.Getenv("WKHTMLTOPDF_PATH")
-	if dir == "" {
-		return fmt.Errorf("%s not found", exe)
-	}
-	path, err = lookPath(filepath.Join(dir, exe))
-	if errors.Is(err, exec.ErrDot) {
-		return err
-	}
-	if err == nil && path != "" {
-		binPath.Set(path)
-		pdfg.binPath = path
-		return nil
+	for _, location := range getSearchOrder() {
+		var file string
+		switch location {
+		case SearchPreferredDir:
+			dir := preferredBinDir.Get()
+			if dir == "" {
+				continue
+			}
+			file = filepath.Join(dir, exe)
+			if runtime.GOOS == "windows" {
+				// don't depend on PATHEXT for the preferred dir
+				file += ".exe"
+			}
+		case SearchExeDir:
+			exeDir, err := filepath.Abs(filepath.Dir(os.Args[0]))
+			if err != nil {
+				return err
+			}
+			file = filepath.Join(exeDir, exe)
+		case SearchPATH:
+			file = exe
+		case SearchEnvDir:
+			dir := os.Getenv("WKHTMLTOPDF_PATH")
+			if dir == "" {
+				continue
+			}
+			file = filepath.Join(dir, exe)
+		default:
+			return fmt.Errorf("unknown search location %d", location)
+		}
+		path, err := lookPath(file)
+		if errors.Is(err, exec.ErrDot) && location != SearchExeDir {
+			return err
+		}
+		if err == nil && path != "" {
+			binPath.Set(path)
+			pdfg.binPath = path
+			return nil
+		}
 	}
 	return fmt.Errorf("%s not found", exe)
 }
@@ -554,68 +1047,262 @@ func (pdfg *PDFGenerator) checkDuplicateFlags() error {
 
 // Create creates the PDF document and stores it in the internal buffer if no error is returned
 func (pdfg *PDFGenerator) Create() error {
-	return pdfg.run(context.Background())
+	_, err := pdfg.run(context.Background())
+	return err
 }
 
 // CreateContext is Create with a context passed to exec.CommandContext when calling wkhtmltopdf
 func (pdfg *PDFGenerator) CreateContext(ctx context.Context) error {
+	_, err := pdfg.run(ctx)
+	return err
+}
+
+// CreateWithResult is CreateContext, but also returns a RenderResult with the PDF bytes, the warnings
+// wkhtmltopdf printed, its exit code and the duration of the run.
+// The result is also returned when wkhtmltopdf fails, as long as the process was started.
+func (pdfg *PDFGenerator) CreateWithResult(ctx context.Context) (*RenderResult, error) {
 	return pdfg.run(ctx)
 }
 
-func (pdfg *PDFGenerator) run(ctx context.Context) error {
-	// check for duplicate flags
-	err := pdfg.checkDuplicateFlags()
-	if err != nil {
+// CreateAndTee creates the PDF document like Create, keeps it in the internal buffer for Bytes and Buffer, and also
+// writes it to the file at path. wkhtmltopdf runs once. OutputFile and a writer set with SetOutput are ignored for
+// this call. The file is written after post-processing, so it always has the same content as the buffer, and it is
+// replaced atomically (see WriteFileFrom). It is not written if creating the PDF fails.
+func (pdfg *PDFGenerator) CreateAndTee(path string) error {
+	outputFile, outWriter := pdfg.OutputFile, pdfg.outWriter
+	pdfg.OutputFile, pdfg.outWriter = "", nil
+	defer func() { pdfg.OutputFile, pdfg.outWriter = outputFile, outWriter }()
+
+	if _, err := pdfg.run(context.Background()); err != nil {
 		return err
 	}
+	return WriteFileFrom(context.Background(), bytes.NewReader(pdfg.Bytes()), path, nil)
+}
+
+func (pdfg *PDFGenerator) run(ctx context.Context) (*RenderResult, error) {
+	restoreOutput, warning := pdfg.applyOutputFallback()
+	defer restoreOutput()
+
+	var result *RenderResult
+	var err error
+	if pdfg.metricsWriter == nil {
+		result, err = pdfg.render(ctx)
+	} else {
+		result, err = pdfg.renderWithMetrics(ctx)
+	}
+	if warning != "" && result != nil {
+		result.Warnings = append([]string{warning}, result.Warnings...)
+	}
+	return result, err
+}
+
+func (pdfg *PDFGenerator) render(ctx context.Context) (*RenderResult, error) {
+	// check for duplicate flags and missing files
+	err := pdfg.Validate()
+	if err != nil {
+		return nil, err
+	}
+	// probing the output file writes a file, so it is not part of Validate
+	if err := pdfg.checkOutputFile(); err != nil {
+		return nil, err
+	}
 
-	// create command
-	cmd := exec.CommandContext(ctx, pdfg.binPath, pdfg.Args()...)
+	// write temporary files and make other last minute changes
+	cleanup, err := pdfg.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+
+	// count the cover pages when the cover is excluded from the page numbers
+	resetCoverPages, err := pdfg.countCoverPages(ctx)
+	defer resetCoverPages()
+	if err != nil {
+		return nil, err
+	}
+
+	// create command, it is also killed when the output exceeds the limit set with SetMaxOutputBytes
+	cmdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	cmd := exec.CommandContext(cmdCtx, pdfg.binPath, pdfg.Args()...)
 
 	// configure the commande (different for each OS, windows only for now (hides the cmd console))
 	cmdConfig(cmd)
+	pdfg.applyLocale(cmd)
+
+	// stderr is always kept in a buffer to collect warnings, and also written to the provided writer if set
+	// the phases in the output are timed and each line is classified for the RenderResult
+	errBuf := new(bytes.Buffer)
+	phases := newPhaseRecorder()
+	events := newStderrScanner(pdfg.stderrRules, pdfg.stderrHandler)
+	cmd.Stderr = io.MultiWriter(errBuf, phases, events)
+	if pdfg.stdErr != nil {
+		cmd.Stderr = io.MultiWriter(pdfg.stdErr, errBuf, phases, events)
+	}
+
+	// if there is a pageReader page (from Stdin) we set Stdin to that reader, the others were spilled to files
+	for i, page := range pdfg.pages {
+		if _, spilled := pdfg.spilled[i]; !spilled && page.Reader() != nil {
+			cmd.Stdin, err = stdinReader(page)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// stdin is read to memory when it is also needed for the source hash or embedding, the structure tree or to
+	// count the pages
+	var stdin []byte
+	if cmd.Stdin != nil && (pdfg.provenance || pdfg.embedSource || pdfg.tagged || pdfg.forceOddStart ||
+		pdfg.tocMinPages > 0 || pdfg.hasIncludePages() || pdfg.needsPageCounts() || pdfg.fitToOnePage) {
+		if stdin, err = io.ReadAll(cmd.Stdin); err != nil {
+			return nil, err
+		}
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
 
-	// set stderr to the provided writer, or create a new buffer
-	var errBuf *bytes.Buffer
-	cmd.Stderr = pdfg.stdErr
-	if cmd.Stderr == nil {
-		errBuf = new(bytes.Buffer)
-		cmd.Stderr = errBuf
+	// shrink the page to fit on one PDF page, which changes the arguments of cmd
+	resetZoom, err := pdfg.fitZoom(ctx, stdin)
+	defer resetZoom()
+	if err != nil {
+		return nil, err
+	}
+
+	// leave out the TOC when the document is shorter than the minimum, which changes the arguments of cmd
+	resetTOC, err := pdfg.checkTOCMinPages(ctx, stdin)
+	defer resetTOC()
+	if err != nil {
+		return nil, err
+	}
+	cmd.Args = append([]string{cmd.Args[0]}, pdfg.Args()...)
+
+	// count the body pages when it must start on an odd page
+	resetBodyPages, err := pdfg.countBodyPages(ctx, stdin)
+	defer resetBodyPages()
+	if err != nil {
+		return nil, err
+	}
+
+	// count the pages of each page when only some of them are included or PDFs are inserted between them
+	resetPageGroups, err := pdfg.countPageGroups(ctx, stdin)
+	defer resetPageGroups()
+	if err != nil {
+		return nil, err
 	}
 
 	// set output to the desired writer or the internal buffer
-	if pdfg.outWriter != nil {
+	// when the PDF is post-processed, output for a custom writer is buffered first
+	processors := pdfg.postProcessors()
+	if pdfg.provenance {
+		hash, err := pdfg.sourceHash(stdin)
+		if err != nil {
+			return nil, err
+		}
+		// built-in post-processing runs before the post-processors added by AddPostProcessor
+		generatedAt := time.Now()
+		if pdfg.deterministic {
+			generatedAt = deterministicTime()
+		}
+		processors = pdfg.insertBuiltIn(processors, setProvenance(hash, generatedAt))
+	}
+	if pdfg.embedSource {
+		sources, err := pdfg.pageSources(stdin)
+		if err != nil {
+			return nil, err
+		}
+		processors = pdfg.insertBuiltIn(processors, embedSources(sources))
+	}
+	if pdfg.tagged {
+		pages, err := pdfg.structureHTML(stdin)
+		if err != nil {
+			return nil, err
+		}
+		processors = pdfg.insertBuiltIn(processors, setStructTree(pages))
+	}
+	var procBuf *bytes.Buffer
+	if pdfg.outWriter != nil && len(processors) > 0 {
+		procBuf = new(bytes.Buffer)
+		cmd.Stdout = procBuf
+	} else if pdfg.outWriter != nil {
 		cmd.Stdout = pdfg.outWriter
 	} else {
 		pdfg.outbuf.Reset() // reset internal buffer when we use it
 		cmd.Stdout = &pdfg.outbuf
 	}
-
-	// if there is a pageReader page (from Stdin) we set Stdin to that reader
-	for _, page := range pdfg.pages {
-		if page.Reader() != nil {
-			cmd.Stdin = page.Reader()
-			break
-		}
+	var limit *limitWriter
+	if pdfg.maxOutputBytes > 0 {
+		limit = &limitWriter{w: cmd.Stdout, max: pdfg.maxOutputBytes, exceeded: cancel}
+		cmd.Stdout = limit
 	}
 
-	// run cmd to create the PDF
-	err = cmd.Run()
+	// run cmd to create the PDF, when a slot is free if the number of renders is limited
+	release, err := acquireRenderSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	err = runCmd(cmd, pdfg.processPriority)
+	release()
+	result := &RenderResult{
+		Warnings:          parseWarnings(errBuf.String()),
+		StderrEvents:      events.flush(),
+		ExitCode:          -1,
+		Duration:          time.Since(start),
+		PossiblyTruncated: possiblyTruncated(errBuf.String()),
+	}
+	result.setPhases(phases.phases(time.Now()))
+	if cmd.ProcessState != nil {
+		result.ExitCode = cmd.ProcessState.ExitCode()
+	}
 	if err != nil {
+		if limit != nil && limit.isExceeded() {
+			if pdfg.outWriter == nil {
+				pdfg.outbuf.Reset() // don't keep a partial PDF
+			}
+			return result, limit.err()
+		}
 		if ctxErr := ctx.Err(); ctxErr != nil {
-			return ctxErr
+			return result, ctxErr
 		}
+	}
 
-		// on an error, return the error and the contents of Stderr if it was our own buffer
+	// the policy set with SetExitCodePolicy decides which exit codes are errors
+	var output []byte
+	if procBuf != nil {
+		output = procBuf.Bytes()
+	} else if pdfg.outWriter == nil {
+		output = pdfg.outbuf.Bytes()
+	}
+	if policyErr, applied := pdfg.applyExitCodePolicy(cmd, err, errBuf.String(), output); applied {
+		if policyErr != nil {
+			return result, policyErr
+		}
+		err = nil
+	}
+	if err != nil {
+		// on an error, return the error and the contents of Stderr if it was not sent to a custom writer
 		// if Stderr was set to a custom writer, just return err
-		if errBuf != nil {
+		if pdfg.stdErr == nil {
 			if errStr := errBuf.String(); strings.TrimSpace(errStr) != "" {
-				return fmt.Errorf("%s\n%s", errStr, err)
+				return result, fmt.Errorf("%s\n%w", errStr, err)
 			}
 		}
-		return err
+		return result, err
 	}
-	return nil
+	if err := pdfg.checkOutputFileSize(); err != nil {
+		return result, err
+	}
+	if len(processors) > 0 {
+		err = pdfg.postProcess(processors, procBuf)
+		if err != nil {
+			return result, err
+		}
+	}
+	if pdfg.outWriter == nil && pdfg.OutputFile == "" {
+		result.Bytes = pdfg.outbuf.Bytes()
+	}
+	return result, nil
 }
 
 // NewPDFGenerator returns a new PDFGenerator struct with all options created and
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -547,6 +548,56 @@ func TestFindPath(t *testing.T) {
 	assert.EqualError(t, err, "wkhtmltopdf not found")
 }
 
+func TestFindPathPreferredDir(t *testing.T) {
+	defer func() {
+		lookPath = exec.LookPath
+		SetPreferredBinDir("")
+		SetSearchOrder()
+	}()
+
+	pdfgen := new(PDFGenerator)
+	preferred := filepath.Join("/opt", "bundle", "bin")
+	wantFile := filepath.Join(preferred, "wkhtmltopdf")
+	if runtime.GOOS == "windows" {
+		wantFile += ".exe"
+	}
+
+	// every location has wkhtmltopdf, the preferred dir wins
+	var searched []string
+	lookPath = func(file string) (string, error) {
+		searched = append(searched, file)
+		return file, nil
+	}
+	SetPreferredBinDir(preferred)
+	require.NoError(t, pdfgen.findPath())
+	assert.Equal(t, wantFile, pdfgen.binPath)
+	assert.Equal(t, []string{wantFile}, searched)
+
+	// the preferred dir has no wkhtmltopdf, the next location is used
+	searched = nil
+	lookPath = func(file string) (string, error) {
+		searched = append(searched, file)
+		if file == "wkhtmltopdf" {
+			return file, nil
+		}
+		return "", errors.New("mock error")
+	}
+	SetPreferredBinDir(preferred)
+	require.NoError(t, pdfgen.findPath())
+	assert.Equal(t, "wkhtmltopdf", pdfgen.binPath)
+	require.Len(t, searched, 3)
+	assert.Equal(t, wantFile, searched[0])
+
+	// only the locations in the search order are searched
+	searched = nil
+	SetSearchOrder(SearchPATH, SearchPreferredDir)
+	require.NoError(t, pdfgen.findPath())
+	assert.Equal(t, []string{"wkhtmltopdf"}, searched)
+
+	SetSearchOrder(SearchPreferredDir)
+	assert.EqualError(t, pdfgen.findPath(), "wkhtmltopdf not found")
+}
+
 func TestStringOption(t *testing.T) {
 	opt := stringOption{
 		option: "stringopt",
@@ -699,3 +750,98 @@ func TestMarkdownPage(t *testing.T) {
 
 	t.Logf("Markdown PDF size %vkB", len(pdfBytes)/1024)
 }
+
+func TestSetZoom(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	for _, zoom := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		assert.Error(t, pdfg.SetZoom(zoom), "zoom %g", zoom)
+	}
+	assert.Zero(t, pdfg.zoom)
+
+	err := pdfg.SetZoom(50)
+	assert.ErrorIs(t, err, ErrLargeZoom)
+	assert.Equal(t, 50.0, pdfg.zoom)
+
+	require.NoError(t, pdfg.SetZoom(1.25))
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	page := NewPage("testdata/html5.html")
+	page.Zoom.Set(0.5)
+	pdfg.AddPage(page)
+
+	// the global zoom reaches the page without zoom, the page zoom overrides it
+	assert.Equal(t, "page testdata/htmlsimple.html --zoom 1.250 page testdata/html5.html --zoom 0.500 -", pdfg.ArgString())
+}
+
+func TestAddCustomHeader(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetCover("testdata/htmlsimple.html")
+	pdfg.AddCustomHeader("Authorization", "Bearer token")
+	pdfg.SetCustomHeaderPropagation(true)
+
+	plain := NewPage("testdata/htmlsimple.html")
+	pdfg.AddPage(plain)
+	own := NewPage("testdata/html5.html")
+	own.CustomHeader.Set("Authorization", "Bearer other")
+	pdfg.AddPage(own)
+
+	// the page without headers gets the global one, the page with its own header keeps it
+	assert.Contains(t, pdfg.ArgString(), "page testdata/htmlsimple.html --custom-header Authorization Bearer token --custom-header-propagation page")
+	assert.Contains(t, pdfg.ArgString(), "page testdata/html5.html --custom-header Authorization Bearer other --custom-header-propagation -")
+	assert.Contains(t, pdfg.ArgString(), "cover testdata/htmlsimple.html --custom-header Authorization Bearer token --custom-header-propagation page")
+
+	j, err := pdfg.ToJSON()
+	require.NoError(t, err)
+	restored, err := NewPDFGeneratorFromJSON(bytes.NewReader(j))
+	require.NoError(t, err)
+	assert.Equal(t, pdfg.ArgString(), restored.ArgString())
+
+	// the global header also reaches pages added after loading
+	restored.AddPage(NewPage("testdata/htmlsimple.html"))
+	assert.True(t, strings.HasSuffix(restored.ArgString(), "page testdata/htmlsimple.html --custom-header Authorization Bearer token --custom-header-propagation -"))
+}
+
+func TestCreateAndTee(t *testing.T) {
+	pdfg := newTestPDFGenerator(t)
+	pdfg.OutputFile = filepath.Join(t.TempDir(), "ignored.pdf")
+	var w bytes.Buffer
+	pdfg.SetOutput(&w)
+	path := filepath.Join(t.TempDir(), "tee.pdf")
+
+	require.NoError(t, pdfg.CreateAndTee(path))
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(b, []byte("%PDF-")))
+	assert.Equal(t, pdfg.Bytes(), b)
+
+	// OutputFile and the writer are not used, but kept for the next run
+	assert.NoFileExists(t, pdfg.OutputFile)
+	assert.Zero(t, w.Len())
+	assert.Equal(t, &w, pdfg.outWriter)
+}
+
+func TestEffectivePageArgs(t *testing.T) {
+	pdfg := NewPDFPreparer()
+	pdfg.SetFooterHTML("testdata/footer.html")
+	pdfg.SetReplace("author", "Jane")
+	pdfg.AddPage(NewPage("testdata/htmlsimple.html"))
+	own := NewMarkdownPage("testdata/testmd.md")
+	own.FooterHTML.Set("testdata/footer-toc.html")
+	pdfg.AddPage(own)
+
+	// the same page configured by hand
+	manual := NewPage("testdata/htmlsimple.html")
+	manual.FooterHTML.Set("testdata/footer.html")
+	manual.Replace.Set("author", "Jane")
+
+	args, err := pdfg.EffectivePageArgs(0)
+	require.NoError(t, err)
+	assert.Equal(t, append([]string{"page", "testdata/htmlsimple.html"}, manual.Args()...), args)
+
+	// the page's own footer wins over the global one
+	args, err = pdfg.EffectivePageArgs(1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"page", "-", "--footer-html", "testdata/footer-toc.html", "--replace", "author", "Jane"}, args)
+
+	_, err = pdfg.EffectivePageArgs(2)
+	assert.EqualError(t, err, "page index 2 out of range, there are 2 pages")
+}
@@ -0,0 +1,68 @@
+package wkhtmltopdf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// writeFileChunkSize is the size of the chunks WriteFileFrom copies, the context is checked between chunks
+const writeFileChunkSize = 1 << 20
+
+// WriteFileFrom copies r to the file at path, for outputs too large to keep in memory, like a PDF written to a
+// temporary file. The data is written to a temporary file in the same directory, which is renamed to path when
+// the copy is complete, so path is never left with partial content. If ctx is canceled or copying fails, the
+// temporary file is removed and the error is returned. progress, if not nil, is called after each chunk with
+// the number of bytes written so far. The file is created with permissions 0644.
+func WriteFileFrom(ctx context.Context, r io.Reader, path string, progress func(written int64)) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	buf := make([]byte, writeFileChunkSize)
+	var written int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		n, rerr := r.Read(buf)
+		if n > 0 {
+			if _, err := f.Write(buf[:n]); err != nil {
+				return fmt.Errorf("error writing %s: %w", path, err)
+			}
+			written += int64(n)
+			if progress != nil {
+				progress(written)
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return fmt.Errorf("error reading input for %s: %w", path, rerr)
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := f.Chmod(0644); err != nil {
+		return fmt.Errorf("error setting permissions of %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("error renaming temporary file to %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package wkhtmltopdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFileFrom(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.pdf")
+	data := bytes.Repeat([]byte("0123456789"), writeFileChunkSize/4) // 2.5 chunks
+
+	var progress []int64
+	err := WriteFileFrom(context.Background(), bytes.NewReader(data), path, func(written int64) {
+		progress = append(progress, written)
+	})
+	require.NoError(t, err)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, b)
+	assert.Equal(t, int64(len(data)), progress[len(progress)-1])
+	assert.Len(t, progress, 3)
+
+	// only the final file is left
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFileFromCanceled(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("old"), 0644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	data := bytes.Repeat([]byte("x"), 3*writeFileChunkSize)
+	err := WriteFileFrom(ctx, bytes.NewReader(data), path, func(int64) { cancel() })
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the existing file is unchanged and the temporary file is removed
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(b))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// read errors are returned too
+	errRead := errors.New("read failed")
+	err = WriteFileFrom(context.Background(), io.MultiReader(bytes.NewReader([]byte("abc")), &errorReader{err: errRead}), path, nil)
+	assert.ErrorIs(t, err, errRead)
+}